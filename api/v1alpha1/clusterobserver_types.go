@@ -23,6 +23,30 @@ import (
 // EDIT THIS FILE!  THIS IS SCAFFOLDING FOR YOU TO OWN!
 // NOTE: json tags are required.  Any new fields you add must have json tags for the fields to be serialized.
 
+// ConflictPolicy defines how an observer behaves when another ClusterObserver
+// already claims the same cluster name
+// +kubebuilder:validation:Enum=Warn;Block
+type ConflictPolicy string
+
+const (
+	// ConflictPolicyWarn flags the conflict but keeps reporting
+	ConflictPolicyWarn ConflictPolicy = "Warn"
+	// ConflictPolicyBlock flags the conflict and refuses to start the reporter
+	ConflictPolicyBlock ConflictPolicy = "Block"
+)
+
+// Condition types maintained on ClusterObservers
+const (
+	// ConditionTypeConflicting indicates that another ClusterObserver claims the same cluster name
+	ConditionTypeConflicting = "Conflicting"
+	// ConditionTypeConfigValid indicates that the spec describes a valid configuration
+	ConditionTypeConfigValid = "ConfigValid"
+	// ConditionTypeReporting indicates that the observer's reports are being delivered
+	ConditionTypeReporting = "Reporting"
+	// ConditionTypeReady indicates that the configuration is valid and reports are being delivered
+	ConditionTypeReady = "Ready"
+)
+
 // ClusterObserverSpec defines the desired state of ClusterObserver
 type ClusterObserverSpec struct {
 	// ClusterName is the identifier for this cluster in reports
@@ -38,6 +62,61 @@ type ClusterObserverSpec struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:default="30s"
 	ReportInterval string `json:"reportInterval,omitempty"`
+
+	// ConflictPolicy controls what happens when an older ClusterObserver already
+	// claims the same cluster name. Warn only flags the conflict, Block also
+	// refuses to start the reporter for this observer.
+	// +kubebuilder:default="Warn"
+	// +optional
+	ConflictPolicy ConflictPolicy `json:"conflictPolicy,omitempty"`
+
+	// WatchNamespaces limits observation to the listed namespaces.
+	// All namespaces are observed when empty.
+	// +optional
+	WatchNamespaces []string `json:"watchNamespaces,omitempty"`
+
+	// ExcludeNamespaces lists namespaces that are never observed.
+	// Takes precedence over WatchNamespaces.
+	// +optional
+	ExcludeNamespaces []string `json:"excludeNamespaces,omitempty"`
+
+	// Selector limits observation to ingresses whose labels match.
+	// All ingresses are observed when unset.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
+
+	// WarningThreshold is the remaining validity below which a certificate
+	// is reported as warning (e.g., "720h")
+	// +kubebuilder:default="720h"
+	// +optional
+	WarningThreshold string `json:"warningThreshold,omitempty"`
+
+	// CriticalThreshold is the remaining validity below which a certificate
+	// is reported as critical (e.g., "168h"). Must not exceed WarningThreshold.
+	// +kubebuilder:default="168h"
+	// +optional
+	CriticalThreshold string `json:"criticalThreshold,omitempty"`
+
+	// MinRenewalLeadTime is the validity a certificate should have left when
+	// it is renewed (e.g., "360h"). Renewals observed later are reported as late.
+	// +kubebuilder:default="360h"
+	// +optional
+	MinRenewalLeadTime string `json:"minRenewalLeadTime,omitempty"`
+
+	// ReportLabels are attached to every report, e.g. region, environment
+	// and team, so collectors can route and partition reports. Keys must be
+	// valid Prometheus label names other than cluster.
+	// +optional
+	ReportLabels map[string]string `json:"reportLabels,omitempty"`
+}
+
+// NamespaceIngressCount is the number of observed ingresses in a namespace
+type NamespaceIngressCount struct {
+	// Namespace is the namespace the ingresses live in
+	Namespace string `json:"namespace"`
+
+	// IngressCount is the number of observed ingresses in the namespace
+	IngressCount int `json:"ingressCount"`
 }
 
 // ClusterObserverStatus defines the observed state of ClusterObserver.
@@ -50,6 +129,18 @@ type ClusterObserverStatus struct {
 	// +optional
 	IngressCount int `json:"ingressCount,omitempty"`
 
+	// TLSSecretCount is the number of distinct TLS secrets a certificate
+	// was parsed from
+	// +optional
+	TLSSecretCount int `json:"tlsSecretCount,omitempty"`
+
+	// NamespaceBreakdown lists the number of observed ingresses per
+	// namespace, most ingresses first
+	// +listType=map
+	// +listMapKey=namespace
+	// +optional
+	NamespaceBreakdown []NamespaceIngressCount `json:"namespaceBreakdown,omitempty"`
+
 	// conditions represent the current state of the ClusterObserver resource.
 	// +listType=map
 	// +listMapKey=type
@@ -59,6 +150,12 @@ type ClusterObserverStatus struct {
 
 // +kubebuilder:object:root=true
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="Cluster",type=string,JSONPath=`.spec.clusterName`
+// +kubebuilder:printcolumn:name="Endpoint",type=string,JSONPath=`.spec.reportEndpoint`
+// +kubebuilder:printcolumn:name="Interval",type=string,JSONPath=`.spec.reportInterval`
+// +kubebuilder:printcolumn:name="Ingresses",type=integer,JSONPath=`.status.ingressCount`
+// +kubebuilder:printcolumn:name="Last Report",type=date,JSONPath=`.status.lastReportTime`
+// +kubebuilder:printcolumn:name="Age",type=date,JSONPath=`.metadata.creationTimestamp`
 
 // ClusterObserver is the Schema for the clusterobservers API
 type ClusterObserver struct {
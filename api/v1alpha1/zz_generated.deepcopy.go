@@ -30,7 +30,7 @@ func (in *ClusterObserver) DeepCopyInto(out *ClusterObserver) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -87,6 +87,28 @@ func (in *ClusterObserverList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterObserverSpec) DeepCopyInto(out *ClusterObserverSpec) {
 	*out = *in
+	if in.WatchNamespaces != nil {
+		in, out := &in.WatchNamespaces, &out.WatchNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExcludeNamespaces != nil {
+		in, out := &in.ExcludeNamespaces, &out.ExcludeNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ReportLabels != nil {
+		in, out := &in.ReportLabels, &out.ReportLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterObserverSpec.
@@ -106,6 +128,11 @@ func (in *ClusterObserverStatus) DeepCopyInto(out *ClusterObserverStatus) {
 		in, out := &in.LastReportTime, &out.LastReportTime
 		*out = (*in).DeepCopy()
 	}
+	if in.NamespaceBreakdown != nil {
+		in, out := &in.NamespaceBreakdown, &out.NamespaceBreakdown
+		*out = make([]NamespaceIngressCount, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -124,3 +151,18 @@ func (in *ClusterObserverStatus) DeepCopy() *ClusterObserverStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NamespaceIngressCount) DeepCopyInto(out *NamespaceIngressCount) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NamespaceIngressCount.
+func (in *NamespaceIngressCount) DeepCopy() *NamespaceIngressCount {
+	if in == nil {
+		return nil
+	}
+	out := new(NamespaceIngressCount)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -19,6 +19,7 @@ package main
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"flag"
 	"net/http"
 	"os"
@@ -28,23 +29,38 @@ import (
 	// to ensure that exec-entrypoint and run can make use of them.
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	ctrl "sigs.k8s.io/controller-runtime"
+	crcache "sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
 
 	observerv1alpha1 "github.com/ugurcancaykara/cert-observer/api/v1alpha1"
 	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/certutil"
 	"github.com/ugurcancaykara/cert-observer/internal/config"
 	"github.com/ugurcancaykara/cert-observer/internal/controller"
+	"github.com/ugurcancaykara/cert-observer/internal/discovery"
 	"github.com/ugurcancaykara/cert-observer/internal/metrics"
+	"github.com/ugurcancaykara/cert-observer/internal/notifier"
+	"github.com/ugurcancaykara/cert-observer/internal/query"
 	"github.com/ugurcancaykara/cert-observer/internal/reporter"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
+	webhookv1alpha1 "github.com/ugurcancaykara/cert-observer/internal/webhook/v1alpha1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -57,22 +73,49 @@ func init() {
 	utilruntime.Must(clientgoscheme.AddToScheme(scheme))
 
 	utilruntime.Must(observerv1alpha1.AddToScheme(scheme))
+	utilruntime.Must(gatewayv1.Install(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
 // nolint:gocyclo
 func main() {
+	// Track uptime from process start for self-health reporting
+	observerStats := stats.NewRecorder()
+
 	var metricsAddr string
 	var metricsCertPath, metricsCertName, metricsCertKey string
 	var webhookCertPath, webhookCertName, webhookCertKey string
 	var enableLeaderElection bool
 	var probeAddr string
+	var observerMetricsAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var discoverNamespaces bool
+	var cacheSweepInterval time.Duration
+	var tombstoneTTL time.Duration
+	var deletionGracePeriod time.Duration
+	var cacheSnapshotPath string
+	var cacheSnapshotInterval time.Duration
+	var observerRequeueInterval time.Duration
+	var observerReloadInterval time.Duration
+	var observerRequeueJitter float64
+	var observerMaxConcurrentReconciles int
+	var rateLimit controller.RateLimit
+	var observerName, observerNamespace string
+	var reporterPerObserver bool
+	var enableWebhooks bool
+	var scanOpaqueSecrets bool
+	var readPKCS12Secrets bool
+	var readCACertificates bool
+	var watchWorkloadSecrets bool
+	var secretLabelSelector string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
-	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
+	flag.StringVar(&probeAddr, "health-probe-bind-address", config.DefaultHealthAddr,
+		"The address the probe endpoint binds to. Overrides HEALTH_ADDR.")
+	flag.StringVar(&observerMetricsAddr, "observer-metrics-bind-address", config.DefaultMetricsAddr,
+		"The address the observer metrics and query API bind to. Overrides METRICS_ADDR.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
@@ -81,19 +124,119 @@ func main() {
 	flag.StringVar(&webhookCertPath, "webhook-cert-path", "", "The directory that contains the webhook certificate.")
 	flag.StringVar(&webhookCertName, "webhook-cert-name", "tls.crt", "The name of the webhook certificate file.")
 	flag.StringVar(&webhookCertKey, "webhook-cert-key", "tls.key", "The name of the webhook key file.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"If set, the validating webhook for ClusterObservers is served. Requires a serving certificate, "+
+			"see --webhook-cert-path.")
 	flag.StringVar(&metricsCertPath, "metrics-cert-path", "",
 		"The directory that contains the metrics server certificate.")
 	flag.StringVar(&metricsCertName, "metrics-cert-name", "tls.crt", "The name of the metrics server certificate file.")
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&discoverNamespaces, "discover-namespaces", false,
+		"If set, only watch namespaces where the observer can list and watch ingresses and secrets, "+
+			"as checked with SelfSubjectAccessReviews. Useful when access is granted per namespace via RoleBindings.")
+	flag.BoolVar(&scanOpaqueSecrets, "scan-opaque-secrets", false,
+		"If set, Opaque secrets referenced by observed ingresses and gateways are scanned for PEM certificates "+
+			"under any key, such as cert.pem, when none of the certificate keys are present.")
+	flag.BoolVar(&readPKCS12Secrets, "read-pkcs12-secrets", false,
+		"If set, certificates are read from PKCS#12 keystores in referenced secrets, under keystore.p12 "+
+			"unless PKCS12_KEYSTORE_KEY is set, with the password under PKCS12_PASSWORD_KEY.")
+	flag.BoolVar(&readCACertificates, "read-ca-certificates", false,
+		"If set, the certificates of the ca.crt bundle in referenced secrets, such as the trust bundle of "+
+			"client certificate authentication, are reported along with the serving certificate.")
+	flag.BoolVar(&watchWorkloadSecrets, "watch-workload-secrets", false,
+		"If set, the certificates of secrets named by the "+controller.WatchSecretAnnotation+" annotation "+
+			"of Deployments and DaemonSets are observed, for workloads that mount certificates directly.")
+	flag.StringVar(&secretLabelSelector, "secret-label-selector", "",
+		"If set, only secrets matching this label selector, e.g. "+controller.SecretWatchLabel+"=true, are watched "+
+			"and read. Cuts memory on clusters with many secrets; referenced secrets without the labels are treated as missing.")
+	flag.DurationVar(&cacheSweepInterval, "cache-sweep-interval", 5*time.Minute,
+		"How often to remove cached ingresses that no longer exist. Set to 0 to disable the sweep.")
+	flag.DurationVar(&tombstoneTTL, "tombstone-ttl", 0,
+		"If set, deleted ingresses are kept this long and included once in the next report with \"deleted\": true. "+
+			"Should be at least the report interval. Set to 0 to disable tombstones.")
+	flag.DurationVar(&deletionGracePeriod, "deletion-grace-period", 0,
+		"If set, deleted ingresses stay cached this long and are only evicted if not re-created in the meantime, "+
+			"smoothing out brief deletions during GitOps syncs. Set to 0 to evict them right away.")
+	flag.StringVar(&cacheSnapshotPath, "cache-snapshot-path", "",
+		"If set, the cache is periodically written to this file and restored from it on startup.")
+	flag.DurationVar(&cacheSnapshotInterval, "cache-snapshot-interval", time.Minute,
+		"How often to write the cache snapshot when --cache-snapshot-path is set.")
+	flag.StringVar(&observerName, "observer-name", "",
+		"Name of the ClusterObserver to load the configuration from. "+
+			"If empty, the only ClusterObserver in --observer-namespace (or the cluster) is used.")
+	flag.StringVar(&observerNamespace, "observer-namespace", "",
+		"Namespace of the ClusterObserver to load the configuration from.")
+	flag.BoolVar(&reporterPerObserver, "reporter-per-observer", false,
+		"Run one reporter per ClusterObserver, each reporting the ingresses in its own scope. "+
+			"Ingresses in all namespaces are observed and --observer-name is ignored.")
+	flag.DurationVar(&observerRequeueInterval, "observer-requeue-interval", controller.DefaultObserverRequeueInterval,
+		"Base interval between ClusterObserver status refreshes.")
+	flag.DurationVar(&observerReloadInterval, "observer-reload-interval", 0,
+		"If set, the ClusterObserver configuring the reporter is re-read from the API server at this interval "+
+			"and spec changes are applied, for clusters where watch events may be missed. Set to 0 to disable.")
+	flag.Float64Var(&observerRequeueJitter, "observer-requeue-jitter", 0.1,
+		"Fraction of --observer-requeue-interval by which each requeue is randomly shifted earlier or later.")
+	flag.IntVar(&observerMaxConcurrentReconciles, "observer-max-concurrent-reconciles", 1,
+		"Number of ClusterObservers reconciled in parallel.")
+	flag.Float64Var(&rateLimit.QPS, "reconcile-qps", controller.DefaultRateLimit.QPS,
+		"Sustained rate of ingress and gateway reconciles per second once --reconcile-burst is used up.")
+	flag.IntVar(&rateLimit.Burst, "reconcile-burst", controller.DefaultRateLimit.Burst,
+		"Number of ingress and gateway reconciles allowed in a burst, e.g. when a shared secret changes.")
+	flag.DurationVar(&rateLimit.MaxDelay, "reconcile-max-backoff", controller.DefaultRateLimit.MaxDelay,
+		"Maximum delay before retrying a failed ingress or gateway reconcile.")
+	flag.DurationVar(&rateLimit.SecretDebounce, "secret-event-debounce", controller.DefaultRateLimit.SecretDebounce,
+		"Delay before reconciling the ingresses and gateways of a changed secret, so that events in quick "+
+			"succession collapse into one reconcile. Set to 0 to reconcile right away.")
 	opts := zap.Options{
 		Development: true,
 	}
+	logOptsErr := config.LoadLogOptions(&opts)
 	opts.BindFlags(flag.CommandLine)
 	flag.Parse()
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+	if logOptsErr != nil {
+		setupLog.Error(logOptsErr, "unable to load logging configuration")
+		os.Exit(1)
+	}
+
+	if observerRequeueJitter < 0 || observerRequeueJitter >= 1 {
+		setupLog.Error(nil, "--observer-requeue-jitter must be at least 0 and less than 1",
+			"jitter", observerRequeueJitter)
+		os.Exit(1)
+	}
+	if observerMaxConcurrentReconciles < 1 {
+		setupLog.Error(nil, "--observer-max-concurrent-reconciles must be at least 1",
+			"max", observerMaxConcurrentReconciles)
+		os.Exit(1)
+	}
+
+	rateLimit.BaseDelay = controller.DefaultRateLimit.BaseDelay
+	if err := rateLimit.Validate(); err != nil {
+		setupLog.Error(err, "invalid reconcile rate limit")
+		os.Exit(1)
+	}
+
+	// Server addresses come from the environment unless set by flag
+	serverCfg, err := config.LoadServer()
+	if err != nil {
+		setupLog.Error(err, "unable to load server addresses")
+		os.Exit(1)
+	}
+	flag.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "health-probe-bind-address":
+			serverCfg.HealthAddr = probeAddr
+		case "observer-metrics-bind-address":
+			serverCfg.MetricsAddr = observerMetricsAddr
+		}
+	})
+	if err := serverCfg.Validate(); err != nil {
+		setupLog.Error(err, "invalid server addresses")
+		os.Exit(1)
+	}
 
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
@@ -162,11 +305,103 @@ func main() {
 		metricsServerOptions.KeyName = metricsCertKey
 	}
 
-	mgr, err := ctrl.NewManager(ctrl.GetConfigOrDie(), ctrl.Options{
+	// Load configuration from ClusterObserver CRD only
+	// Use a direct API client (not cached) since manager hasn't been created yet
+	ctx := context.Background()
+	restConfig := ctrl.GetConfigOrDie()
+	directClient, err := client.New(restConfig, client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create direct client")
+		os.Exit(1)
+	}
+
+	// With a reporter per observer, no single observer configures the process
+	var cfg *config.Config
+	observerKey := types.NamespacedName{Name: observerName, Namespace: observerNamespace}
+	if !reporterPerObserver {
+		cfg, err = config.LoadFromCRD(ctx, directClient, observerKey)
+	}
+	switch {
+	case reporterPerObserver:
+		setupLog.Info("running one reporter per ClusterObserver")
+	case errors.Is(err, config.ErrClusterNameConflict):
+		setupLog.Info("cluster name is claimed by another ClusterObserver, reporter will not be started")
+	case errors.Is(err, config.ErrObserverNotFound):
+		setupLog.Info("no ClusterObserver found, reporter will not be started", "reason", err.Error())
+	case err != nil:
+		setupLog.Error(err, "unable to load configuration from CRD")
+		os.Exit(1)
+	default:
+		setupLog.Info("loaded configuration from ClusterObserver CRD",
+			"observer", cfg.Source,
+			"cluster", cfg.ClusterName,
+			"endpoint", cfg.ReportEndpoint,
+			"interval", cfg.ReportInterval)
+	}
+
+	// Restrict observed namespaces and ingresses if configured
+	var namespaceFilter config.NamespaceFilter
+	var ingressSelector labels.Selector
+	if cfg != nil {
+		namespaceFilter = cfg.NamespaceFilter()
+		ingressSelector = cfg.IngressSelector
+	}
+
+	// Optionally scope the Ingress and Secret informers to the namespaces the
+	// observer can actually read, for clusters granting access via RoleBindings
+	var cacheOptions crcache.Options
+	var secretNamespaces map[string]crcache.Config
+	if discoverNamespaces {
+		candidates, err := discovery.CandidateNamespaces(ctx, directClient, namespaceFilter)
+		if err != nil {
+			setupLog.Error(err, "unable to determine candidate namespaces")
+			os.Exit(1)
+		}
+		accessible, err := discovery.AccessibleNamespaces(ctx,
+			&discovery.SelfSubjectAccessReviewer{Client: directClient}, candidates)
+		if err != nil {
+			setupLog.Error(err, "unable to discover accessible namespaces")
+			os.Exit(1)
+		}
+		if len(accessible) == 0 {
+			setupLog.Error(nil, "no namespace grants access to ingresses and secrets")
+			os.Exit(1)
+		}
+		setupLog.Info("discovered accessible namespaces", "namespaces", accessible)
+
+		namespaces := make(map[string]crcache.Config, len(accessible))
+		for _, ns := range accessible {
+			namespaces[ns] = crcache.Config{}
+		}
+		cacheOptions.ByObject = map[client.Object]crcache.ByObject{
+			&networkingv1.Ingress{}: {Namespaces: namespaces},
+		}
+		secretNamespaces = namespaces
+	}
+
+	// Optionally restrict the Secret informer to labeled secrets
+	var secretSelector labels.Selector
+	if secretLabelSelector != "" {
+		secretSelector, err = labels.Parse(secretLabelSelector)
+		if err != nil {
+			setupLog.Error(err, "invalid --secret-label-selector")
+			os.Exit(1)
+		}
+		setupLog.Info("watching only labeled secrets", "selector", secretSelector.String())
+	}
+	if secretSelector != nil || secretNamespaces != nil {
+		if cacheOptions.ByObject == nil {
+			cacheOptions.ByObject = make(map[client.Object]crcache.ByObject)
+		}
+		cacheOptions.ByObject[&corev1.Secret{}] = controller.SecretCacheConfig(secretSelector, secretNamespaces)
+	}
+
+	mgr, err := ctrl.NewManager(restConfig, ctrl.Options{
 		Scheme:                 scheme,
+		Cache:                  cacheOptions,
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
-		HealthProbeBindAddress: probeAddr,
+		HealthProbeBindAddress: serverCfg.HealthAddr,
 		LeaderElection:         enableLeaderElection,
 		LeaderElectionID:       "23929dd5.cert-observer.io",
 		// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
@@ -186,60 +421,260 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Load configuration from ClusterObserver CRD only
-	// Use a direct API client (not cached) since manager hasn't started yet
-	ctx := context.Background()
-	directClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
-	if err != nil {
-		setupLog.Error(err, "unable to create direct client")
-		os.Exit(1)
-	}
-
-	cfg, err := config.LoadFromCRD(ctx, directClient)
-	if err != nil {
-		setupLog.Error(err, "unable to load configuration from CRD")
-		os.Exit(1)
-	}
-
-	if cfg == nil {
-		setupLog.Info("no ClusterObserver CRD found, reporter will not be started")
-	} else {
-		setupLog.Info("loaded configuration from ClusterObserver CRD",
-			"cluster", cfg.ClusterName,
-			"endpoint", cfg.ReportEndpoint,
-			"interval", cfg.ReportInterval)
-	}
-
 	// Initialize the ingress cache
 	// Use empty cluster name if no config available
 	clusterName := ""
 	if cfg != nil {
 		clusterName = cfg.ClusterName
 	}
-	ingressCache := cache.NewIngressCache(clusterName)
+	ingressCache := cache.NewIngressCache(clusterName).WithTombstones(tombstoneTTL).
+		WithDeletionGrace(deletionGracePeriod)
 	setupLog.Info("initialized ingress cache", "cluster", clusterName)
 
+	// Track certificate renewals against the configured lead time
+	minRenewalLeadTime := config.DefaultMinRenewalLeadTime
+	if cfg != nil {
+		minRenewalLeadTime = cfg.MinRenewalLeadTime
+	}
+	renewals := cache.NewRenewalHistory(minRenewalLeadTime)
+
+	// Check secret names against the naming convention only if one is configured
+	secretNamePattern, err := config.LoadSecretNamePattern()
+	if err != nil {
+		setupLog.Error(err, "unable to load secret name pattern")
+		os.Exit(1)
+	}
+	certificateKeys := config.LoadCertificateKeys()
+	if certificateKeys != nil {
+		setupLog.Info("reading certificates from secret keys", "keys", certificateKeys)
+	}
+	var pkcs12 *certutil.PKCS12
+	if readPKCS12Secrets {
+		keystore := config.LoadPKCS12()
+		pkcs12 = &keystore
+		setupLog.Info("reading certificates from PKCS#12 keystores",
+			"keystoreKey", keystore.KeystoreKey, "passwordKey", keystore.PasswordKey)
+	}
+	acmeIssuers, err := config.LoadACMEIssuers()
+	if err != nil {
+		setupLog.Error(err, "unable to load ACME issuers")
+		os.Exit(1)
+	}
+	var secretNames *cache.SecretNameConvention
+	if secretNamePattern != nil {
+		secretNames = cache.NewSecretNameConvention(secretNamePattern)
+		setupLog.Info("checking secret names", "pattern", secretNamePattern.String())
+	}
+
 	// Setup Ingress controller
 	if err = (&controller.IngressReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Cache:  ingressCache,
+		Client:             mgr.GetClient(),
+		Scheme:             mgr.GetScheme(),
+		Cache:              ingressCache,
+		Namespaces:         namespaceFilter,
+		Selector:           ingressSelector,
+		Stats:              observerStats,
+		Renewals:           renewals,
+		SecretNames:        secretNames,
+		ACMEIssuers:        cache.NewACMEIssuers(acmeIssuers),
+		ScanOpaqueSecrets:  scanOpaqueSecrets,
+		CertificateKeys:    certificateKeys,
+		PKCS12:             pkcs12,
+		ReadCACertificates: readCACertificates,
+		RateLimit:          rateLimit,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "Ingress")
 		os.Exit(1)
 	}
 
+	// Setup Gateway controller only if the Gateway API CRDs are installed
+	_, err = mgr.GetRESTMapper().RESTMapping(schema.GroupKind{Group: gatewayv1.GroupName, Kind: "Gateway"},
+		gatewayv1.GroupVersion.Version)
+	switch {
+	case apimeta.IsNoMatchError(err):
+		setupLog.Info("Gateway API CRDs not installed, gateways will not be observed")
+	case err != nil:
+		setupLog.Error(err, "unable to check for Gateway API CRDs")
+		os.Exit(1)
+	default:
+		if err := (&controller.GatewayReconciler{
+			Client:             mgr.GetClient(),
+			Scheme:             mgr.GetScheme(),
+			Cache:              ingressCache,
+			Namespaces:         namespaceFilter,
+			Stats:              observerStats,
+			Renewals:           renewals,
+			SecretNames:        secretNames,
+			ACMEIssuers:        cache.NewACMEIssuers(acmeIssuers),
+			ScanOpaqueSecrets:  scanOpaqueSecrets,
+			CertificateKeys:    certificateKeys,
+			PKCS12:             pkcs12,
+			ReadCACertificates: readCACertificates,
+			RateLimit:          rateLimit,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Gateway")
+			os.Exit(1)
+		}
+	}
+
+	// Setup Route controller only on OpenShift, where the Route API is installed
+	_, err = mgr.GetRESTMapper().RESTMapping(controller.RouteGroupVersionKind.GroupKind(),
+		controller.RouteGroupVersionKind.Version)
+	switch {
+	case apimeta.IsNoMatchError(err):
+		setupLog.Info("OpenShift Route API not installed, routes will not be observed")
+	case err != nil:
+		setupLog.Error(err, "unable to check for the OpenShift Route API")
+		os.Exit(1)
+	default:
+		if err := (&controller.RouteReconciler{
+			Client:      mgr.GetClient(),
+			Scheme:      mgr.GetScheme(),
+			Cache:       ingressCache,
+			Namespaces:  namespaceFilter,
+			Stats:       observerStats,
+			Renewals:    renewals,
+			ACMEIssuers: cache.NewACMEIssuers(acmeIssuers),
+			RateLimit:   rateLimit,
+		}).SetupWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create controller", "controller", "Route")
+			os.Exit(1)
+		}
+	}
+
+	// Setup workload controllers for certificates mounted by Deployments and DaemonSets
+	if watchWorkloadSecrets {
+		for _, kind := range controller.WorkloadKinds {
+			if err := (&controller.WorkloadReconciler{
+				Client:             mgr.GetClient(),
+				Scheme:             mgr.GetScheme(),
+				Cache:              ingressCache,
+				Namespaces:         namespaceFilter,
+				Kind:               kind,
+				Stats:              observerStats,
+				Renewals:           renewals,
+				SecretNames:        secretNames,
+				ACMEIssuers:        cache.NewACMEIssuers(acmeIssuers),
+				ScanOpaqueSecrets:  scanOpaqueSecrets,
+				CertificateKeys:    certificateKeys,
+				PKCS12:             pkcs12,
+				ReadCACertificates: readCACertificates,
+				RateLimit:          rateLimit,
+			}).SetupWithManager(mgr); err != nil {
+				setupLog.Error(err, "unable to create controller", "controller", kind)
+				os.Exit(1)
+			}
+		}
+	}
+
+	var httpReporter *reporter.HTTPReporter
+	if cfg != nil {
+		httpReporter = reporter.NewHTTPReporter(cfg, ingressCache, ctrl.Log.WithName("reporter")).
+			WithStats(observerStats)
+		if cfg.DeadLetterQueuePath != "" {
+			queue, err := reporter.NewDeadLetterQueue(cfg.DeadLetterQueuePath, cfg.DeadLetterQueueSize)
+			if err != nil {
+				setupLog.Error(err, "unable to create dead-letter queue")
+				os.Exit(1)
+			}
+			httpReporter.WithDeadLetterQueue(queue.WithMaxAge(cfg.DeadLetterQueueMaxAge))
+			setupLog.Info("persisting failed reports", "path", cfg.DeadLetterQueuePath, "max_size", cfg.DeadLetterQueueSize,
+				"max_age", cfg.DeadLetterQueueMaxAge)
+		}
+		switch cfg.ReportSink {
+		case config.SinkFile:
+			fileSink, err := reporter.NewFileSink(cfg.ReportFilePath, cfg.ReportFileMaxSize, cfg.ReportFileMaxBackups)
+			if err != nil {
+				setupLog.Error(err, "unable to create report file sink")
+				os.Exit(1)
+			}
+			httpReporter.WithSink(fileSink)
+			setupLog.Info("writing reports to file", "path", cfg.ReportFilePath,
+				"max_size", cfg.ReportFileMaxSize, "max_backups", cfg.ReportFileMaxBackups)
+		case config.SinkOTLP:
+			httpReporter.WithSink(reporter.NewOTLPSink(cfg, ctrl.Log.WithName("otlp")))
+			setupLog.Info("exporting certificate expiry over OTLP", "endpoint", cfg.ReportOTLPEndpoint)
+		}
+	}
+
 	// Setup ClusterObserver controller
-	if err := (&controller.ClusterObserverReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-		Cache:  ingressCache,
-	}).SetupWithManager(mgr); err != nil {
+	observerReconciler := &controller.ClusterObserverReconciler{
+		Client:                  mgr.GetClient(),
+		Scheme:                  mgr.GetScheme(),
+		Cache:                   ingressCache,
+		RequeueInterval:         observerRequeueInterval,
+		RequeueJitter:           observerRequeueJitter,
+		MaxConcurrentReconciles: observerMaxConcurrentReconciles,
+	}
+	if httpReporter != nil {
+		// Apply ClusterObserver changes to the running reporter
+		observerReconciler.Reporter = httpReporter
+		observerReconciler.ReporterSource = cfg.Source
+	}
+	var reporterManager *reporter.Manager
+	if reporterPerObserver {
+		reporterManager = reporter.NewManager(ingressCache, ctrl.Log.WithName("reporter")).
+			WithStats(observerStats)
+		if err := mgr.Add(reporterManager); err != nil {
+			setupLog.Error(err, "unable to set up reporter manager")
+			os.Exit(1)
+		}
+		observerReconciler.Reporters = reporterManager
+	}
+	if err := observerReconciler.SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterObserver")
 		os.Exit(1)
 	}
+	// Re-read the reporter's ClusterObserver in case watch events are missed
+	if httpReporter != nil && observerReloadInterval > 0 {
+		if err := mgr.Add(&controller.ConfigReloader{
+			Client:     mgr.GetAPIReader(),
+			Reconciler: observerReconciler,
+			Interval:   observerReloadInterval,
+			Log:        ctrl.Log.WithName("reloader"),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up config reloader")
+			os.Exit(1)
+		}
+	}
+	if enableWebhooks {
+		if err := webhookv1alpha1.SetupClusterObserverWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ClusterObserver")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	// Periodically prune cache entries left behind by missed delete events
+	if cacheSweepInterval > 0 {
+		if err := mgr.Add(&controller.CacheSweeper{
+			Client:   mgr.GetClient(),
+			Cache:    ingressCache,
+			Interval: cacheSweepInterval,
+			Log:      ctrl.Log.WithName("sweeper"),
+		}); err != nil {
+			setupLog.Error(err, "unable to set up cache sweeper")
+			os.Exit(1)
+		}
+	}
+
+	// Keep the cache warm across restarts; reconciles overwrite restored entries
+	if cacheSnapshotPath != "" {
+		snapshotter := &controller.CacheSnapshotter{
+			Cache:    ingressCache,
+			Path:     cacheSnapshotPath,
+			Interval: cacheSnapshotInterval,
+			Log:      ctrl.Log.WithName("snapshotter"),
+		}
+		if err := snapshotter.Restore(); err != nil {
+			setupLog.Error(err, "ignoring unreadable cache snapshot", "path", cacheSnapshotPath)
+		}
+		if err := mgr.Add(snapshotter); err != nil {
+			setupLog.Error(err, "unable to set up cache snapshotter")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -249,21 +684,110 @@ func main() {
 		os.Exit(1)
 	}
 
-	// Start HTTP reporter in a goroutine only if config is available
+	// Run the HTTP reporter on the leader only if config is available
 	signalCtx := ctrl.SetupSignalHandler()
-	if cfg != nil {
-		httpReporter := reporter.NewHTTPReporter(cfg, ingressCache, ctrl.Log.WithName("reporter"))
-		go httpReporter.Start(signalCtx)
+	if httpReporter != nil {
+		if cfg.DryRun {
+			setupLog.Info("dry run, reports are logged instead of sent", "endpoint", cfg.ReportEndpoint)
+		} else if err := httpReporter.CheckEndpoint(signalCtx); err != nil {
+			setupLog.Info("report endpoint is not reachable yet, reports will be retried",
+				"endpoint", cfg.ReportEndpoint, "error", err.Error())
+		}
+		if err := mgr.Add(reporter.LeaderRunnable{Reporter: httpReporter}); err != nil {
+			setupLog.Error(err, "unable to set up reporter")
+			os.Exit(1)
+		}
 	}
 
-	// Start metrics HTTP server
-	metricsHandler := metrics.NewHandler(ingressCache, ctrl.Log.WithName("metrics"))
+	// Start PagerDuty notifier only if a routing key is configured
+	pagerDutyCfg, err := config.LoadPagerDuty()
+	if err != nil {
+		setupLog.Error(err, "unable to load PagerDuty configuration")
+		os.Exit(1)
+	}
+	if pagerDutyCfg != nil {
+		pagerDutyNotifier := notifier.NewPagerDutyNotifier(pagerDutyCfg, ingressCache, clusterName,
+			ctrl.Log.WithName("pagerduty"))
+		if err := mgr.Add(notifier.LeaderRunnable{Notifier: pagerDutyNotifier}); err != nil {
+			setupLog.Error(err, "unable to set up PagerDuty notifier")
+			os.Exit(1)
+		}
+	}
+
+	// Start Slack notifier only if a webhook URL is configured
+	slackCfg, err := config.LoadSlack()
+	if err != nil {
+		setupLog.Error(err, "unable to load Slack configuration")
+		os.Exit(1)
+	}
+	if slackCfg != nil {
+		slackNotifier := notifier.NewSlackNotifier(slackCfg, ingressCache, clusterName, ctrl.Log.WithName("slack"))
+		if err := mgr.Add(notifier.LeaderRunnable{Notifier: slackNotifier}); err != nil {
+			setupLog.Error(err, "unable to set up Slack notifier")
+			os.Exit(1)
+		}
+	}
+
+	// Start metrics and query HTTP server
+	mux := http.NewServeMux()
+	thresholds := config.DefaultExpiryThresholds()
+	var reportLabels map[string]string
+	if cfg != nil {
+		thresholds = cfg.ExpiryThresholds()
+		reportLabels = cfg.ReportLabels
+	}
+	metricsHandler := metrics.NewHandler(ingressCache, thresholds, ctrl.Log.WithName("metrics")).
+		WithStats(observerStats).
+		WithClusterInfo(clusterName, reportLabels)
+	if httpReporter != nil {
+		metricsHandler.WithReportStats(httpReporter)
+	}
+	// Also serve the observer metrics next to controller-runtime's own
+	if err := ctrlmetrics.Registry.Register(metricsHandler.Collector()); err != nil {
+		setupLog.Error(err, "unable to register metrics with controller-runtime")
+		os.Exit(1)
+	}
+	mux.Handle("/metrics", metricsHandler)
+	// Endpoints that trigger reports or read secrets require the same
+	// authentication and authorization as secure metrics
+	protect := func(handler http.Handler) http.Handler { return handler }
+	if secureMetrics {
+		queryFilter, err := filters.WithAuthenticationAndAuthorization(mgr.GetConfig(), mgr.GetHTTPClient())
+		if err != nil {
+			setupLog.Error(err, "unable to set up query endpoint authorization")
+			os.Exit(1)
+		}
+		protect = func(handler http.Handler) http.Handler {
+			protected, err := queryFilter(ctrl.Log.WithName("query"), handler)
+			if err != nil {
+				setupLog.Error(err, "unable to protect query endpoint")
+				os.Exit(1)
+			}
+			return protected
+		}
+	}
+	var reportTriggers []query.ReportTrigger
+	if httpReporter != nil {
+		reportTriggers = append(reportTriggers, httpReporter)
+	}
+	if reporterManager != nil {
+		reportTriggers = append(reportTriggers, reporterManager)
+	}
+	if len(reportTriggers) > 0 {
+		mux.Handle(query.ReportNowPattern,
+			protect(query.NewReportNowHandler(ctrl.Log.WithName("query"), reportTriggers...)))
+	}
+	mux.Handle(query.IngressesPattern, query.NewIngressesHandler(ingressCache, clusterName, ctrl.Log.WithName("query")))
+	mux.Handle(query.ExpiringPattern, query.NewExpiringHandler(ingressCache, ctrl.Log.WithName("query")))
+	mux.Handle(query.SANConflictsPattern, query.NewSANConflictsHandler(ingressCache, ctrl.Log.WithName("query")))
+	mux.Handle(query.SecretCertPattern, protect(query.NewSecretHandler(mgr.GetAPIReader(), ctrl.Log.WithName("query")).
+		WithCertificateKeys(certificateKeys).WithPKCS12(pkcs12).WithNamespaces(namespaceFilter)))
 	metricsServer := &http.Server{
-		Addr:    ":9090",
-		Handler: http.HandlerFunc(metricsHandler.ServeHTTP),
+		Addr:    serverCfg.MetricsAddr,
+		Handler: mux,
 	}
 	go func() {
-		setupLog.Info("starting metrics server", "addr", ":9090")
+		setupLog.Info("starting metrics server", "addr", serverCfg.MetricsAddr)
 		if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			setupLog.Error(err, "metrics server failed")
 		}
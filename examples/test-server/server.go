@@ -1,14 +1,31 @@
 package main
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"strconv"
 	"time"
 )
 
+// Headers of reports signed with REPORT_SIGNING_SECRET
+const (
+	signatureHeader = "X-Cert-Observer-Signature"
+	timestampHeader = "X-Cert-Observer-Timestamp"
+)
+
+// maxSignatureAge is how old a signed report may be before it is rejected as a replay
+const maxSignatureAge = 5 * time.Minute
+
+// signingSecret verifies report signatures when set
+var signingSecret = os.Getenv("REPORT_SIGNING_SECRET")
+
 func main() {
 	http.HandleFunc("/report", handleReport)
 	http.HandleFunc("/health", handleHealth)
@@ -17,6 +34,9 @@ func main() {
 	log.Println("Endpoints:")
 	log.Println("  POST /report - Receives and displays cert-observer reports")
 	log.Println("  GET  /health - Health check")
+	if signingSecret != "" {
+		log.Println("Verifying report signatures")
+	}
 
 	if err := http.ListenAndServe(":8080", nil); err != nil {
 		log.Fatal(err)
@@ -41,6 +61,14 @@ func handleReport(w http.ResponseWriter, r *http.Request) {
 		}
 	}()
 
+	if signingSecret != "" {
+		if err := verifySignature(r.Header, body, time.Now()); err != nil {
+			log.Printf("Rejected report: %v", err)
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			return
+		}
+	}
+
 	// Pretty print JSON
 	var report interface{}
 	if err := json.Unmarshal(body, &report); err != nil {
@@ -70,6 +98,28 @@ func handleReport(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// verifySignature checks the HMAC-SHA256 signature over the timestamp header
+// followed by the body, and that the timestamp is recent
+func verifySignature(header http.Header, body []byte, now time.Time) error {
+	timestamp := header.Get(timestampHeader)
+	signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("missing or invalid %s header", timestampHeader)
+	}
+	if age := now.Sub(time.Unix(signedAt, 0)); age > maxSignatureAge || age < -maxSignatureAge {
+		return fmt.Errorf("signature timestamp is %s off", age.Round(time.Second))
+	}
+
+	mac := hmac.New(sha256.New, []byte(signingSecret))
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	expected := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if !hmac.Equal([]byte(header.Get(signatureHeader)), []byte(expected)) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
 func handleHealth(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	if _, err := fmt.Fprintf(w, "OK\n"); err != nil {
@@ -0,0 +1,59 @@
+package cache
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// DefaultACMEIssuers maps issuer substrings of well-known ACME certificate
+// authorities to their provider names. The Let's Encrypt entry also covers
+// its intermediates, such as R3 and E1, and its staging environment.
+var DefaultACMEIssuers = map[string]string{
+	"O=Let's Encrypt": "letsencrypt",
+	"O=ZeroSSL":       "zerossl",
+}
+
+// ACMEIssuers flags certificates issued by ACME certificate authorities,
+// recognized by their issuer
+type ACMEIssuers struct {
+	// patterns are the issuer substrings, longest first so the most
+	// specific one wins
+	patterns  []string
+	providers map[string]string
+}
+
+// NewACMEIssuers creates a classifier from issuer substrings, matched case
+// insensitively, to provider names
+func NewACMEIssuers(issuers map[string]string) *ACMEIssuers {
+	providers := make(map[string]string, len(issuers))
+	for pattern, provider := range issuers {
+		providers[strings.ToLower(pattern)] = provider
+	}
+	patterns := slices.SortedFunc(maps.Keys(providers), func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a, b)
+	})
+	return &ACMEIssuers{patterns: patterns, providers: providers}
+}
+
+// Check sets IsACME and ACMEProvider on a certificate whose issuer contains
+// one of the substrings. Check is a no-op on a nil classifier.
+func (a *ACMEIssuers) Check(cert *CertificateInfo) {
+	if a == nil || cert == nil {
+		return
+	}
+	cert.IsACME, cert.ACMEProvider = false, ""
+	issuer := strings.ToLower(cert.Issuer)
+	if issuer == "" {
+		return
+	}
+	for _, pattern := range a.patterns {
+		if strings.Contains(issuer, pattern) {
+			cert.IsACME, cert.ACMEProvider = true, a.providers[pattern]
+			return
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package cache
+
+import "testing"
+
+func TestACMEIssuers_Check(t *testing.T) {
+	issuers := NewACMEIssuers(map[string]string{
+		"O=Let's Encrypt":              "letsencrypt",
+		"CN=(STAGING),O=Let's Encrypt": "letsencrypt-staging",
+		"O=ZeroSSL":                    "zerossl",
+	})
+
+	tests := []struct {
+		name         string
+		issuer       string
+		wantACME     bool
+		wantProvider string
+	}{
+		{name: "lets encrypt R3", issuer: "CN=R3,O=Let's Encrypt,C=US", wantACME: true, wantProvider: "letsencrypt"},
+		{name: "lets encrypt E1", issuer: "CN=E1,O=Let's Encrypt,C=US", wantACME: true, wantProvider: "letsencrypt"},
+		{name: "most specific wins", issuer: "CN=(STAGING),O=Let's Encrypt,C=US", wantACME: true, wantProvider: "letsencrypt-staging"},
+		{name: "case insensitive", issuer: "CN=ZeroSSL RSA Domain Secure Site CA,O=zerossl,C=AT", wantACME: true, wantProvider: "zerossl"},
+		{name: "internal CA", issuer: "CN=Example Internal CA,O=Example Corp,C=US"},
+		{name: "unknown issuer", issuer: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// A stale classification from an earlier check must be cleared
+			cert := &CertificateInfo{Name: "webapp-tls", Issuer: tt.issuer, IsACME: true, ACMEProvider: "stale"}
+			issuers.Check(cert)
+			if cert.IsACME != tt.wantACME || cert.ACMEProvider != tt.wantProvider {
+				t.Errorf("IsACME, ACMEProvider = %v, %q, want %v, %q",
+					cert.IsACME, cert.ACMEProvider, tt.wantACME, tt.wantProvider)
+			}
+		})
+	}
+}
+
+func TestACMEIssuers_Nil(t *testing.T) {
+	var issuers *ACMEIssuers
+	cert := &CertificateInfo{Name: "webapp-tls", Issuer: "CN=R3,O=Let's Encrypt,C=US"}
+	issuers.Check(cert)
+	if cert.IsACME {
+		t.Error("IsACME set without a classifier")
+	}
+}
+
+func TestIngressCache_ACMECertificateCounts(t *testing.T) {
+	c := NewIngressCache("test-cluster")
+	c.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "shop",
+		Hosts: []HostInfo{
+			{Host: "shop.local", Certificate: &CertificateInfo{Name: "shop-tls", IsACME: true, ACMEProvider: "letsencrypt"}},
+			{Host: "api.local", Certificate: &CertificateInfo{Name: "api-tls"}},
+		},
+	})
+	// The same secret referenced again is counted once
+	c.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "www",
+		Hosts: []HostInfo{
+			{Host: "www.shop.local", Certificate: &CertificateInfo{Name: "shop-tls", IsACME: true, ACMEProvider: "letsencrypt"}},
+		},
+	})
+	c.Add(&IngressInfo{
+		Namespace: "other",
+		Name:      "shop",
+		Hosts: []HostInfo{
+			{Host: "other.local", Certificate: &CertificateInfo{Name: "shop-tls", IsACME: true, ACMEProvider: "letsencrypt"}},
+		},
+	})
+
+	counts := c.ACMECertificateCounts()
+	if len(counts) != 1 || counts["letsencrypt"] != 2 {
+		t.Errorf("ACMECertificateCounts() = %v, want map[letsencrypt:2]", counts)
+	}
+}
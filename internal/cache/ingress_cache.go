@@ -1,93 +1,906 @@
 package cache
 
 import (
+	"cmp"
+	"context"
+	"iter"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 )
 
-// CertificateInfo holds certificate details
+// CertificateInfo holds certificate details. SecretNamespace locates the
+// source secret and defaults to the namespace of the referencing resource.
 type CertificateInfo struct {
-	Name    string     `json:"name"`
-	Expires *time.Time `json:"expires,omitempty"`
+	Name            string     `json:"name"`
+	SecretNamespace string     `json:"secretNamespace,omitempty"`
+	Expires         *time.Time `json:"expires,omitempty"`
+	Issuer          string     `json:"issuer,omitempty"`
+	DNSNames        []string   `json:"dnsNames,omitempty"`
+	IPAddresses     []string   `json:"ipAddresses,omitempty"`
+	HasIPSAN        bool       `json:"hasIPSAN,omitempty"`
+	Fingerprint     string     `json:"fingerprint,omitempty"`
+	SerialNumber    string     `json:"serialNumber,omitempty"`
+	// AuthorityKeyID and SubjectKeyID are the hex-encoded key identifiers,
+	// linking a leaf to the intermediate that issued it
+	AuthorityKeyID string `json:"authorityKeyId,omitempty"`
+	SubjectKeyID   string `json:"subjectKeyId,omitempty"`
+	ChainLength    int    `json:"chainLength,omitempty"`
+	// KeyAlgorithm and KeyBits describe the leaf's public key, e.g. RSA
+	// with 2048 bits or ECDSA with 256 bits for P-256
+	KeyAlgorithm string `json:"keyAlgorithm,omitempty"`
+	KeyBits      int    `json:"keyBits,omitempty"`
+	// Version is the X.509 version of the leaf. PreV3 marks v1 and v2
+	// certificates, which have no extensions and so no SANs.
+	Version int  `json:"version,omitempty"`
+	PreV3   bool `json:"preV3,omitempty"`
+	// State is one of the CertificateState constants and tells a missing
+	// secret apart from one without a certificate or with a broken one
+	State string `json:"state,omitempty"`
+	// ParseError explains why the referenced secret's certificate couldn't
+	// be parsed, e.g. malformed PEM. It is empty for parsed certificates
+	// and for missing secrets, which have no expiry either.
+	ParseError string `json:"parseError,omitempty"`
+	// NoLeafCertificate is set when tls.crt holds only CA certificates, in
+	// which case no expiry is reported
+	NoLeafCertificate bool `json:"noLeafCertificate,omitempty"`
+	// RenewalLeadDays is how many days before the previous certificate
+	// expired the last renewal was observed; negative if it had already
+	// expired. Unset until a renewal has been observed.
+	RenewalLeadDays *int `json:"renewalLeadDays,omitempty"`
+	// RenewedLate is set when the last renewal had less lead time than required
+	RenewedLate bool `json:"renewedLate,omitempty"`
+	// FirstObservedAt is when the cache first saw this certificate in its
+	// secret. It is kept while the serial number stays the same.
+	FirstObservedAt time.Time `json:"firstObservedAt,omitzero"`
+	// SecretResourceVersion is the resourceVersion of the secret the
+	// certificate was read from, and SecretObservedAt when it was read, so
+	// collectors can tell how fresh the certificate is after a rotation.
+	// Both are unset when the secret couldn't be read.
+	SecretResourceVersion string    `json:"secretResourceVersion,omitempty"`
+	SecretObservedAt      time.Time `json:"secretObservedAt,omitzero"`
+	// NonconformingName is set when the secret name doesn't follow the
+	// configured naming convention
+	NonconformingName bool `json:"nonconformingName,omitempty"`
+	// IsACME is set when the issuer is a known ACME certificate authority,
+	// named by ACMEProvider, e.g. "letsencrypt"
+	IsACME       bool   `json:"isACME,omitempty"`
+	ACMEProvider string `json:"acmeProvider,omitempty"`
+	// IntervalsUntilExpiry is the number of whole report intervals left
+	// before expiry, 0 once expired. Only set in reports.
+	IntervalsUntilExpiry *int `json:"intervalsUntilExpiry,omitempty"`
+	// DaysUntilExpiry is the number of whole days from the report's
+	// generation time until expiry, rounded toward zero and negative once
+	// expired by a day or more. Only set in reports, when enabled.
+	DaysUntilExpiry *int `json:"daysUntilExpiry,omitempty"`
+	// ExpiresWithinNextInterval is set in reports when the certificate
+	// expires before the next report is due, or has already expired
+	ExpiresWithinNextInterval bool `json:"expiresWithinNextInterval,omitempty"`
+	// ExpiresOnWeekend and ExpiresAfterHours are set in reports when the
+	// certificate expires on a weekend or outside business hours
+	ExpiresOnWeekend  bool `json:"expiresOnWeekend,omitempty"`
+	ExpiresAfterHours bool `json:"expiresAfterHours,omitempty"`
+	// CACertificates are the certificates of the secret's ca.crt bundle,
+	// named by their subject, when reading CA bundles is enabled. An expired
+	// CA there breaks client certificate authentication without affecting
+	// the served certificate.
+	CACertificates []CertificateInfo `json:"caCertificates,omitempty"`
 }
 
 // HostInfo holds information about a single host in an Ingress
 type HostInfo struct {
 	Host        string           `json:"host"`
+	Listener    string           `json:"listener,omitempty"`
 	Certificate *CertificateInfo `json:"certificate,omitempty"`
+	// NoHost marks the placeholder entry of an ingress without any host,
+	// and the entries of workload secrets, whose Host is empty
+	NoHost bool `json:"noHost,omitempty"`
+	// TLSEnabled is set for Ingress hosts listed in a TLS entry, directly or
+	// through a wildcard, so hosts exposed without TLS stand out
+	TLSEnabled bool `json:"tlsEnabled,omitempty"`
+	// SSLRedirect is set for Ingress hosts whose ingress asks for HTTP to
+	// be redirected to HTTPS by a known ingress controller annotation. It is
+	// best-effort: controllers redirecting by default aren't detected.
+	SSLRedirect bool `json:"sslRedirect,omitempty"`
+	// CertificateRef is the SecretKey of the certificate in reports that
+	// list certificates once instead of embedding them in each host
+	CertificateRef string `json:"certificateRef,omitempty"`
 }
 
+// KindGateway marks cache entries built from Gateway API Gateways
+const KindGateway = "Gateway"
+
+// KindRoute marks cache entries built from OpenShift Routes
+const KindRoute = "Route"
+
+// Workload kinds mark cache entries built from the annotated secrets of
+// Deployments and DaemonSets that mount certificates directly
+const (
+	KindDeployment = "Deployment"
+	KindDaemonSet  = "DaemonSet"
+)
+
 // IngressInfo holds information about an Ingress resource
 type IngressInfo struct {
+	// Kind is empty for Ingresses, KindGateway for Gateways, KindRoute for
+	// OpenShift Routes and a workload kind for Deployments and DaemonSets
+	Kind      string     `json:"kind,omitempty"`
 	Namespace string     `json:"namespace"`
 	Name      string     `json:"name"`
 	Hosts     []HostInfo `json:"hosts"`
+	// Labels are the resource labels, used to scope per-observer reports
+	Labels map[string]string `json:"labels,omitempty"`
+	// SecretCount is the number of distinct TLS secrets referenced
+	SecretCount int `json:"secretCount"`
+	// Deleted marks a tombstone for a resource deleted since the last report
+	Deleted bool `json:"deleted,omitempty"`
+	// LastChanged is when the cache last saw the hosts or their certificates
+	// change. Reconciles that write the same hosts and certificates keep it.
+	LastChanged time.Time `json:"lastChanged,omitzero"`
+}
+
+// Certificate states, telling why a certificate has no expiry
+const (
+	// CertificateStateOK is a certificate read and parsed from its secret
+	CertificateStateOK = "ok"
+	// CertificateStateMissingSecret is a reference to a secret that doesn't exist
+	CertificateStateMissingSecret = "missing_secret"
+	// CertificateStateUnreadableSecret is a secret that couldn't be fetched,
+	// e.g. for lack of permissions
+	CertificateStateUnreadableSecret = "unreadable_secret"
+	// CertificateStateNoCertificate is a secret without any of the
+	// certificate keys, e.g. no tls.crt
+	CertificateStateNoCertificate = "no_tls_crt"
+	// CertificateStateParseError is a certificate that couldn't be parsed
+	CertificateStateParseError = "parse_error"
+)
+
+// Certificate expiry statuses
+const (
+	StatusOK       = "ok"
+	StatusWarning  = "warning"
+	StatusCritical = "critical"
+	StatusExpired  = "expired"
+)
+
+// Statuses lists all expiry statuses from healthiest to worst
+var Statuses = []string{StatusOK, StatusWarning, StatusCritical, StatusExpired}
+
+// ExpiryThresholds defines the remaining validity below which a certificate
+// is considered in warning or critical state
+type ExpiryThresholds struct {
+	Warning  time.Duration
+	Critical time.Duration
+}
+
+// Status returns the expiry status of a certificate expiring at the given time
+func (t ExpiryThresholds) Status(expires, now time.Time) string {
+	remaining := expires.Sub(now)
+	switch {
+	case remaining <= 0:
+		return StatusExpired
+	case remaining < t.Critical:
+		return StatusCritical
+	case remaining < t.Warning:
+		return StatusWarning
+	default:
+		return StatusOK
+	}
 }
 
 // IngressCache provides thread-safe storage for Ingress information
 type IngressCache struct {
-	mu          sync.RWMutex
-	items       map[string]*IngressInfo
-	clusterName string
+	mu    sync.RWMutex
+	items map[string]*IngressInfo
+	// updated records when each entry was last written
+	updated map[string]time.Time
+	// certificates records the certificate last seen in each secret
+	certificates map[string]certificateRecord
+	// tombstones holds recently deleted entries while tombstoneTTL is set
+	tombstones   map[string]tombstone
+	tombstoneTTL time.Duration
+	// pendingDeletes holds when each entry deleted while deletionGrace is
+	// set was deleted. The entry stays cached until the grace window passes
+	// without it being re-added.
+	pendingDeletes map[string]time.Time
+	deletionGrace  time.Duration
+	clusterName    string
+	// shared holds deep copies of the items, built on demand by GetAllShared
+	// and handed to every caller until the next mutation drops it
+	shared   []*IngressInfo
+	sharedMu sync.Mutex
+}
+
+// tombstone is a deleted entry and when it was deleted
+type tombstone struct {
+	info      *IngressInfo
+	deletedAt time.Time
+}
+
+// certificateRecord identifies a secret's certificate and when it was first seen
+type certificateRecord struct {
+	serialNumber    string
+	firstObservedAt time.Time
 }
 
 // NewIngressCache creates a new IngressCache instance
 func NewIngressCache(clusterName string) *IngressCache {
 	return &IngressCache{
-		items:       make(map[string]*IngressInfo),
-		clusterName: clusterName,
+		items:          make(map[string]*IngressInfo),
+		updated:        make(map[string]time.Time),
+		certificates:   make(map[string]certificateRecord),
+		tombstones:     make(map[string]tombstone),
+		pendingDeletes: make(map[string]time.Time),
+		clusterName:    clusterName,
 	}
 }
 
+// ClusterName returns the name of the cluster the cache holds entries for
+func (c *IngressCache) ClusterName() string {
+	return c.clusterName
+}
+
+// WithTombstones keeps deleted entries for ttl, so reports can include them
+// as tombstones. The TTL should cover at least one report interval.
+func (c *IngressCache) WithTombstones(ttl time.Duration) *IngressCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.tombstoneTTL = ttl
+	return c
+}
+
+// WithDeletionGrace keeps deleted entries for grace before evicting them, so
+// a resource deleted and re-created shortly after, e.g. during a GitOps
+// sync, doesn't drop out of reports and metrics in between. Entries are
+// evicted right away when grace is 0.
+func (c *IngressCache) WithDeletionGrace(grace time.Duration) *IngressCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.deletionGrace = grace
+	return c
+}
+
 // Add adds or updates an IngressInfo in the cache
 func (c *IngressCache) Add(info *IngressInfo) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	key := makeKey(c.clusterName, info.Namespace, info.Name)
+	now := time.Now()
+	key := makeKey(c.clusterName, info.Kind, info.Namespace, info.Name)
+	c.setLastChanged(key, info, now)
 	c.items[key] = info
+	c.updated[key] = now
+	delete(c.tombstones, key)
+	delete(c.pendingDeletes, key)
+	c.observeCertificates(info, now)
+	c.shared = nil
+}
+
+// setLastChanged sets LastChanged on the entry, keeping the time of the
+// cached entry when the hosts and certificates are the same. An entry that
+// already carries the time, e.g. restored from a snapshot, keeps it when
+// nothing is cached under its key. c.mu must be held.
+func (c *IngressCache) setLastChanged(key string, info *IngressInfo, now time.Time) {
+	cached, ok := c.items[key]
+	if ok && sameHosts(cached.Hosts, info.Hosts) {
+		info.LastChanged = cached.LastChanged
+		return
+	}
+	if !ok && !info.LastChanged.IsZero() {
+		return
+	}
+	info.LastChanged = now
+}
+
+// sameHosts reports whether two host lists hold the same hosts, in any
+// order, comparing the certificates by value. Ingress hosts are collected
+// from a map, so their order differs between reconciles.
+func sameHosts(a, b []HostInfo) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a, b = slices.Clone(a), slices.Clone(b)
+	slices.SortFunc(a, compareHosts)
+	slices.SortFunc(b, compareHosts)
+	return slices.EqualFunc(a, b, func(x, y HostInfo) bool {
+		return x.Host == y.Host && x.Listener == y.Listener && x.NoHost == y.NoHost &&
+			x.TLSEnabled == y.TLSEnabled && x.SSLRedirect == y.SSLRedirect &&
+			x.CertificateRef == y.CertificateRef && sameCertificate(x.Certificate, y.Certificate)
+	})
+}
+
+// compareHosts orders hosts by name and then by listener
+func compareHosts(x, y HostInfo) int {
+	return cmp.Or(strings.Compare(x.Host, y.Host), strings.Compare(x.Listener, y.Listener))
+}
+
+// sameCertificate reports whether two certificates are equal by value,
+// ignoring when and from which secret version they were read
+func sameCertificate(a, b *CertificateInfo) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	x, y := *a, *b
+	for _, cert := range []*CertificateInfo{&x, &y} {
+		cert.Expires = nil
+		cert.FirstObservedAt = time.Time{}
+		cert.SecretObservedAt = time.Time{}
+		cert.SecretResourceVersion = ""
+	}
+	return sameTime(a.Expires, b.Expires) && reflect.DeepEqual(x, y)
+}
+
+// sameTime reports whether two optional times are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+// observeCertificates sets FirstObservedAt on the entry's certificates,
+// keeping the time recorded for a secret until its serial number changes.
+// A certificate that already carries the time, e.g. restored from a
+// snapshot, keeps it when its secret hasn't been seen yet. c.mu must be held.
+func (c *IngressCache) observeCertificates(info *IngressInfo, now time.Time) {
+	for _, host := range info.Hosts {
+		cert := host.Certificate
+		if cert == nil {
+			continue
+		}
+		key := SecretKey(info, cert)
+		record, ok := c.certificates[key]
+		if !ok || record.serialNumber != cert.SerialNumber {
+			record = certificateRecord{serialNumber: cert.SerialNumber, firstObservedAt: now}
+			if !ok && !cert.FirstObservedAt.IsZero() {
+				record.firstObservedAt = cert.FirstObservedAt
+			}
+			c.certificates[key] = record
+		}
+		cert.FirstObservedAt = record.firstObservedAt
+	}
+}
+
+// forgetUnusedCertificates drops the records of secrets no entry references
+// anymore. c.mu must be held.
+func (c *IngressCache) forgetUnusedCertificates() {
+	used := make(map[string]bool, len(c.certificates))
+	for _, info := range c.items {
+		for _, host := range info.Hosts {
+			if host.Certificate != nil {
+				used[SecretKey(info, host.Certificate)] = true
+			}
+		}
+	}
+	for key := range c.certificates {
+		if !used[key] {
+			delete(c.certificates, key)
+		}
+	}
 }
 
 // Delete removes an IngressInfo from the cache
 func (c *IngressCache) Delete(namespace, name string) {
+	c.DeleteKind("", namespace, name)
+}
+
+// DeleteKind removes an entry of the given kind from the cache. With a
+// deletion grace window, the entry is only marked as pending deletion and
+// evicted once the window passes without it being re-added.
+func (c *IngressCache) DeleteKind(kind, namespace, name string) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	key := makeKey(c.clusterName, namespace, name)
+	key := makeKey(c.clusterName, kind, namespace, name)
+	if _, ok := c.items[key]; ok && c.deletionGrace > 0 {
+		// Deleting a pending entry again doesn't extend its window
+		if _, pending := c.pendingDeletes[key]; !pending {
+			deletedAt := time.Now()
+			c.pendingDeletes[key] = deletedAt
+			time.AfterFunc(c.deletionGrace, func() { c.evict(key, deletedAt) })
+		}
+		return
+	}
+	c.remove(key, time.Now())
+}
+
+// evict removes an entry pending deletion since deletedAt, unless it has
+// been re-added, or re-added and deleted again, in the meantime
+func (c *IngressCache) evict(key string, deletedAt time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if pendingSince, ok := c.pendingDeletes[key]; !ok || !pendingSince.Equal(deletedAt) {
+		return
+	}
+	c.remove(key, time.Now())
+}
+
+// remove removes an entry, leaving a tombstone if enabled. c.mu must be held.
+func (c *IngressCache) remove(key string, now time.Time) {
+	c.bury(key, now)
 	delete(c.items, key)
+	delete(c.updated, key)
+	delete(c.pendingDeletes, key)
+	c.forgetUnusedCertificates()
+	c.shared = nil
+}
+
+// bury records a tombstone for the entry if tombstones are enabled, and
+// drops tombstones past their TTL. c.mu must be held.
+func (c *IngressCache) bury(key string, now time.Time) {
+	if c.tombstoneTTL <= 0 {
+		return
+	}
+	for k, t := range c.tombstones {
+		if now.Sub(t.deletedAt) > c.tombstoneTTL {
+			delete(c.tombstones, k)
+		}
+	}
+	if info, ok := c.items[key]; ok {
+		c.tombstones[key] = tombstone{info: info, deletedAt: now}
+	}
+}
+
+// DeletedSince returns tombstones for the entries deleted after since and
+// within the tombstone TTL of now. Re-adding an entry removes its tombstone.
+func (c *IngressCache) DeletedSince(since, now time.Time) []*IngressInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []*IngressInfo
+	for _, t := range c.tombstones {
+		if !t.deletedAt.After(since) || now.Sub(t.deletedAt) > c.tombstoneTTL {
+			continue
+		}
+		info := copyInfo(t.info)
+		info.Deleted = true
+		result = append(result, info)
+	}
+	return result
+}
+
+// Prune removes entries of the given kind that don't belong to a live
+// resource. live holds the "namespace/name" of every existing resource.
+// Entries written after listedAt are kept, since they may come from a
+// reconcile of a resource created after the live list was taken.
+// It returns the number of removed entries.
+func (c *IngressCache) Prune(kind string, live []string, listedAt time.Time) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	keep := make(map[string]bool, len(live))
+	for _, namespacedName := range live {
+		namespace, name, _ := strings.Cut(namespacedName, "/")
+		keep[makeKey(c.clusterName, kind, namespace, name)] = true
+	}
+
+	now := time.Now()
+	removed := 0
+	for key, info := range c.items {
+		if info.Kind != kind || keep[key] || c.updated[key].After(listedAt) {
+			continue
+		}
+		c.bury(key, now)
+		delete(c.items, key)
+		delete(c.updated, key)
+		delete(c.pendingDeletes, key)
+		removed++
+	}
+	if removed > 0 {
+		c.forgetUnusedCertificates()
+		c.shared = nil
+	}
+	return removed
 }
 
 // GetAll returns all IngressInfo entries in the cache
 func (c *IngressCache) GetAll() []*IngressInfo {
+	result, _ := c.GetAllContext(context.Background())
+	return result
+}
+
+// contextCheckInterval is the number of entries copied between checks of
+// the context, keeping the checks cheap next to the copies
+const contextCheckInterval = 256
+
+// GetAllContext returns copies of all entries like GetAll, but stops and
+// returns the context's error once ctx is done, so that copying a large
+// cache doesn't hold up shutdown
+func (c *IngressCache) GetAllContext(ctx context.Context) ([]*IngressInfo, error) {
 	c.mu.RLock()
 	defer c.mu.RUnlock()
 
 	result := make([]*IngressInfo, 0, len(c.items))
 	for _, info := range c.items {
-		// Create a deep copy to avoid race conditions
-		infoCopy := &IngressInfo{
-			Namespace: info.Namespace,
-			Name:      info.Name,
-			Hosts:     make([]HostInfo, len(info.Hosts)),
-		}
-		for i, host := range info.Hosts {
-			infoCopy.Hosts[i] = HostInfo{
-				Host: host.Host,
+		if len(result)%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
 			}
+		}
+		result = append(result, copyInfo(info))
+	}
+	return result, nil
+}
+
+// GetAllShared returns copies of all entries like GetAll, but the copies are
+// shared by all callers until the cache changes, so back-to-back reads of an
+// unchanged cache, e.g. metrics scrapes, don't copy it again. Callers must
+// not modify the returned slice or entries; those that do need GetAll.
+func (c *IngressCache) GetAllShared() []*IngressInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// Writers hold c.mu exclusively, so sharedMu only orders readers
+	c.sharedMu.Lock()
+	defer c.sharedMu.Unlock()
+	if c.shared == nil {
+		c.shared = make([]*IngressInfo, 0, len(c.items))
+		for _, info := range c.items {
+			c.shared = append(c.shared, copyInfo(info))
+		}
+	}
+	return c.shared
+}
+
+// Len returns the number of entries in the cache
+func (c *IngressCache) Len() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return len(c.items)
+}
+
+// GetByNamespace returns all IngressInfo entries in the given namespace
+func (c *IngressCache) GetByNamespace(namespace string) []*IngressInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []*IngressInfo
+	for _, info := range c.items {
+		if info.Namespace == namespace {
+			result = append(result, copyInfo(info))
+		}
+	}
+	return result
+}
+
+// GetByHost returns all IngressInfo entries serving the given host.
+// Hosts are compared case-insensitively and wildcard hosts such as
+// *.example.com match a single leading label.
+func (c *IngressCache) GetByHost(host string) []*IngressInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	var result []*IngressInfo
+	for _, info := range c.items {
+		for _, h := range info.Hosts {
+			if HostMatches(h.Host, host) {
+				result = append(result, copyInfo(info))
+				break
+			}
+		}
+	}
+	return result
+}
+
+// GetExpiringSoon returns all entries with at least one certificate that
+// expires within the given duration of now, including expired ones.
+// Certificates without a known expiry are ignored.
+func (c *IngressCache) GetExpiringSoon(within time.Duration, now time.Time) []*IngressInfo {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	deadline := now.Add(within)
+	var result []*IngressInfo
+	for _, info := range c.items {
+		for _, h := range info.Hosts {
+			if h.Certificate != nil && h.Certificate.Expires != nil && !h.Certificate.Expires.After(deadline) {
+				result = append(result, copyInfo(info))
+				break
+			}
+		}
+	}
+	return result
+}
+
+// copyInfo creates a deep copy of an IngressInfo to avoid race conditions
+func copyInfo(info *IngressInfo) *IngressInfo {
+	infoCopy := &IngressInfo{
+		Kind:        info.Kind,
+		Namespace:   info.Namespace,
+		Name:        info.Name,
+		Labels:      maps.Clone(info.Labels),
+		Hosts:       make([]HostInfo, len(info.Hosts)),
+		SecretCount: info.SecretCount,
+		LastChanged: info.LastChanged,
+	}
+	for i, host := range info.Hosts {
+		infoCopy.Hosts[i] = HostInfo{
+			Host:           host.Host,
+			Listener:       host.Listener,
+			NoHost:         host.NoHost,
+			TLSEnabled:     host.TLSEnabled,
+			SSLRedirect:    host.SSLRedirect,
+			CertificateRef: host.CertificateRef,
+		}
+		if host.Certificate != nil {
+			infoCopy.Hosts[i].Certificate = copyCertificate(host.Certificate)
+		}
+	}
+	return infoCopy
+}
+
+// copyCertificate creates a deep copy of a CertificateInfo
+func copyCertificate(cert *CertificateInfo) *CertificateInfo {
+	certCopy := &CertificateInfo{
+		Name:                  cert.Name,
+		SecretNamespace:       cert.SecretNamespace,
+		Expires:               clonePtr(cert.Expires),
+		Issuer:                cert.Issuer,
+		DNSNames:              slices.Clone(cert.DNSNames),
+		IPAddresses:           slices.Clone(cert.IPAddresses),
+		HasIPSAN:              cert.HasIPSAN,
+		Fingerprint:           cert.Fingerprint,
+		SerialNumber:          cert.SerialNumber,
+		AuthorityKeyID:        cert.AuthorityKeyID,
+		SubjectKeyID:          cert.SubjectKeyID,
+		ChainLength:           cert.ChainLength,
+		KeyAlgorithm:          cert.KeyAlgorithm,
+		KeyBits:               cert.KeyBits,
+		Version:               cert.Version,
+		PreV3:                 cert.PreV3,
+		State:                 cert.State,
+		ParseError:            cert.ParseError,
+		NoLeafCertificate:     cert.NoLeafCertificate,
+		RenewalLeadDays:       clonePtr(cert.RenewalLeadDays),
+		RenewedLate:           cert.RenewedLate,
+		FirstObservedAt:       cert.FirstObservedAt,
+		SecretResourceVersion: cert.SecretResourceVersion,
+		SecretObservedAt:      cert.SecretObservedAt,
+		NonconformingName:     cert.NonconformingName,
+		IsACME:                cert.IsACME,
+		ACMEProvider:          cert.ACMEProvider,
+	}
+	for _, ca := range cert.CACertificates {
+		certCopy.CACertificates = append(certCopy.CACertificates, *copyCertificate(&ca))
+	}
+	return certCopy
+}
+
+// clonePtr returns a pointer to a copy of the value p points at, so copies
+// don't share pointer fields with the cached entry. It returns nil for nil.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// HostMatches reports whether a host pattern, possibly a wildcard, covers the
+// given host. As in RFC 6125, a wildcard is only allowed as the complete
+// left-most label and matches exactly one label, so *.example.com covers
+// foo.example.com but neither example.com nor foo.bar.example.com. Hosts are
+// compared case-insensitively.
+func HostMatches(pattern, host string) bool {
+	if pattern == "" || host == "" {
+		return false
+	}
+	if strings.EqualFold(pattern, host) {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(pattern, "*.")
+	if !ok {
+		return false
+	}
+	label, rest, found := strings.Cut(host, ".")
+	return found && label != "" && strings.EqualFold(rest, suffix)
+}
+
+// PlaintextHostCount returns the number of hosts across all cached ingresses
+// that are served without any TLS certificate
+func (c *IngressCache) PlaintextHostCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return plaintextHostCount(maps.Values(c.items))
+}
+
+// plaintextHostCount counts the hosts of the given entries served without TLS
+func plaintextHostCount(infos iter.Seq[*IngressInfo]) int {
+	count := 0
+	for info := range infos {
+		for _, host := range info.Hosts {
+			// Skip the placeholder entry for ingresses without any host;
+			// entries from older snapshots only have the empty host
+			if host.NoHost || host.Host == "" {
+				continue
+			}
+			if host.Certificate == nil {
+				count++
+			}
+		}
+	}
+	return count
+}
+
+// UniqueCertificateCount returns the number of distinct certificates across
+// all cached entries. Certificates are identified by namespace and secret
+// name, so a secret shared by several ingresses is counted once.
+func (c *IngressCache) UniqueCertificateCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return uniqueCertificateCount(maps.Values(c.items))
+}
+
+// uniqueCertificateCount counts the distinct certificates of the given entries
+func uniqueCertificateCount(infos iter.Seq[*IngressInfo]) int {
+	seen := make(map[string]bool)
+	for info := range infos {
+		for _, host := range info.Hosts {
 			if host.Certificate != nil {
-				certCopy := &CertificateInfo{
-					Name:    host.Certificate.Name,
-					Expires: host.Certificate.Expires,
+				seen[SecretKey(info, host.Certificate)] = true
+			}
+		}
+	}
+	return len(seen)
+}
+
+// TLSSecretCount returns the number of distinct secrets a certificate was
+// parsed from. Unlike UniqueCertificateCount, referenced secrets that are
+// missing or hold no certificate are not counted.
+func (c *IngressCache) TLSSecretCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, info := range c.items {
+		for _, host := range info.Hosts {
+			if host.Certificate != nil && host.Certificate.ChainLength > 0 {
+				seen[SecretKey(info, host.Certificate)] = true
+			}
+		}
+	}
+	return len(seen)
+}
+
+// NonconformingCertificateCount returns the number of distinct certificates
+// whose secret name doesn't follow the naming convention
+func (c *IngressCache) NonconformingCertificateCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, info := range c.items {
+		for _, host := range info.Hosts {
+			if host.Certificate != nil && host.Certificate.NonconformingName {
+				seen[SecretKey(info, host.Certificate)] = true
+			}
+		}
+	}
+	return len(seen)
+}
+
+// PreV3CertificateCount returns the number of distinct certificates older
+// than X.509v3
+func (c *IngressCache) PreV3CertificateCount() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	seen := make(map[string]bool)
+	for _, info := range c.items {
+		for _, host := range info.Hosts {
+			if host.Certificate != nil && host.Certificate.PreV3 {
+				seen[SecretKey(info, host.Certificate)] = true
+			}
+		}
+	}
+	return len(seen)
+}
+
+// ACMECertificateCounts returns the number of distinct ACME-issued
+// certificates per provider
+func (c *IngressCache) ACMECertificateCounts() map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	seen := make(map[string]string)
+	for _, info := range c.items {
+		for _, host := range info.Hosts {
+			if host.Certificate != nil && host.Certificate.IsACME {
+				seen[SecretKey(info, host.Certificate)] = host.Certificate.ACMEProvider
+			}
+		}
+	}
+	counts := make(map[string]int)
+	for _, provider := range seen {
+		counts[provider]++
+	}
+	return counts
+}
+
+// KeyAlgorithmCounts returns the number of distinct certificates per public
+// key algorithm and size, keyed by KeyType
+func (c *IngressCache) KeyAlgorithmCounts() map[KeyType]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	seen := make(map[string]KeyType)
+	for _, info := range c.items {
+		for _, host := range info.Hosts {
+			if host.Certificate != nil && host.Certificate.KeyAlgorithm != "" {
+				seen[SecretKey(info, host.Certificate)] = KeyType{
+					Algorithm: host.Certificate.KeyAlgorithm,
+					Bits:      host.Certificate.KeyBits,
 				}
-				infoCopy.Hosts[i].Certificate = certCopy
 			}
 		}
-		result = append(result, infoCopy)
 	}
-	return result
+	counts := make(map[KeyType]int)
+	for _, key := range seen {
+		counts[key]++
+	}
+	return counts
+}
+
+// KeyType is a public key algorithm and size
+type KeyType struct {
+	Algorithm string
+	Bits      int
+}
+
+// CountByStatus returns the number of unique certificates in each expiry
+// status. Certificates shared by several hosts or ingresses in the same
+// namespace are counted once; certificates without a known expiry are skipped.
+func (c *IngressCache) CountByStatus(thresholds ExpiryThresholds, now time.Time) map[string]int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	counts := make(map[string]int, len(Statuses))
+	for _, status := range Statuses {
+		counts[status] = 0
+	}
+
+	seen := make(map[string]bool)
+	for _, info := range c.items {
+		for _, host := range info.Hosts {
+			if host.Certificate == nil || host.Certificate.Expires == nil {
+				continue
+			}
+			key := SecretKey(info, host.Certificate)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			counts[thresholds.Status(*host.Certificate.Expires, now)]++
+		}
+	}
+	return counts
+}
+
+// SecretKey identifies the secret behind a certificate as namespace/name.
+// Secrets without a recorded namespace are in the namespace of the entry.
+func SecretKey(info *IngressInfo, cert *CertificateInfo) string {
+	return cert.SecretNamespaceOr(info.Namespace) + "/" + cert.Name
+}
+
+// SecretNamespaceOr returns the namespace of the source secret, or
+// defaultNamespace when it isn't recorded, e.g. in older snapshots
+func (c *CertificateInfo) SecretNamespaceOr(defaultNamespace string) string {
+	if c.SecretNamespace != "" {
+		return c.SecretNamespace
+	}
+	return defaultNamespace
 }
 
 // makeKey creates a unique key for cache storage
-func makeKey(clusterName, namespace, name string) string {
+func makeKey(clusterName, kind, namespace, name string) string {
+	if kind != "" {
+		return clusterName + "/" + kind + "/" + namespace + "/" + name
+	}
 	return clusterName + "/" + namespace + "/" + name
 }
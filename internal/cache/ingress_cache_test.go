@@ -1,8 +1,15 @@
 package cache
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 )
 
 func TestNewIngressCache(t *testing.T) {
@@ -91,6 +98,7 @@ func TestIngressCache_Concurrency(t *testing.T) {
 		go func() {
 			defer wg.Done()
 			cache.GetAll()
+			cache.GetAllShared()
 		}()
 	}
 
@@ -115,6 +123,8 @@ func TestIngressCache_DeepCopy(t *testing.T) {
 				Certificate: &CertificateInfo{
 					Name: "webapp-tls",
 				},
+				TLSEnabled:  true,
+				SSLRedirect: true,
 			},
 		},
 	}
@@ -130,4 +140,964 @@ func TestIngressCache_DeepCopy(t *testing.T) {
 	if cached.Hosts[0].Host != "webapp.local" {
 		t.Error("GetAll did not return a deep copy, original was modified")
 	}
+	if !cached.Hosts[0].TLSEnabled || !cached.Hosts[0].SSLRedirect {
+		t.Errorf("copied host = %+v, want TLSEnabled and SSLRedirect kept", cached.Hosts[0])
+	}
+}
+
+func TestIngressCache_GetAllShared(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+	original := &IngressInfo{Namespace: "default", Name: "webapp", Hosts: []HostInfo{{Host: "webapp.local"}}}
+	cache.Add(original)
+
+	first := cache.GetAllShared()
+	if len(first) != 1 || first[0] == original {
+		t.Fatalf("GetAllShared() = %v, want a copy of the cached entry", first)
+	}
+	if second := cache.GetAllShared(); &second[0] != &first[0] {
+		t.Error("GetAllShared() copied an unchanged cache again")
+	}
+
+	mutations := []struct {
+		name   string
+		mutate func()
+		want   int
+	}{
+		{name: "add", mutate: func() {
+			cache.Add(&IngressInfo{Namespace: "default", Name: "api", Hosts: []HostInfo{{Host: "api.local"}}})
+		}, want: 2},
+		{name: "delete", mutate: func() { cache.Delete("default", "api") }, want: 1},
+		{name: "prune", mutate: func() { cache.Prune("", nil, time.Now()) }, want: 0},
+	}
+	for _, m := range mutations {
+		before := cache.GetAllShared()
+		m.mutate()
+		after := cache.GetAllShared()
+		if len(after) != m.want {
+			t.Errorf("after %s: GetAllShared() has %d entries, want %d", m.name, len(after), m.want)
+		}
+		if len(before) > 0 && len(after) > 0 && &before[0] == &after[0] {
+			t.Errorf("after %s: GetAllShared() returned the stale copies", m.name)
+		}
+		if cache.Len() != m.want {
+			t.Errorf("after %s: Len() = %d, want %d", m.name, cache.Len(), m.want)
+		}
+	}
+}
+
+// checkCountingContext is a context that is done from its nth Err check on,
+// cancelling an operation midway
+type checkCountingContext struct {
+	context.Context
+	checks, n int
+}
+
+func (c *checkCountingContext) Err() error {
+	c.checks++
+	if c.checks >= c.n {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestIngressCache_GetAllContext(t *testing.T) {
+	cache := newBenchmarkCache(3 * contextCheckInterval)
+
+	all, err := cache.GetAllContext(context.Background())
+	if err != nil || len(all) != 3*contextCheckInterval {
+		t.Fatalf("GetAllContext() = %d entries, %v, want %d entries", len(all), err, 3*contextCheckInterval)
+	}
+
+	ctx := &checkCountingContext{Context: context.Background(), n: 2}
+	all, err = cache.GetAllContext(ctx)
+	if !errors.Is(err, context.Canceled) || all != nil {
+		t.Errorf("GetAllContext() cancelled midway = %d entries, %v, want context.Canceled", len(all), err)
+	}
+	if ctx.checks != 2 {
+		t.Errorf("context checked %d times, want copying to stop at the cancelling check", ctx.checks)
+	}
+
+	view := cache.View(func(*IngressInfo) bool { return true })
+	if _, err := view.GetAllContext(&checkCountingContext{Context: context.Background(), n: 2}); !errors.Is(err, context.Canceled) {
+		t.Errorf("View.GetAllContext() cancelled midway error = %v, want context.Canceled", err)
+	}
+}
+
+func TestIngressCache_PlaintextHostCount(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "mixed",
+		Hosts: []HostInfo{
+			{Host: "secure.local", Certificate: &CertificateInfo{Name: "secure-tls"}},
+			{Host: "plain.local"},
+		},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "plain",
+		Hosts:     []HostInfo{{Host: "other.local"}},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "missing-secret",
+		Hosts: []HostInfo{
+			// Certificate without expiry still counts as TLS
+			{Host: "broken.local", Certificate: &CertificateInfo{Name: "missing-tls"}},
+		},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "no-host",
+		Hosts:     []HostInfo{{NoHost: true}},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "snapshot-no-host",
+		// Placeholder restored from a snapshot without the NoHost flag
+		Hosts: []HostInfo{{Host: ""}},
+	})
+
+	if got := cache.PlaintextHostCount(); got != 2 {
+		t.Errorf("PlaintextHostCount() = %d, want 2", got)
+	}
+}
+
+func TestIngressCache_CertificateIdentity(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+	fingerprint := "632e0db9996a09bf36e46c6f3e1cf7b17947544caac5846ba936002130b953de"
+	serial := "58f181d5f67112fbf5b4226815d622b84b7530af"
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{{
+			Host:        "webapp.local",
+			Certificate: &CertificateInfo{Name: "webapp-tls", Fingerprint: fingerprint, SerialNumber: serial},
+		}},
+	})
+
+	// Both survive the copy made by GetAll and a JSON round trip
+	data, err := json.Marshal(cache.GetAll()[0])
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"fingerprint":"`+fingerprint+`"`) ||
+		!strings.Contains(string(data), `"serialNumber":"`+serial+`"`) {
+		t.Errorf("JSON = %s, want fingerprint and serialNumber", data)
+	}
+	var decoded IngressInfo
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	cert := decoded.Hosts[0].Certificate
+	if cert.Fingerprint != fingerprint || cert.SerialNumber != serial {
+		t.Errorf("Fingerprint, SerialNumber = %v, %v, want %v, %v", cert.Fingerprint, cert.SerialNumber, fingerprint, serial)
+	}
+}
+
+func TestIngressCache_SecretFreshness(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+	observedAt := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{
+			{Host: "webapp.local", Certificate: &CertificateInfo{
+				Name:                  "webapp-tls",
+				SecretResourceVersion: "4711",
+				SecretObservedAt:      observedAt,
+			}},
+			{Host: "pending.local", Certificate: &CertificateInfo{Name: "pending-tls"}},
+		},
+	})
+
+	copied := cache.GetAll()[0]
+	cert := copied.Hosts[0].Certificate
+	if cert.SecretResourceVersion != "4711" || !cert.SecretObservedAt.Equal(observedAt) {
+		t.Errorf("copy has SecretResourceVersion, SecretObservedAt = %q, %v, want 4711, %v",
+			cert.SecretResourceVersion, cert.SecretObservedAt, observedAt)
+	}
+
+	data, err := json.Marshal(copied)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	if !strings.Contains(string(data), `"secretResourceVersion":"4711","secretObservedAt":"2025-06-01T12:00:00Z"`) {
+		t.Errorf("JSON = %s, want secretResourceVersion and secretObservedAt", data)
+	}
+	// Unread secrets have neither
+	if strings.Count(string(data), "secretResourceVersion") != 1 || strings.Count(string(data), "secretObservedAt") != 1 {
+		t.Errorf("JSON = %s, want the fields omitted for the unread secret", data)
+	}
+	var decoded IngressInfo
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal: %v", err)
+	}
+	if got := decoded.Hosts[0].Certificate; got.SecretResourceVersion != "4711" || !got.SecretObservedAt.Equal(observedAt) {
+		t.Errorf("decoded SecretResourceVersion, SecretObservedAt = %q, %v", got.SecretResourceVersion, got.SecretObservedAt)
+	}
+}
+
+func TestIngressCache_DeepCopyPointers(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+
+	expires := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	caExpires := time.Date(2035, 1, 1, 0, 0, 0, 0, time.UTC)
+	leadDays := 20
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{{
+			Host: "webapp.local",
+			Certificate: &CertificateInfo{
+				Name:            "webapp-tls",
+				Expires:         &expires,
+				RenewalLeadDays: &leadDays,
+				CACertificates:  []CertificateInfo{{Name: "CN=Client CA", Expires: &caExpires}},
+			},
+		}},
+	})
+
+	// Mutate the values the retrieved pointers point at
+	retrieved := cache.GetAll()[0].Hosts[0].Certificate
+	*retrieved.Expires = time.Time{}
+	*retrieved.RenewalLeadDays = -1
+	*retrieved.CACertificates[0].Expires = time.Time{}
+	retrieved.CACertificates[0].Name = "modified"
+
+	cached := cache.GetAll()[0].Hosts[0].Certificate
+	if !cached.Expires.Equal(expires) {
+		t.Errorf("cached Expires = %v, want %v", cached.Expires, expires)
+	}
+	if *cached.RenewalLeadDays != 20 {
+		t.Errorf("cached RenewalLeadDays = %d, want 20", *cached.RenewalLeadDays)
+	}
+	if cached.Expires == retrieved.Expires {
+		t.Error("copies share the Expires pointer")
+	}
+	if ca := cached.CACertificates[0]; ca.Name != "CN=Client CA" || !ca.Expires.Equal(caExpires) {
+		t.Errorf("cached CA certificate = %s expiring %v, want CN=Client CA expiring %v", ca.Name, ca.Expires, caExpires)
+	}
+}
+
+func TestIngressCache_KindsDoNotCollide(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+
+	cache.Add(&IngressInfo{Namespace: "default", Name: "web", Hosts: []HostInfo{{Host: "web.local"}}})
+	cache.Add(&IngressInfo{Kind: KindGateway, Namespace: "default", Name: "web", Hosts: []HostInfo{{Host: "gw.local", Listener: "https"}}})
+
+	if len(cache.GetAll()) != 2 {
+		t.Fatalf("GetAll() returned %d items, want 2", len(cache.GetAll()))
+	}
+
+	cache.DeleteKind(KindGateway, "default", "web")
+	all := cache.GetAll()
+	if len(all) != 1 || all[0].Kind != "" {
+		t.Errorf("DeleteKind removed the wrong entry, remaining: %v", all)
+	}
+}
+
+func newQueryCache() *IngressCache {
+	cache := NewIngressCache("test-cluster")
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts:     []HostInfo{{Host: "webapp.example.com", Certificate: &CertificateInfo{Name: "webapp-tls"}}},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "wildcard",
+		Hosts:     []HostInfo{{Host: "*.example.com", Certificate: &CertificateInfo{Name: "wildcard-tls"}}},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "team-a",
+		Name:      "api",
+		Hosts:     []HostInfo{{Host: "api.team-a.io"}, {Host: "API.example.com"}},
+	})
+	return cache
+}
+
+func names(infos []*IngressInfo) []string {
+	result := make([]string, 0, len(infos))
+	for _, info := range infos {
+		result = append(result, info.Namespace+"/"+info.Name)
+	}
+	slices.Sort(result)
+	return result
+}
+
+func TestIngressCache_GetByNamespace(t *testing.T) {
+	cache := newQueryCache()
+
+	tests := []struct {
+		namespace string
+		want      []string
+	}{
+		{namespace: "default", want: []string{"default/webapp", "default/wildcard"}},
+		{namespace: "team-a", want: []string{"team-a/api"}},
+		{namespace: "missing", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			if got := names(cache.GetByNamespace(tt.namespace)); !slices.Equal(got, tt.want) {
+				t.Errorf("GetByNamespace(%q) = %v, want %v", tt.namespace, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngressCache_GetByHost(t *testing.T) {
+	cache := newQueryCache()
+
+	tests := []struct {
+		name string
+		host string
+		want []string
+	}{
+		{name: "exact match", host: "api.team-a.io", want: []string{"team-a/api"}},
+		{name: "case insensitive", host: "WEBAPP.example.com", want: []string{"default/webapp", "default/wildcard"}},
+		{name: "wildcard and explicit host", host: "api.example.com", want: []string{"default/wildcard", "team-a/api"}},
+		{name: "wildcard only", host: "blog.example.com", want: []string{"default/wildcard"}},
+		{name: "wildcard does not match nested labels", host: "a.b.example.com", want: []string{}},
+		{name: "wildcard does not match apex", host: "example.com", want: []string{}},
+		{name: "miss", host: "unknown.org", want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := names(cache.GetByHost(tt.host)); !slices.Equal(got, tt.want) {
+				t.Errorf("GetByHost(%q) = %v, want %v", tt.host, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngressCache_GetByHostDeepCopy(t *testing.T) {
+	cache := newQueryCache()
+
+	result := cache.GetByHost("api.team-a.io")
+	result[0].Hosts[0].Host = "modified.local"
+
+	if got := cache.GetByHost("api.team-a.io"); len(got) != 1 {
+		t.Error("GetByHost did not return a deep copy, original was modified")
+	}
+}
+
+func TestExpiryThresholds_Status(t *testing.T) {
+	now := time.Now()
+	thresholds := ExpiryThresholds{Warning: 30 * 24 * time.Hour, Critical: 7 * 24 * time.Hour}
+
+	tests := []struct {
+		name    string
+		expires time.Time
+		want    string
+	}{
+		{name: "ok", expires: now.Add(60 * 24 * time.Hour), want: StatusOK},
+		{name: "warning", expires: now.Add(20 * 24 * time.Hour), want: StatusWarning},
+		{name: "critical", expires: now.Add(24 * time.Hour), want: StatusCritical},
+		{name: "expired", expires: now.Add(-time.Hour), want: StatusExpired},
+		{name: "expires now", expires: now, want: StatusExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := thresholds.Status(tt.expires, now); got != tt.want {
+				t.Errorf("Status() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngressCache_CountByStatus(t *testing.T) {
+	now := time.Now()
+	thresholds := ExpiryThresholds{Warning: 30 * 24 * time.Hour, Critical: 7 * 24 * time.Hour}
+	at := func(d time.Duration) *time.Time {
+		expires := now.Add(d)
+		return &expires
+	}
+
+	cache := NewIngressCache("test-cluster")
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{
+			{Host: "a.local", Certificate: &CertificateInfo{Name: "ok-tls", Expires: at(90 * 24 * time.Hour)}},
+			// Same certificate on another host is counted once
+			{Host: "b.local", Certificate: &CertificateInfo{Name: "ok-tls", Expires: at(90 * 24 * time.Hour)}},
+			{Host: "c.local", Certificate: &CertificateInfo{Name: "warning-tls", Expires: at(14 * 24 * time.Hour)}},
+			{Host: "d.local", Certificate: &CertificateInfo{Name: "unknown-tls"}},
+			{Host: "e.local"},
+		},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "team-a",
+		Name:      "api",
+		Hosts: []HostInfo{
+			// Same secret name in another namespace is a different certificate
+			{Host: "f.local", Certificate: &CertificateInfo{Name: "ok-tls", Expires: at(60 * 24 * time.Hour)}},
+			{Host: "g.local", Certificate: &CertificateInfo{Name: "critical-tls", Expires: at(2 * 24 * time.Hour)}},
+			{Host: "h.local", Certificate: &CertificateInfo{Name: "expired-tls", Expires: at(-24 * time.Hour)}},
+		},
+	})
+
+	want := map[string]int{StatusOK: 2, StatusWarning: 1, StatusCritical: 1, StatusExpired: 1}
+	got := cache.CountByStatus(thresholds, now)
+	for status, count := range want {
+		if got[status] != count {
+			t.Errorf("CountByStatus()[%s] = %d, want %d", status, got[status], count)
+		}
+	}
+}
+
+func TestIngressCache_Prune(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+
+	cache.Add(&IngressInfo{Namespace: "default", Name: "live", Hosts: []HostInfo{{Host: "live.local"}}})
+	cache.Add(&IngressInfo{Namespace: "default", Name: "orphan", Hosts: []HostInfo{{Host: "orphan.local"}}})
+	cache.Add(&IngressInfo{Namespace: "team-a", Name: "live", Hosts: []HostInfo{{Host: "other.local"}}})
+	cache.Add(&IngressInfo{Kind: KindGateway, Namespace: "default", Name: "gateway", Hosts: []HostInfo{{Host: "gw.local"}}})
+	listedAt := time.Now()
+
+	removed := cache.Prune("", []string{"default/live"}, listedAt)
+	if removed != 2 {
+		t.Errorf("Prune() removed %d entries, want 2", removed)
+	}
+
+	got := names(cache.GetAll())
+	want := []string{"default/gateway", "default/live"}
+	if !slices.Equal(got, want) {
+		t.Errorf("remaining entries = %v, want %v", got, want)
+	}
+}
+
+func TestIngressCache_PruneKeepsEntriesWrittenAfterListing(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+
+	listedAt := time.Now().Add(-time.Second)
+	// Reconciled after the live list was taken, so it isn't in it yet
+	cache.Add(&IngressInfo{Namespace: "default", Name: "new", Hosts: []HostInfo{{Host: "new.local"}}})
+
+	if removed := cache.Prune("", nil, listedAt); removed != 0 {
+		t.Errorf("Prune() removed %d entries, want 0", removed)
+	}
+	if len(cache.GetAll()) != 1 {
+		t.Error("entry written after listing was pruned")
+	}
+}
+
+func TestIngressCache_Tombstones(t *testing.T) {
+	cache := NewIngressCache("test-cluster").WithTombstones(time.Minute)
+
+	cache.Add(&IngressInfo{Namespace: "default", Name: "webapp", Hosts: []HostInfo{{Host: "webapp.local"}}})
+	cache.Add(&IngressInfo{Namespace: "default", Name: "orphan", Hosts: []HostInfo{{Host: "orphan.local"}}})
+	before := time.Now().Add(-time.Second)
+	cache.Delete("default", "webapp")
+	cache.Prune("", nil, time.Now().Add(time.Second))
+	now := time.Now()
+
+	got := cache.DeletedSince(before, now)
+	if !slices.Equal(names(got), []string{"default/orphan", "default/webapp"}) {
+		t.Fatalf("DeletedSince() = %v, want both deleted entries", names(got))
+	}
+	for _, info := range got {
+		if !info.Deleted || len(info.Hosts) != 1 {
+			t.Errorf("tombstone %s = %+v, want the deleted entry marked Deleted", info.Name, info)
+		}
+	}
+
+	if got := cache.DeletedSince(now, now); len(got) != 0 {
+		t.Errorf("DeletedSince(now) = %v, want none after the deletions", names(got))
+	}
+	if got := cache.DeletedSince(before, now.Add(2*time.Minute)); len(got) != 0 {
+		t.Errorf("DeletedSince() past the TTL = %v, want none", names(got))
+	}
+
+	// Re-creating an entry removes its tombstone
+	cache.Add(&IngressInfo{Namespace: "default", Name: "webapp", Hosts: []HostInfo{{Host: "webapp.local"}}})
+	if got := names(cache.DeletedSince(before, now)); !slices.Equal(got, []string{"default/orphan"}) {
+		t.Errorf("DeletedSince() after re-creating = %v, want [default/orphan]", got)
+	}
+}
+
+func TestIngressCache_TombstonesDisabled(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+
+	cache.Add(&IngressInfo{Namespace: "default", Name: "webapp"})
+	cache.Delete("default", "webapp")
+
+	if got := cache.DeletedSince(time.Time{}, time.Now()); len(got) != 0 {
+		t.Errorf("DeletedSince() = %v, want none without WithTombstones", names(got))
+	}
+}
+
+// waitForLen polls the cache until it holds want entries or a second passes
+func waitForLen(t *testing.T, cache *IngressCache, want int) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for cache.Len() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("Len() = %d, want %d", cache.Len(), want)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestIngressCache_DeletionGrace(t *testing.T) {
+	const grace = 50 * time.Millisecond
+	webapp := func() *IngressInfo {
+		return &IngressInfo{Namespace: "default", Name: "webapp", Hosts: []HostInfo{{Host: "webapp.local"}}}
+	}
+
+	t.Run("re-added within the window", func(t *testing.T) {
+		cache := NewIngressCache("test-cluster").WithTombstones(time.Minute).WithDeletionGrace(grace)
+		before := time.Now()
+		cache.Add(webapp())
+
+		cache.Delete("default", "webapp")
+		if got := names(cache.GetAll()); !slices.Equal(got, []string{"default/webapp"}) {
+			t.Fatalf("GetAll() pending deletion = %v, want the entry kept", got)
+		}
+		cache.Add(webapp())
+
+		time.Sleep(2 * grace)
+		if cache.Len() != 1 {
+			t.Errorf("Len() after the window = %d, want the re-added entry", cache.Len())
+		}
+		if got := cache.DeletedSince(before, time.Now()); len(got) != 0 {
+			t.Errorf("DeletedSince() = %v, want no tombstone for the re-added entry", names(got))
+		}
+	})
+
+	t.Run("not re-added within the window", func(t *testing.T) {
+		cache := NewIngressCache("test-cluster").WithTombstones(time.Minute).WithDeletionGrace(grace)
+		cache.Add(webapp())
+
+		cache.Delete("default", "webapp")
+		evictedAfter := time.Now()
+		waitForLen(t, cache, 0)
+		if got := names(cache.DeletedSince(evictedAfter, time.Now())); !slices.Equal(got, []string{"default/webapp"}) {
+			t.Errorf("DeletedSince() = %v, want a tombstone dated at eviction", got)
+		}
+	})
+
+	t.Run("deleted again after re-adding", func(t *testing.T) {
+		// A longer window leaves room for checking between the two evictions
+		const grace = 4 * grace
+		cache := NewIngressCache("test-cluster").WithDeletionGrace(grace)
+		cache.Add(webapp())
+		cache.Delete("default", "webapp")
+		cache.Add(webapp())
+		time.Sleep(grace / 2)
+
+		// The first deletion's window must not evict the entry early
+		cache.Delete("default", "webapp")
+		time.Sleep(grace/2 + grace/4)
+		if cache.Len() != 1 {
+			t.Fatalf("Len() within the second window = %d, want 1", cache.Len())
+		}
+		waitForLen(t, cache, 0)
+	})
+
+	t.Run("no window", func(t *testing.T) {
+		cache := NewIngressCache("test-cluster")
+		cache.Add(webapp())
+		cache.Delete("default", "webapp")
+		if cache.Len() != 0 {
+			t.Errorf("Len() = %d, want the entry evicted right away", cache.Len())
+		}
+	})
+}
+
+func TestIngressCache_UniqueCertificateCount(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+
+	// Shared certificate across hosts and ingresses in one namespace
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{
+			{Host: "a.local", Certificate: &CertificateInfo{Name: "shared-tls"}},
+			{Host: "b.local", Certificate: &CertificateInfo{Name: "shared-tls"}},
+			{Host: "plain.local"},
+		},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "multi-host",
+		Hosts: []HostInfo{
+			{Host: "c.local", Certificate: &CertificateInfo{Name: "shared-tls"}},
+			{Host: "d.local", Certificate: &CertificateInfo{Name: "api-tls"}},
+		},
+	})
+	// Same secret name in another namespace is a distinct certificate
+	cache.Add(&IngressInfo{
+		Namespace: "team-a",
+		Name:      "webapp",
+		Hosts:     []HostInfo{{Host: "e.local", Certificate: &CertificateInfo{Name: "shared-tls"}}},
+	})
+
+	if got := cache.UniqueCertificateCount(); got != 3 {
+		t.Errorf("UniqueCertificateCount() = %d, want 3", got)
+	}
+}
+
+func TestIngressCache_TLSSecretCount(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+
+	// A parsed secret shared across hosts and ingresses is counted once
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{
+			{Host: "a.local", Certificate: &CertificateInfo{Name: "shared-tls", ChainLength: 2}},
+			{Host: "b.local", Certificate: &CertificateInfo{Name: "shared-tls", ChainLength: 2}},
+			{Host: "plain.local"},
+		},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "api",
+		Hosts: []HostInfo{
+			{Host: "c.local", Certificate: &CertificateInfo{Name: "shared-tls", ChainLength: 2}},
+			{Host: "d.local", Certificate: &CertificateInfo{Name: "api-tls", ChainLength: 1}},
+			// Referenced but missing or unparseable secrets are not counted
+			{Host: "e.local", Certificate: &CertificateInfo{Name: "missing-tls"}},
+		},
+	})
+	// Same secret name in another namespace is a distinct secret
+	cache.Add(&IngressInfo{
+		Namespace: "team-a",
+		Name:      "webapp",
+		Hosts:     []HostInfo{{Host: "f.local", Certificate: &CertificateInfo{Name: "shared-tls", ChainLength: 1}}},
+	})
+
+	if got := cache.TLSSecretCount(); got != 3 {
+		t.Errorf("TLSSecretCount() = %d, want 3", got)
+	}
+	if got := cache.UniqueCertificateCount(); got != 4 {
+		t.Errorf("UniqueCertificateCount() = %d, want 4", got)
+	}
+}
+
+func TestIngressCache_GetExpiringSoon(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		expires := now.Add(d)
+		return &expires
+	}
+
+	cache := NewIngressCache("test-cluster")
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "soon",
+		Hosts: []HostInfo{
+			{Host: "ok.local", Certificate: &CertificateInfo{Name: "ok-tls", Expires: at(90 * 24 * time.Hour)}},
+			{Host: "soon.local", Certificate: &CertificateInfo{Name: "soon-tls", Expires: at(5 * 24 * time.Hour)}},
+		},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "expired",
+		Hosts:     []HostInfo{{Host: "old.local", Certificate: &CertificateInfo{Name: "old-tls", Expires: at(-time.Hour)}}},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "later",
+		Hosts:     []HostInfo{{Host: "later.local", Certificate: &CertificateInfo{Name: "later-tls", Expires: at(30 * 24 * time.Hour)}}},
+	})
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "unknown",
+		Hosts:     []HostInfo{{Host: "plain.local"}, {Host: "new.local", Certificate: &CertificateInfo{Name: "new-tls"}}},
+	})
+
+	got := names(cache.GetExpiringSoon(14*24*time.Hour, now))
+	want := []string{"default/expired", "default/soon"}
+	if !slices.Equal(got, want) {
+		t.Errorf("GetExpiringSoon() = %v, want %v", got, want)
+	}
+}
+
+func TestIngressCache_SecretNamespace(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+
+	// Same-namespace reference, recorded explicitly and implicitly
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{
+			{Host: "a.local", Certificate: &CertificateInfo{Name: "shared-tls", SecretNamespace: "default"}},
+			{Host: "b.local", Certificate: &CertificateInfo{Name: "shared-tls"}},
+		},
+	})
+	// Cross-namespace reference to a secret of the same name
+	cache.Add(&IngressInfo{
+		Kind:      KindGateway,
+		Namespace: "default",
+		Name:      "gateway",
+		Hosts:     []HostInfo{{Host: "c.local", Certificate: &CertificateInfo{Name: "shared-tls", SecretNamespace: "certs"}}},
+	})
+
+	if got := cache.UniqueCertificateCount(); got != 2 {
+		t.Errorf("UniqueCertificateCount() = %d, want 2", got)
+	}
+
+	for _, info := range cache.GetAll() {
+		for _, host := range info.Hosts {
+			want := "default"
+			if info.Kind == KindGateway {
+				want = "certs"
+			}
+			if got := host.Certificate.SecretNamespaceOr(info.Namespace); got != want {
+				t.Errorf("%s %s: SecretNamespaceOr() = %q, want %q", info.Name, host.Host, got, want)
+			}
+		}
+	}
+}
+
+func TestIngressCache_FirstObservedAt(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+	add := func(name, serial string) time.Time {
+		t.Helper()
+		cache.Add(&IngressInfo{
+			Namespace: "default",
+			Name:      name,
+			Hosts: []HostInfo{{
+				Host:        name + ".local",
+				Certificate: &CertificateInfo{Name: "shared-tls", SerialNumber: serial},
+			}},
+		})
+		for _, info := range cache.GetByNamespace("default") {
+			if info.Name == name {
+				return info.Hosts[0].Certificate.FirstObservedAt
+			}
+		}
+		t.Fatalf("entry %s not cached", name)
+		return time.Time{}
+	}
+
+	first := add("webapp", "1a")
+	if first.IsZero() {
+		t.Fatal("FirstObservedAt not set on first Add")
+	}
+
+	time.Sleep(2 * time.Millisecond)
+	if got := add("webapp", "1a"); !got.Equal(first) {
+		t.Errorf("FirstObservedAt after update with same serial = %v, want %v", got, first)
+	}
+	// Another entry referencing the same secret sees the same time
+	if got := add("api", "1a"); !got.Equal(first) {
+		t.Errorf("FirstObservedAt for shared secret = %v, want %v", got, first)
+	}
+
+	rotated := add("webapp", "2b")
+	if !rotated.After(first) {
+		t.Errorf("FirstObservedAt after serial change = %v, want after %v", rotated, first)
+	}
+
+	// Once no entry references the secret, its history is dropped
+	cache.Delete("default", "webapp")
+	cache.Delete("default", "api")
+	time.Sleep(2 * time.Millisecond)
+	if got := add("webapp", "2b"); !got.After(rotated) {
+		t.Errorf("FirstObservedAt after re-adding = %v, want after %v", got, rotated)
+	}
+}
+
+func TestIngressCache_FirstObservedAtRestored(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+	restored := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{{
+			Host:        "webapp.local",
+			Certificate: &CertificateInfo{Name: "webapp-tls", SerialNumber: "1a", FirstObservedAt: restored},
+		}},
+	})
+
+	// A reconcile after the restore parses the secret again without the time
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{{
+			Host:        "webapp.local",
+			Certificate: &CertificateInfo{Name: "webapp-tls", SerialNumber: "1a"},
+		}},
+	})
+
+	got := cache.GetAll()[0].Hosts[0].Certificate.FirstObservedAt
+	if !got.Equal(restored) {
+		t.Errorf("FirstObservedAt = %v, want restored %v", got, restored)
+	}
+}
+
+func TestIngressCache_LastChanged(t *testing.T) {
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	renewed := expires.AddDate(0, 3, 0)
+	entry := func(host string, expires time.Time) *IngressInfo {
+		return &IngressInfo{
+			Namespace: "default",
+			Name:      "webapp",
+			Hosts: []HostInfo{{
+				Host: host,
+				Certificate: &CertificateInfo{
+					Name:                  "webapp-tls",
+					Expires:               &expires,
+					DNSNames:              []string{host},
+					SerialNumber:          "1a",
+					SecretResourceVersion: "100",
+					// Each reconcile reads the secret again
+					SecretObservedAt: time.Now(),
+				},
+			}},
+		}
+	}
+
+	tests := []struct {
+		name        string
+		update      *IngressInfo
+		wantChanged bool
+	}{
+		{name: "unchanged", update: entry("webapp.local", expires)},
+		{
+			name: "secret updated without certificate change",
+			update: func() *IngressInfo {
+				info := entry("webapp.local", expires)
+				info.Hosts[0].Certificate.SecretResourceVersion = "101"
+				return info
+			}(),
+		},
+		{name: "host changed", update: entry("shop.local", expires), wantChanged: true},
+		{name: "certificate expiry changed", update: entry("webapp.local", renewed), wantChanged: true},
+		{
+			name: "certificate removed",
+			update: &IngressInfo{
+				Namespace: "default",
+				Name:      "webapp",
+				Hosts:     []HostInfo{{Host: "webapp.local"}},
+			},
+			wantChanged: true,
+		},
+		{
+			name: "host added",
+			update: func() *IngressInfo {
+				info := entry("webapp.local", expires)
+				info.Hosts = append(info.Hosts, HostInfo{Host: "shop.local"})
+				return info
+			}(),
+			wantChanged: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := NewIngressCache("test-cluster")
+			cache.Add(entry("webapp.local", expires))
+			first := cache.GetAll()[0].LastChanged
+			if first.IsZero() {
+				t.Fatal("LastChanged not set on first Add")
+			}
+
+			time.Sleep(2 * time.Millisecond)
+			cache.Add(tt.update)
+			got := cache.GetAll()[0].LastChanged
+			if tt.wantChanged && !got.After(first) {
+				t.Errorf("LastChanged = %v, want after %v", got, first)
+			}
+			if !tt.wantChanged && !got.Equal(first) {
+				t.Errorf("LastChanged = %v, want unchanged %v", got, first)
+			}
+		})
+	}
+}
+
+func TestIngressCache_LastChangedHostOrder(t *testing.T) {
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	renewed := expires.AddDate(0, 3, 0)
+	host := func(name string, expires time.Time) HostInfo {
+		return HostInfo{Host: name, Certificate: &CertificateInfo{Name: name + "-tls", Expires: &expires}}
+	}
+	entry := func(hosts ...HostInfo) *IngressInfo {
+		return &IngressInfo{Namespace: "default", Name: "webapp", Hosts: hosts}
+	}
+
+	cache := NewIngressCache("test-cluster")
+	cache.Add(entry(host("webapp.local", expires), host("shop.local", renewed)))
+	first := cache.GetAll()[0].LastChanged
+
+	time.Sleep(2 * time.Millisecond)
+	cache.Add(entry(host("shop.local", renewed), host("webapp.local", expires)))
+	if got := cache.GetAll()[0].LastChanged; !got.Equal(first) {
+		t.Errorf("LastChanged after reordering hosts = %v, want unchanged %v", got, first)
+	}
+
+	cache.Add(entry(host("shop.local", expires), host("webapp.local", renewed)))
+	if got := cache.GetAll()[0].LastChanged; !got.After(first) {
+		t.Errorf("LastChanged after swapping expiries = %v, want after %v", got, first)
+	}
+}
+
+func TestIngressCache_LastChangedRestored(t *testing.T) {
+	cache := NewIngressCache("test-cluster")
+	restored := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache.Add(&IngressInfo{
+		Namespace:   "default",
+		Name:        "webapp",
+		Hosts:       []HostInfo{{Host: "webapp.local"}},
+		LastChanged: restored,
+	})
+
+	// A reconcile after the restore builds the entry again without the time
+	cache.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts:     []HostInfo{{Host: "webapp.local"}},
+	})
+
+	if got := cache.GetAll()[0].LastChanged; !got.Equal(restored) {
+		t.Errorf("LastChanged = %v, want restored %v", got, restored)
+	}
+}
+
+// newBenchmarkCache returns a cache with n entries of two TLS hosts each
+func newBenchmarkCache(n int) *IngressCache {
+	cache := NewIngressCache("bench-cluster")
+	expires := time.Now().Add(30 * 24 * time.Hour)
+	for i := range n {
+		name := "webapp-" + strconv.Itoa(i)
+		cache.Add(&IngressInfo{
+			Namespace:   "default",
+			Name:        name,
+			Labels:      map[string]string{"app": name},
+			SecretCount: 1,
+			Hosts: []HostInfo{
+				{Host: name + ".local", Certificate: &CertificateInfo{Name: name + "-tls", Expires: &expires,
+					DNSNames: []string{name + ".local", "www." + name + ".local"}}},
+				{Host: "www." + name + ".local", Certificate: &CertificateInfo{Name: name + "-tls", Expires: &expires,
+					DNSNames: []string{name + ".local", "www." + name + ".local"}}},
+			},
+		})
+	}
+	return cache
+}
+
+// BenchmarkIngressCache_GetAll and BenchmarkIngressCache_GetAllShared compare
+// back-to-back reads of an unchanged cache, e.g. metrics scrapes
+func BenchmarkIngressCache_GetAll(b *testing.B) {
+	cache := newBenchmarkCache(5000)
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = cache.GetAll()
+	}
+}
+
+func BenchmarkIngressCache_GetAllShared(b *testing.B) {
+	cache := newBenchmarkCache(5000)
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = cache.GetAllShared()
+	}
 }
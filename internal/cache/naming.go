@@ -0,0 +1,23 @@
+package cache
+
+import "regexp"
+
+// SecretNameConvention flags certificates whose secret name doesn't follow
+// the naming convention, e.g. "<app>-tls"
+type SecretNameConvention struct {
+	pattern *regexp.Regexp
+}
+
+// NewSecretNameConvention creates a convention that secret names must match
+func NewSecretNameConvention(pattern *regexp.Regexp) *SecretNameConvention {
+	return &SecretNameConvention{pattern: pattern}
+}
+
+// Check sets NonconformingName on a certificate whose secret name doesn't
+// match the pattern. Check is a no-op on a nil convention.
+func (c *SecretNameConvention) Check(cert *CertificateInfo) {
+	if c == nil || cert == nil {
+		return
+	}
+	cert.NonconformingName = !c.pattern.MatchString(cert.Name)
+}
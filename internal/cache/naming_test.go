@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestSecretNameConvention_Check(t *testing.T) {
+	convention := NewSecretNameConvention(regexp.MustCompile(`^[a-z0-9-]+-tls$`))
+
+	tests := []struct {
+		secret string
+		want   bool
+	}{
+		{secret: "shop-tls", want: false},
+		{secret: "api-v2-tls", want: false},
+		{secret: "shop-cert", want: true},
+		{secret: "wildcard", want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.secret, func(t *testing.T) {
+			// A stale flag from an earlier check must be cleared
+			cert := &CertificateInfo{Name: tt.secret, NonconformingName: true}
+			convention.Check(cert)
+			if cert.NonconformingName != tt.want {
+				t.Errorf("NonconformingName = %v, want %v", cert.NonconformingName, tt.want)
+			}
+		})
+	}
+}
+
+func TestSecretNameConvention_Nil(t *testing.T) {
+	var convention *SecretNameConvention
+	cert := &CertificateInfo{Name: "shop-cert"}
+	convention.Check(cert)
+	if cert.NonconformingName {
+		t.Error("NonconformingName set without a convention")
+	}
+}
+
+func TestIngressCache_NonconformingCertificateCount(t *testing.T) {
+	c := NewIngressCache("test-cluster")
+	c.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "shop",
+		Hosts: []HostInfo{
+			{Host: "shop.local", Certificate: &CertificateInfo{Name: "shop-tls"}},
+			{Host: "legacy.local", Certificate: &CertificateInfo{Name: "legacy-cert", NonconformingName: true}},
+		},
+	})
+	// The same secret referenced again is counted once
+	c.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "legacy",
+		Hosts: []HostInfo{
+			{Host: "old.local", Certificate: &CertificateInfo{Name: "legacy-cert", NonconformingName: true}},
+			{Host: "plain.local"},
+		},
+	})
+	c.Add(&IngressInfo{
+		Namespace: "other",
+		Name:      "legacy",
+		Hosts: []HostInfo{
+			{Host: "other.local", Certificate: &CertificateInfo{Name: "legacy-cert", NonconformingName: true}},
+		},
+	})
+
+	if got := c.NonconformingCertificateCount(); got != 2 {
+		t.Errorf("NonconformingCertificateCount() = %d, want 2", got)
+	}
+}
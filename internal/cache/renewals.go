@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// RenewalHistory tracks the certificate served by each secret to detect
+// renewals and check that they happen early enough before expiry
+type RenewalHistory struct {
+	mu sync.Mutex
+	// minLeadTime is the validity a certificate should have left when it is
+	// renewed; renewals with less lead time are flagged as late
+	minLeadTime time.Duration
+	secrets     map[string]*renewalState
+}
+
+// renewalState is the last observed certificate of a secret and the lead
+// time of its last renewal
+type renewalState struct {
+	serialNumber string
+	expires      time.Time
+	// leadTime is nil until a renewal has been observed
+	leadTime *time.Duration
+}
+
+// NewRenewalHistory creates a RenewalHistory that flags renewals happening
+// less than minLeadTime before expiry
+func NewRenewalHistory(minLeadTime time.Duration) *RenewalHistory {
+	return &RenewalHistory{
+		minLeadTime: minLeadTime,
+		secrets:     make(map[string]*renewalState),
+	}
+}
+
+// Observe records the certificate currently stored in a secret and fills in
+// its renewal fields from the last renewal of that secret. A renewal is
+// detected when the serial number changes and the new certificate expires
+// after the previous one. Its lead time is how long before the previous
+// certificate expired the new one was first observed. defaultNamespace is
+// used when the certificate doesn't record its secret's namespace. Observe
+// is a no-op on a nil history.
+func (h *RenewalHistory) Observe(cert *CertificateInfo, defaultNamespace string, now time.Time) {
+	if h == nil || cert == nil || cert.SerialNumber == "" || cert.Expires == nil {
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	key := cert.SecretNamespaceOr(defaultNamespace) + "/" + cert.Name
+	state, ok := h.secrets[key]
+	if !ok {
+		// First sighting, nothing to compare against yet
+		h.secrets[key] = &renewalState{serialNumber: cert.SerialNumber, expires: *cert.Expires}
+		return
+	}
+
+	if cert.SerialNumber != state.serialNumber {
+		// A replacement that doesn't extend validity, e.g. a rollback, isn't a renewal
+		if cert.Expires.After(state.expires) {
+			leadTime := state.expires.Sub(now)
+			state.leadTime = &leadTime
+		}
+		state.serialNumber = cert.SerialNumber
+		state.expires = *cert.Expires
+	}
+
+	if state.leadTime != nil {
+		days := int(*state.leadTime / (24 * time.Hour))
+		cert.RenewalLeadDays = &days
+		cert.RenewedLate = *state.leadTime < h.minLeadTime
+	}
+}
@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRenewalHistory_Observe(t *testing.T) {
+	day := 24 * time.Hour
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	oldExpiry := start.Add(90 * day)
+	newExpiry := oldExpiry.Add(90 * day)
+
+	tests := []struct {
+		name      string
+		renewedAt time.Time
+		wantDays  int
+		wantLate  bool
+	}{
+		{
+			name:      "renewed on time",
+			renewedAt: oldExpiry.Add(-30 * day),
+			wantDays:  30,
+		},
+		{
+			name:      "renewed late",
+			renewedAt: oldExpiry.Add(-5 * day),
+			wantDays:  5,
+			wantLate:  true,
+		},
+		{
+			name:      "renewed after expiry",
+			renewedAt: oldExpiry.Add(2 * day),
+			wantDays:  -2,
+			wantLate:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			history := NewRenewalHistory(15 * day)
+
+			initial := &CertificateInfo{Name: "webapp-tls", SerialNumber: "1a", Expires: &oldExpiry}
+			history.Observe(initial, "default", start)
+			if initial.RenewalLeadDays != nil || initial.RenewedLate {
+				t.Fatalf("first observation reported a renewal: %+v", initial)
+			}
+
+			renewed := &CertificateInfo{Name: "webapp-tls", SerialNumber: "2b", Expires: &newExpiry}
+			history.Observe(renewed, "default", tt.renewedAt)
+			if renewed.RenewalLeadDays == nil {
+				t.Fatal("RenewalLeadDays not set after renewal")
+			}
+			if *renewed.RenewalLeadDays != tt.wantDays {
+				t.Errorf("RenewalLeadDays = %d, want %d", *renewed.RenewalLeadDays, tt.wantDays)
+			}
+			if renewed.RenewedLate != tt.wantLate {
+				t.Errorf("RenewedLate = %v, want %v", renewed.RenewedLate, tt.wantLate)
+			}
+
+			// Later observations of the same certificate keep reporting the last renewal
+			again := &CertificateInfo{Name: "webapp-tls", SerialNumber: "2b", Expires: &newExpiry}
+			history.Observe(again, "default", tt.renewedAt.Add(day))
+			if again.RenewalLeadDays == nil || *again.RenewalLeadDays != tt.wantDays || again.RenewedLate != tt.wantLate {
+				t.Errorf("repeated observation = %+v, want lead %d days, late %v", again, tt.wantDays, tt.wantLate)
+			}
+		})
+	}
+}
+
+func TestRenewalHistory_NotARenewal(t *testing.T) {
+	day := 24 * time.Hour
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	expires := now.Add(90 * day)
+	earlier := now.Add(60 * day)
+
+	history := NewRenewalHistory(15 * day)
+	history.Observe(&CertificateInfo{Name: "webapp-tls", SerialNumber: "1a", Expires: &expires}, "default", now)
+
+	// Same serial, e.g. an unrelated secret update
+	same := &CertificateInfo{Name: "webapp-tls", SerialNumber: "1a", Expires: &expires}
+	history.Observe(same, "default", now.Add(day))
+	if same.RenewalLeadDays != nil {
+		t.Errorf("unchanged certificate reported a renewal: %+v", same)
+	}
+
+	// New serial without extended validity, e.g. a rollback
+	rollback := &CertificateInfo{Name: "webapp-tls", SerialNumber: "0f", Expires: &earlier}
+	history.Observe(rollback, "default", now.Add(2*day))
+	if rollback.RenewalLeadDays != nil {
+		t.Errorf("rollback reported a renewal: %+v", rollback)
+	}
+
+	// Certificates in other namespaces are tracked separately
+	other := &CertificateInfo{Name: "webapp-tls", SerialNumber: "2b", Expires: &expires}
+	history.Observe(other, "team-a", now.Add(3*day))
+	if other.RenewalLeadDays != nil {
+		t.Errorf("certificate in another namespace reported a renewal: %+v", other)
+	}
+}
+
+func TestRenewalHistory_Nil(t *testing.T) {
+	var history *RenewalHistory
+	expires := time.Now()
+	// Must not panic
+	history.Observe(&CertificateInfo{Name: "webapp-tls", SerialNumber: "1a", Expires: &expires}, "default", time.Now())
+}
@@ -0,0 +1,74 @@
+package cache
+
+import (
+	"maps"
+	"slices"
+	"strings"
+)
+
+// SANConflict is a subject alternative name covered by more than one
+// distinct certificate, e.g. a stale certificate shadowing its replacement
+type SANConflict struct {
+	SAN          string           `json:"san"`
+	Certificates []SANCertificate `json:"certificates"`
+}
+
+// SANCertificate is one of the certificates covering a conflicting SAN, with
+// the secrets it was found in as namespace/name
+type SANCertificate struct {
+	Fingerprint string   `json:"fingerprint"`
+	Secrets     []string `json:"secrets"`
+}
+
+// FindDuplicateSANs returns the DNS and IP address SANs covered by more than
+// one distinct certificate, identified by fingerprint, sorted by SAN. The same
+// certificate copied into several secrets is not a conflict. DNS names are
+// compared case insensitively.
+func (c *IngressCache) FindDuplicateSANs() []SANConflict {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	// SAN -> fingerprint -> secret keys
+	claims := make(map[string]map[string]map[string]bool)
+	claim := func(san, fingerprint, secret string) {
+		if claims[san] == nil {
+			claims[san] = make(map[string]map[string]bool)
+		}
+		if claims[san][fingerprint] == nil {
+			claims[san][fingerprint] = make(map[string]bool)
+		}
+		claims[san][fingerprint][secret] = true
+	}
+	for _, info := range c.items {
+		for _, host := range info.Hosts {
+			cert := host.Certificate
+			if cert == nil || cert.Fingerprint == "" {
+				continue
+			}
+			secret := SecretKey(info, cert)
+			for _, name := range cert.DNSNames {
+				claim(strings.ToLower(name), cert.Fingerprint, secret)
+			}
+			for _, ip := range cert.IPAddresses {
+				claim(ip, cert.Fingerprint, secret)
+			}
+		}
+	}
+
+	var conflicts []SANConflict
+	for _, san := range slices.Sorted(maps.Keys(claims)) {
+		certs := claims[san]
+		if len(certs) < 2 {
+			continue
+		}
+		conflict := SANConflict{SAN: san}
+		for _, fingerprint := range slices.Sorted(maps.Keys(certs)) {
+			conflict.Certificates = append(conflict.Certificates, SANCertificate{
+				Fingerprint: fingerprint,
+				Secrets:     slices.Sorted(maps.Keys(certs[fingerprint])),
+			})
+		}
+		conflicts = append(conflicts, conflict)
+	}
+	return conflicts
+}
@@ -0,0 +1,67 @@
+package cache
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestIngressCache_FindDuplicateSANs(t *testing.T) {
+	c := NewIngressCache("test-cluster")
+	c.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "api",
+		Hosts: []HostInfo{{Host: "api.example.com", Certificate: &CertificateInfo{
+			Name: "api-tls", Fingerprint: "aaaa", DNSNames: []string{"api.example.com", "www.example.com"},
+		}}},
+	})
+	// A stale certificate in another secret still claims api.example.com
+	c.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "api-legacy",
+		Hosts: []HostInfo{{Host: "api.example.com", Certificate: &CertificateInfo{
+			Name: "api-tls-old", Fingerprint: "bbbb", DNSNames: []string{"API.example.com"},
+		}}},
+	})
+	// The same certificate copied into another namespace is not a conflict
+	c.Add(&IngressInfo{
+		Namespace: "team-a",
+		Name:      "www",
+		Hosts: []HostInfo{{Host: "www.example.com", Certificate: &CertificateInfo{
+			Name: "api-tls", Fingerprint: "aaaa", DNSNames: []string{"api.example.com", "www.example.com"},
+		}}},
+	})
+	// Unparsed certificates have no SANs to compare
+	c.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "pending",
+		Hosts:     []HostInfo{{Host: "api.example.com", Certificate: &CertificateInfo{Name: "pending-tls"}}},
+	})
+
+	want := []SANConflict{{
+		SAN: "api.example.com",
+		Certificates: []SANCertificate{
+			{Fingerprint: "aaaa", Secrets: []string{"default/api-tls", "team-a/api-tls"}},
+			{Fingerprint: "bbbb", Secrets: []string{"default/api-tls-old"}},
+		},
+	}}
+	if got := c.FindDuplicateSANs(); !reflect.DeepEqual(got, want) {
+		t.Errorf("FindDuplicateSANs() = %+v, want %+v", got, want)
+	}
+}
+
+func TestIngressCache_FindDuplicateSANs_Clean(t *testing.T) {
+	c := NewIngressCache("test-cluster")
+	c.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{
+			{Host: "webapp.local", Certificate: &CertificateInfo{Name: "webapp-tls", Fingerprint: "aaaa", DNSNames: []string{"webapp.local"}}},
+			{Host: "api.local", Certificate: &CertificateInfo{Name: "api-tls", Fingerprint: "bbbb", DNSNames: []string{"api.local"}, IPAddresses: []string{"10.0.0.1"}}},
+			{Host: "plain.local"},
+		},
+	})
+
+	if got := c.FindDuplicateSANs(); len(got) != 0 {
+		t.Errorf("FindDuplicateSANs() = %+v, want none", got)
+	}
+}
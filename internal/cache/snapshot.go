@@ -0,0 +1,33 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Snapshot writes all cached entries to w as JSON
+func (c *IngressCache) Snapshot(w io.Writer) error {
+	if err := json.NewEncoder(w).Encode(c.GetAllShared()); err != nil {
+		return fmt.Errorf("failed to encode cache snapshot: %w", err)
+	}
+	return nil
+}
+
+// Restore loads entries from a snapshot written by Snapshot. Restored entries
+// are added as if they had just been reconciled, so later reconciles replace
+// them. The cache is left untouched when the snapshot cannot be decoded.
+func (c *IngressCache) Restore(r io.Reader) error {
+	var entries []*IngressInfo
+	if err := json.NewDecoder(r).Decode(&entries); err != nil {
+		return fmt.Errorf("failed to decode cache snapshot: %w", err)
+	}
+
+	for _, info := range entries {
+		if info == nil || info.Namespace == "" || info.Name == "" {
+			continue
+		}
+		c.Add(info)
+	}
+	return nil
+}
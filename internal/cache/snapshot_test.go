@@ -0,0 +1,73 @@
+package cache
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestIngressCache_SnapshotRestore(t *testing.T) {
+	expires := time.Date(2025, 11, 21, 9, 5, 23, 0, time.UTC)
+	source := NewIngressCache("test-cluster")
+	source.Add(&IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []HostInfo{
+			{Host: "webapp.local", Certificate: &CertificateInfo{Name: "webapp-tls", Expires: &expires}},
+		},
+	})
+	source.Add(&IngressInfo{
+		Kind:      KindGateway,
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts:     []HostInfo{{Host: "webapp.local", Listener: "https"}},
+	})
+
+	var buf bytes.Buffer
+	if err := source.Snapshot(&buf); err != nil {
+		t.Fatalf("Snapshot() error = %v", err)
+	}
+
+	restored := NewIngressCache("test-cluster")
+	if err := restored.Restore(&buf); err != nil {
+		t.Fatalf("Restore() error = %v", err)
+	}
+
+	entries := restored.GetAll()
+	if len(entries) != 2 {
+		t.Fatalf("restored %d entries, want 2", len(entries))
+	}
+	ingresses := restored.GetByNamespace("default")
+	var found bool
+	for _, info := range ingresses {
+		if info.Kind == "" {
+			found = true
+			got := info.Hosts[0].Certificate.Expires
+			if got == nil || !got.Equal(expires) {
+				t.Errorf("restored expiry = %v, want %v", got, expires)
+			}
+		}
+	}
+	if !found {
+		t.Error("restored cache is missing the ingress entry")
+	}
+
+	// Reconciles overwrite restored entries
+	restored.Add(&IngressInfo{Namespace: "default", Name: "webapp", Hosts: []HostInfo{{Host: "new.local"}}})
+	if got := len(restored.GetAll()); got != 2 {
+		t.Errorf("entries after overwrite = %d, want 2", got)
+	}
+}
+
+func TestIngressCache_RestoreCorrupt(t *testing.T) {
+	c := NewIngressCache("test-cluster")
+	c.Add(&IngressInfo{Namespace: "default", Name: "webapp"})
+
+	if err := c.Restore(strings.NewReader(`[{"namespace": "default"`)); err == nil {
+		t.Fatal("Restore() expected error for corrupt snapshot")
+	}
+	if got := len(c.GetAll()); got != 1 {
+		t.Errorf("entries after failed restore = %d, want 1", got)
+	}
+}
@@ -0,0 +1,68 @@
+package cache
+
+import (
+	"context"
+	"iter"
+	"slices"
+	"time"
+)
+
+// View is a read-only subset of an IngressCache, e.g. the entries in the
+// scope of a single ClusterObserver. It is evaluated on every call, so it
+// always reflects the current cache contents.
+type View struct {
+	cache *IngressCache
+	keep  func(*IngressInfo) bool
+}
+
+// View returns the entries for which keep returns true
+func (c *IngressCache) View(keep func(*IngressInfo) bool) *View {
+	return &View{cache: c, keep: keep}
+}
+
+// GetAll returns copies of all entries in the view
+func (v *View) GetAll() []*IngressInfo {
+	result, _ := v.GetAllContext(context.Background())
+	return result
+}
+
+// GetAllContext returns copies of all entries in the view, or the context's
+// error once ctx is done
+func (v *View) GetAllContext(ctx context.Context) ([]*IngressInfo, error) {
+	result, err := v.cache.GetAllContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return slices.DeleteFunc(result, func(info *IngressInfo) bool {
+		return !v.keep(info)
+	}), nil
+}
+
+// PlaintextHostCount returns the number of hosts in the view served without TLS
+func (v *View) PlaintextHostCount() int {
+	return plaintextHostCount(v.shared())
+}
+
+// UniqueCertificateCount returns the number of distinct certificates in the view
+func (v *View) UniqueCertificateCount() int {
+	return uniqueCertificateCount(v.shared())
+}
+
+// shared yields the entries in the view from the cache's shared copies,
+// for read-only callers
+func (v *View) shared() iter.Seq[*IngressInfo] {
+	return func(yield func(*IngressInfo) bool) {
+		for _, info := range v.cache.GetAllShared() {
+			if v.keep(info) && !yield(info) {
+				return
+			}
+		}
+	}
+}
+
+// DeletedSince returns the tombstones in the view deleted after since
+func (v *View) DeletedSince(since, now time.Time) []*IngressInfo {
+	return slices.DeleteFunc(v.cache.DeletedSince(since, now), func(info *IngressInfo) bool {
+		return !v.keep(info)
+	})
+}
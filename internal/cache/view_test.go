@@ -0,0 +1,39 @@
+package cache
+
+import "testing"
+
+func TestIngressCache_View(t *testing.T) {
+	c := NewIngressCache("test-cluster")
+	c.Add(&IngressInfo{
+		Namespace: "team-a",
+		Name:      "shop",
+		Hosts: []HostInfo{
+			{Host: "shop.local", Certificate: &CertificateInfo{Name: "shop-tls"}},
+			{Host: "plain.local"},
+		},
+	})
+	c.Add(&IngressInfo{
+		Namespace: "team-b",
+		Name:      "api",
+		Hosts:     []HostInfo{{Host: "api.local", Certificate: &CertificateInfo{Name: "api-tls"}}},
+	})
+
+	view := c.View(func(info *IngressInfo) bool { return info.Namespace == "team-a" })
+
+	all := view.GetAll()
+	if len(all) != 1 || all[0].Name != "shop" {
+		t.Fatalf("GetAll() = %v, want only team-a/shop", all)
+	}
+	if got := view.PlaintextHostCount(); got != 1 {
+		t.Errorf("PlaintextHostCount() = %d, want 1", got)
+	}
+	if got := view.UniqueCertificateCount(); got != 1 {
+		t.Errorf("UniqueCertificateCount() = %d, want 1", got)
+	}
+
+	// Views reflect later changes to the cache
+	c.Add(&IngressInfo{Namespace: "team-a", Name: "blog"})
+	if got := len(view.GetAll()); got != 2 {
+		t.Errorf("GetAll() after Add returned %d entries, want 2", got)
+	}
+}
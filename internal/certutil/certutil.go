@@ -0,0 +1,342 @@
+package certutil
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"software.sslmate.com/src/go-pkcs12"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+// ErrNoCertificate matches, with errors.Is, the error for secrets without
+// any of the certificate keys, as opposed to secrets whose certificate is
+// malformed
+var ErrNoCertificate = errors.New("secret does not contain a certificate")
+
+// noCertificateError tells which certificate keys a secret lacks
+type noCertificateError string
+
+func (e noCertificateError) Error() string {
+	return string(e)
+}
+
+// Is reports whether target is ErrNoCertificate
+func (e noCertificateError) Is(target error) bool {
+	return target == ErrNoCertificate
+}
+
+// DefaultCertificateKeys are the secret keys the certificate is read from
+// when none are configured
+var DefaultCertificateKeys = []string{corev1.TLSCertKey}
+
+// ParseTLSSecret extracts certificate information from a TLS Secret.
+// The returned CertificateInfo is never nil; when the certificate can't be
+// parsed it carries only the secret name and the error explains why.
+func ParseTLSSecret(secret *corev1.Secret) (*cache.CertificateInfo, error) {
+	return ParseSecret(secret, DefaultCertificateKeys)
+}
+
+// ParseSecret extracts certificate information from the first of the given
+// keys present in the Secret, or from tls.crt when keys is empty. Like
+// ParseTLSSecret, the returned CertificateInfo is never nil.
+func ParseSecret(secret *corev1.Secret, keys []string) (*cache.CertificateInfo, error) {
+	info := &cache.CertificateInfo{
+		Name:            secret.Name,
+		SecretNamespace: secret.Namespace,
+	}
+	if len(keys) == 0 {
+		keys = DefaultCertificateKeys
+	}
+
+	for _, key := range keys {
+		if certData, ok := secret.Data[key]; ok {
+			return info, parseCertificate(info, certData)
+		}
+	}
+	return info, noCertificateError("secret does not contain " + strings.Join(keys, " or "))
+}
+
+// ParsePEM extracts certificate information from PEM data held outside a
+// Secret, such as the inline certificate of an OpenShift Route, naming it
+// name. Like ParseSecret, the returned CertificateInfo is never nil, and
+// empty data matches ErrNoCertificate.
+func ParsePEM(name string, data []byte) (*cache.CertificateInfo, error) {
+	info := &cache.CertificateInfo{Name: name}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return info, noCertificateError("no certificate given")
+	}
+	return info, parseCertificate(info, data)
+}
+
+// ParseOpaqueSecret extracts certificate information from a Secret of any
+// type, such as an Opaque secret holding a PEM certificate under cert.pem.
+// The given keys are used when present, as in ParseSecret, otherwise the
+// first other key holding a PEM certificate in key order. CA bundles under
+// ca.crt are skipped.
+func ParseOpaqueSecret(secret *corev1.Secret, keys []string) (*cache.CertificateInfo, error) {
+	if len(keys) == 0 {
+		keys = DefaultCertificateKeys
+	}
+	if hasAnyKey(secret, keys) {
+		return ParseSecret(secret, keys)
+	}
+
+	info := &cache.CertificateInfo{
+		Name:            secret.Name,
+		SecretNamespace: secret.Namespace,
+	}
+	for _, key := range slices.Sorted(maps.Keys(secret.Data)) {
+		if key == corev1.ServiceAccountRootCAKey || !isPEMCertificate(secret.Data[key]) {
+			continue
+		}
+		return info, parseCertificate(info, secret.Data[key])
+	}
+	return info, noCertificateError("secret does not contain a PEM certificate")
+}
+
+// ParseCACertificates extracts the details of each certificate in the CA
+// bundle under ca.crt, such as the trust bundle of an ingress using client
+// certificate authentication. Each is named by its subject. It returns nil
+// when the Secret has no ca.crt.
+func ParseCACertificates(secret *corev1.Secret) ([]cache.CertificateInfo, error) {
+	data, ok := secret.Data[corev1.ServiceAccountRootCAKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var certs []cache.CertificateInfo
+	for {
+		var block *pem.Block
+		block, data = firstCertificateBlock(data)
+		if block == nil {
+			break
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse CA certificate %d: %w", len(certs)+1, err)
+		}
+		info := cache.CertificateInfo{
+			Name:  cert.Subject.String(),
+			State: cache.CertificateStateOK,
+		}
+		setCertificateDetails(&info, cert)
+		certs = append(certs, info)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no CERTIFICATE block in %s", corev1.ServiceAccountRootCAKey)
+	}
+	return certs, nil
+}
+
+const (
+	// DefaultPKCS12KeystoreKey is the secret key a PKCS#12 keystore is read
+	// from when none is configured
+	DefaultPKCS12KeystoreKey = "keystore.p12"
+	// DefaultPKCS12PasswordKey is the secret key the keystore password is
+	// read from when none is configured
+	DefaultPKCS12PasswordKey = "keystore.password"
+)
+
+// PKCS12 locates a PKCS#12 keystore and its password in a Secret
+type PKCS12 struct {
+	// KeystoreKey holds the keystore, DefaultPKCS12KeystoreKey when empty
+	KeystoreKey string
+	// PasswordKey holds the keystore password, DefaultPKCS12PasswordKey when
+	// empty. The password is empty when the Secret lacks the key.
+	PasswordKey string
+}
+
+// ParsePKCS12Secret extracts certificate information from a PKCS#12 keystore
+// in the Secret, falling back to ParseSecret with the given keys when the
+// Secret has no keystore. The leaf is the certificate of the keystore's
+// private key. Like ParseSecret, the returned CertificateInfo is never nil.
+func ParsePKCS12Secret(secret *corev1.Secret, keys []string, p12 PKCS12) (*cache.CertificateInfo, error) {
+	keystore, ok := secret.Data[p12.keystoreKey()]
+	if !ok {
+		return ParseSecret(secret, keys)
+	}
+
+	info := &cache.CertificateInfo{
+		Name:            secret.Name,
+		SecretNamespace: secret.Namespace,
+	}
+	passwordKey := p12.PasswordKey
+	if passwordKey == "" {
+		passwordKey = DefaultPKCS12PasswordKey
+	}
+	password := strings.TrimRight(string(secret.Data[passwordKey]), "\r\n")
+
+	_, leaf, caCerts, err := pkcs12.DecodeChain(keystore, password)
+	if err != nil {
+		return info, fmt.Errorf("failed to decode PKCS#12 keystore: %w", err)
+	}
+	info.ChainLength = 1 + len(caCerts)
+	setCertificateDetails(info, leaf)
+	return info, nil
+}
+
+// HasKeystore reports whether the Secret holds a PKCS#12 keystore
+func (p PKCS12) HasKeystore(secret *corev1.Secret) bool {
+	_, ok := secret.Data[p.keystoreKey()]
+	return ok
+}
+
+// keystoreKey returns the secret key the keystore is read from
+func (p PKCS12) keystoreKey() string {
+	if p.KeystoreKey == "" {
+		return DefaultPKCS12KeystoreKey
+	}
+	return p.KeystoreKey
+}
+
+// hasAnyKey reports whether the Secret has data under any of the keys
+func hasAnyKey(secret *corev1.Secret, keys []string) bool {
+	for _, key := range keys {
+		if _, ok := secret.Data[key]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCertificate fills in the certificate details from PEM data
+func parseCertificate(info *cache.CertificateInfo, data []byte) error {
+	cert, chainLength, err := parseCertificateChain(data)
+	if err != nil {
+		return err
+	}
+	info.ChainLength = chainLength
+	if cert == nil {
+		// Only intermediates, don't report a CA expiry as the serving cert's
+		info.NoLeafCertificate = true
+		return nil
+	}
+	setCertificateDetails(info, cert)
+	return nil
+}
+
+// setCertificateDetails fills in the details of the leaf certificate
+func setCertificateDetails(info *cache.CertificateInfo, cert *x509.Certificate) {
+	info.Expires = &cert.NotAfter
+	info.Issuer = cert.Issuer.String()
+	info.DNSNames = cert.DNSNames
+	for _, ip := range cert.IPAddresses {
+		info.IPAddresses = append(info.IPAddresses, ip.String())
+	}
+	info.HasIPSAN = len(info.IPAddresses) > 0
+	info.Fingerprint = fingerprint(cert)
+	info.SerialNumber = cert.SerialNumber.Text(16)
+	info.AuthorityKeyID = hex.EncodeToString(cert.AuthorityKeyId)
+	info.SubjectKeyID = hex.EncodeToString(cert.SubjectKeyId)
+	info.KeyAlgorithm, info.KeyBits = keyType(cert)
+	info.Version = cert.Version
+	info.PreV3 = cert.Version < 3
+}
+
+// keyType returns the public key algorithm of the certificate and the key
+// size in bits, i.e. the modulus size for RSA and the curve size for ECDSA.
+// The size is 0 for other algorithms and both are empty for unknown ones.
+func keyType(cert *x509.Certificate) (string, int) {
+	switch key := cert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return cert.PublicKeyAlgorithm.String(), key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return cert.PublicKeyAlgorithm.String(), key.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		return cert.PublicKeyAlgorithm.String(), ed25519.PublicKeySize * 8
+	}
+	if cert.PublicKeyAlgorithm == x509.UnknownPublicKeyAlgorithm {
+		return "", 0
+	}
+	return cert.PublicKeyAlgorithm.String(), 0
+}
+
+// isPEMCertificate reports whether the PEM data holds a certificate
+func isPEMCertificate(data []byte) bool {
+	block, _ := firstCertificateBlock(data)
+	return block != nil
+}
+
+// firstCertificateBlock returns the first CERTIFICATE block in the PEM data
+// and the data after it, skipping other blocks such as a private key that
+// bad tooling put before the certificate. The block is nil if there is none.
+func firstCertificateBlock(data []byte) (*pem.Block, []byte) {
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil || block.Type == "CERTIFICATE" {
+			return block, data
+		}
+	}
+}
+
+// parseCertificateChain returns the leaf certificate and the number of
+// certificates in the PEM data. Blocks other than certificates are skipped
+// wherever they are, e.g. a private key before the chain.
+// The leaf is the first non-CA certificate; a self-signed first certificate
+// is also accepted since it is commonly served directly. When the data holds
+// only intermediates the returned certificate is nil.
+func parseCertificateChain(data []byte) (*x509.Certificate, int, error) {
+	block, rest := firstCertificateBlock(data)
+	if block == nil {
+		if first, _ := pem.Decode(data); first != nil {
+			return nil, 0, fmt.Errorf("no CERTIFICATE block in PEM data")
+		}
+		return nil, 0, fmt.Errorf("failed to decode PEM block")
+	}
+
+	// Parse certificate
+	first, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+
+	var leaf *x509.Certificate
+	if !first.IsCA {
+		leaf = first
+	}
+
+	chainLength := 1
+	for {
+		block, rest = firstCertificateBlock(rest)
+		if block == nil {
+			break
+		}
+		chainLength++
+		if leaf != nil {
+			continue
+		}
+		if cert, err := x509.ParseCertificate(block.Bytes); err == nil && !cert.IsCA {
+			leaf = cert
+		}
+	}
+
+	if leaf == nil && isSelfSigned(first) {
+		leaf = first
+	}
+	return leaf, chainLength, nil
+}
+
+// isSelfSigned reports whether the certificate was issued by itself
+func isSelfSigned(cert *x509.Certificate) bool {
+	return bytes.Equal(cert.RawIssuer, cert.RawSubject) && cert.CheckSignatureFrom(cert) == nil
+}
+
+// fingerprint returns the hex-encoded SHA-256 fingerprint of the certificate
+func fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
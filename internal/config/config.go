@@ -1,23 +1,236 @@
 package config
 
 import (
+	"crypto/tls"
 	"fmt"
+	"maps"
+	"net/url"
 	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
+	// Embedded so BUSINESS_HOURS_TIMEZONE works in images without zoneinfo
+	_ "time/tzdata"
+
+	uberzap "go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/net/http/httpguts"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/certutil"
+)
+
+// Default expiry thresholds used when none are configured
+const (
+	DefaultWarningThreshold  = 30 * 24 * time.Hour
+	DefaultCriticalThreshold = 7 * 24 * time.Hour
+)
+
+// DefaultMinRenewalLeadTime is the validity a certificate should have left
+// when it is renewed, used when none is configured
+const DefaultMinRenewalLeadTime = 15 * 24 * time.Hour
+
+// Report sinks selected by REPORT_SINK
+const (
+	SinkHTTP = "http"
+	SinkFile = "file"
+	SinkOTLP = "otlp"
+)
+
+// Expiry precisions selected by REPORT_EXPIRY_PRECISION
+const (
+	ExpiryPrecisionExact = "exact"
+	ExpiryPrecisionDay   = "day"
+	ExpiryPrecisionHour  = "hour"
+)
+
+// Certificate modes selected by REPORT_CERTIFICATE_MODE
+const (
+	// CertificateModeEmbedded embeds each certificate in every host it serves
+	CertificateModeEmbedded = "embedded"
+	// CertificateModeNormalized lists each certificate once, referenced by
+	// the hosts it serves
+	CertificateModeNormalized = "normalized"
+)
+
+// Log formats selected by LOG_FORMAT
+const (
+	LogFormatConsole = "console"
+	LogFormatJSON    = "json"
+)
+
+// DefaultOTLPEndpoint is the OTLP/HTTP metrics endpoint of a local collector
+const DefaultOTLPEndpoint = "http://localhost:4318/v1/metrics"
+
+// Default file sink rotation settings
+const (
+	DefaultReportFileMaxSize    = 10 * 1024 * 1024
+	DefaultReportFileMaxBackups = 3
 )
 
+// DefaultReportShutdownTimeout bounds the final report sent on shutdown, well
+// within the default termination grace period of 30s
+const DefaultReportShutdownTimeout = 5 * time.Second
+
+// DefaultReportInitialJitter is the fraction of the report interval the first
+// report is delayed by at most, used when REPORT_INITIAL_JITTER is not set
+const DefaultReportInitialJitter = 0.1
+
 // Config holds the application configuration
 type Config struct {
-	ClusterName    string
-	ReportEndpoint string
-	ReportInterval time.Duration
+	// Source is the ClusterObserver the configuration was loaded from; empty
+	// when loaded from the environment
+	Source            types.NamespacedName
+	ClusterName       string
+	ReportEndpoint    string
+	ReportInterval    time.Duration
+	WatchNamespaces   []string
+	ExcludeNamespaces []string
+	IngressSelector   labels.Selector
+	WarningThreshold  time.Duration
+	CriticalThreshold time.Duration
+	// MinRenewalLeadTime is the renewal SLA; renewals with less validity
+	// left on the previous certificate are reported as late
+	MinRenewalLeadTime time.Duration
+	// ReportLabels are attached to every report and to the cluster info
+	// metric, e.g. region and environment; nil when none are configured
+	ReportLabels map[string]string
+	// DeadLetterQueuePath enables persisting failed reports when set
+	DeadLetterQueuePath string
+	DeadLetterQueueSize int
+	// DeadLetterQueueMaxAge drops queued reports older than it; 0 keeps
+	// them until the queue is full
+	DeadLetterQueueMaxAge time.Duration
+	// ReportMinTLSVersion is the lowest TLS version the reporter negotiates
+	ReportMinTLSVersion uint16
+	// ReportInsecureSkipVerify disables verification of the collector's
+	// certificate, for bootstrapping against self-signed collectors only;
+	// false by default
+	ReportInsecureSkipVerify bool
+	// ReportProxyURL is the proxy reports are sent through; when nil,
+	// HTTP_PROXY, HTTPS_PROXY and NO_PROXY apply
+	ReportProxyURL *url.URL
+	// ReportSuccessStatusCodes are the collector responses that count as a
+	// delivered report; any other status is retried
+	ReportSuccessStatusCodes StatusCodeSet
+	// ReportAcceptance, when set, also requires the collector's response
+	// body to confirm each delivery
+	ReportAcceptance *ResponseAcceptance
+	// ReportBatchSize is the most ingresses sent per request; larger reports
+	// are split into batches. 0 sends every report in one request.
+	ReportBatchSize int
+	// ReportShutdownTimeout bounds the final report sent when the reporter
+	// stops; 0 sends none
+	ReportShutdownTimeout time.Duration
+	// ReportInitialJitter is the fraction of ReportInterval the first report
+	// is delayed by at most, so a fleet started at once spreads its reports;
+	// 0 sends it right away
+	ReportInitialJitter float64
+	// ReportSigningSecret is the shared secret reports are signed with using
+	// HMAC-SHA256; reports are unsigned when it is empty
+	ReportSigningSecret string
+	// ReportUserAgent replaces the User-Agent of report requests, which
+	// names the operator version and the cluster when empty
+	ReportUserAgent string
+	// DryRun logs reports instead of sending them to ReportEndpoint
+	DryRun bool
+	// ReportDaysUntilExpiry adds each certificate's whole days until expiry,
+	// relative to the report's generation time, to reports
+	ReportDaysUntilExpiry bool
+	// ReportExpiryPrecision is the unit reported expiry times are truncated
+	// to; 0 reports them exactly
+	ReportExpiryPrecision time.Duration
+	// ReportCertificateMode is CertificateModeEmbedded (the default) or
+	// CertificateModeNormalized
+	ReportCertificateMode string
+	// ReportSink is where reports are delivered, SinkHTTP, SinkFile or SinkOTLP
+	ReportSink string
+	// ReportFilePath is the NDJSON file written by the file sink, rotated
+	// once it would grow past ReportFileMaxSize bytes
+	ReportFilePath       string
+	ReportFileMaxSize    int64
+	ReportFileMaxBackups int
+	// ReportOTLPEndpoint is the OTLP/HTTP metrics endpoint the OTLP sink
+	// exports certificate expiry to, with ReportOTLPHeaders on each request
+	ReportOTLPEndpoint string
+	ReportOTLPHeaders  map[string]string
+	// BusinessHours is used to flag certificates expiring when nobody is
+	// around to renew them
+	BusinessHours BusinessHours
+}
+
+// ResponseAcceptance describes how a collector confirms a delivery in its
+// JSON response body. Field and ReceiptIDField are paths of object keys into
+// the body, e.g. ["result", "accepted"].
+type ResponseAcceptance struct {
+	// Field must hold Value for the report to count as delivered; any
+	// response is accepted when it is empty
+	Field []string
+	Value string
+	// ReceiptIDField is where the collector returns the delivery's id, if any
+	ReceiptIDField []string
+}
+
+// BusinessHours is the daily working time, in a time zone, of the teams
+// renewing certificates. Saturdays and Sundays are not working days.
+type BusinessHours struct {
+	Location *time.Location
+	// Start and End are offsets from midnight; End is exclusive
+	Start time.Duration
+	End   time.Duration
+}
+
+// IsWeekend reports whether t falls on a Saturday or Sunday in the time zone
+func (b BusinessHours) IsWeekend(t time.Time) bool {
+	day := t.In(b.Location).Weekday()
+	return day == time.Saturday || day == time.Sunday
+}
+
+// IsAfterHours reports whether the time of day of t in the time zone is
+// outside business hours, on any day of the week
+func (b BusinessHours) IsAfterHours(t time.Time) bool {
+	local := t.In(b.Location)
+	sinceMidnight := time.Duration(local.Hour())*time.Hour + time.Duration(local.Minute())*time.Minute +
+		time.Duration(local.Second())*time.Second
+	return sinceMidnight < b.Start || sinceMidnight >= b.End
+}
+
+// StatusCodeSet is a set of HTTP status codes
+type StatusCodeSet map[int]bool
+
+// Contains reports whether the status code is in the set. A nil set
+// contains every 2xx status.
+func (s StatusCodeSet) Contains(code int) bool {
+	if s == nil {
+		return code >= 200 && code < 300
+	}
+	return s[code]
+}
+
+// tlsVersions maps accepted REPORT_MIN_TLS_VERSION values to TLS versions
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10,
+	"1.1": tls.VersionTLS11,
+	"1.2": tls.VersionTLS12,
+	"1.3": tls.VersionTLS13,
 }
 
 // Load loads configuration from environment variables
 func Load() (*Config, error) {
 	cfg := &Config{
-		ClusterName:    getEnv("CLUSTER_NAME", "local-cluster"),
-		ReportEndpoint: getEnv("REPORT_ENDPOINT", "http://localhost:8080/report"),
+		ClusterName:       getEnv("CLUSTER_NAME", "local-cluster"),
+		ReportEndpoint:    getEnv("REPORT_ENDPOINT", "http://localhost:8080/report"),
+		WatchNamespaces:   getEnvList("WATCH_NAMESPACES"),
+		ExcludeNamespaces: getEnvList("EXCLUDE_NAMESPACES"),
+	}
+
+	if err := ValidateEndpoint(cfg.ReportEndpoint); err != nil {
+		return nil, fmt.Errorf("invalid REPORT_ENDPOINT: %w", err)
 	}
 
 	// Parse report interval
@@ -26,11 +239,705 @@ func Load() (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("invalid REPORT_INTERVAL: %w", err)
 	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid REPORT_INTERVAL: must be positive, got %s", interval)
+	}
 	cfg.ReportInterval = interval
 
+	// Parse ingress label selector
+	selector, err := labels.Parse(getEnv("INGRESS_SELECTOR", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid INGRESS_SELECTOR: %w", err)
+	}
+	cfg.IngressSelector = selector
+
+	// Parse expiry thresholds
+	cfg.WarningThreshold, cfg.CriticalThreshold, err = parseThresholds(
+		getEnv("WARNING_THRESHOLD", ""), getEnv("CRITICAL_THRESHOLD", ""))
+	if err != nil {
+		return nil, err
+	}
+	cfg.MinRenewalLeadTime, err = parseMinRenewalLeadTime(getEnv("MIN_RENEWAL_LEAD_TIME", ""))
+	if err != nil {
+		return nil, err
+	}
+	cfg.ReportLabels, err = ParseReportLabels(getEnv("REPORT_LABELS", ""))
+	if err != nil {
+		return nil, fmt.Errorf("invalid REPORT_LABELS: %w", err)
+	}
+
+	if err := loadDeadLetterQueue(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportTLS(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportProxy(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportSuccessStatusCodes(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportAcceptance(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportBatchSize(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportShutdownTimeout(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportInitialJitter(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadDryRun(cfg); err != nil {
+		return nil, err
+	}
+	loadReportSigning(cfg)
+	if err := loadReportUserAgent(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportDaysUntilExpiry(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportExpiryPrecision(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportCertificateMode(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportSink(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadBusinessHours(cfg); err != nil {
+		return nil, err
+	}
+
 	return cfg, nil
 }
 
+// ValidateEndpoint checks that the report endpoint is an absolute http or
+// https URL with a host
+func ValidateEndpoint(endpoint string) error {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("%q must use the http or https scheme", endpoint)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("%q has no host", endpoint)
+	}
+	return nil
+}
+
+// loadDeadLetterQueue reads the dead-letter queue settings from environment
+// variables. They describe local storage of the pod, so they are read from the
+// environment even when the rest of the configuration comes from the CRD.
+func loadDeadLetterQueue(cfg *Config) error {
+	cfg.DeadLetterQueuePath = getEnv("REPORT_DLQ_PATH", "")
+
+	size, err := strconv.Atoi(getEnv("REPORT_DLQ_MAX_SIZE", "100"))
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_DLQ_MAX_SIZE: %w", err)
+	}
+	if size <= 0 {
+		return fmt.Errorf("invalid REPORT_DLQ_MAX_SIZE: must be positive, got %d", size)
+	}
+	cfg.DeadLetterQueueSize = size
+
+	maxAge, err := time.ParseDuration(getEnv("REPORT_DLQ_MAX_AGE", "0s"))
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_DLQ_MAX_AGE: %w", err)
+	}
+	if maxAge < 0 {
+		return fmt.Errorf("invalid REPORT_DLQ_MAX_AGE: must not be negative, got %s", maxAge)
+	}
+	cfg.DeadLetterQueueMaxAge = maxAge
+
+	return nil
+}
+
+// loadReportTLS reads the reporter TLS settings from environment variables.
+// Like the dead-letter queue, they are pod-local and read even when the rest
+// of the configuration comes from the CRD.
+func loadReportTLS(cfg *Config) error {
+	value := getEnv("REPORT_MIN_TLS_VERSION", "1.2")
+	version, ok := tlsVersions[value]
+	if !ok {
+		return fmt.Errorf("invalid REPORT_MIN_TLS_VERSION %q: must be one of 1.0, 1.1, 1.2, 1.3", value)
+	}
+	cfg.ReportMinTLSVersion = version
+
+	insecure, err := strconv.ParseBool(getEnv("REPORT_INSECURE_SKIP_VERIFY", "false"))
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_INSECURE_SKIP_VERIFY: %w", err)
+	}
+	cfg.ReportInsecureSkipVerify = insecure
+	return nil
+}
+
+// loadReportProxy reads REPORT_PROXY_URL. Egress is a property of the pod's
+// network, so like the TLS settings it is read even when the rest of the
+// configuration comes from the CRD.
+func loadReportProxy(cfg *Config) error {
+	value := getEnv("REPORT_PROXY_URL", "")
+	if value == "" {
+		return nil
+	}
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_PROXY_URL: %w", err)
+	}
+	if proxyURL.Scheme != "http" && proxyURL.Scheme != "https" || proxyURL.Host == "" {
+		return fmt.Errorf("invalid REPORT_PROXY_URL %q: must be an http or https URL with a host", value)
+	}
+	cfg.ReportProxyURL = proxyURL
+	return nil
+}
+
+// loadReportSuccessStatusCodes reads the statuses that count as a delivered
+// report from a comma-separated list of codes (e.g. "302") and classes
+// (e.g. "2xx"). Like the TLS settings, it is read from the environment even
+// when the rest of the configuration comes from the CRD.
+func loadReportSuccessStatusCodes(cfg *Config) error {
+	entries := getEnvList("REPORT_SUCCESS_STATUS_CODES")
+	if len(entries) == 0 {
+		entries = []string{"2xx"}
+	}
+
+	codes := make(StatusCodeSet)
+	for _, entry := range entries {
+		if class, ok := strings.CutSuffix(strings.ToLower(entry), "xx"); ok {
+			digit, err := strconv.Atoi(class)
+			if err != nil || digit < 1 || digit > 5 {
+				return fmt.Errorf("invalid REPORT_SUCCESS_STATUS_CODES entry %q: classes must be 1xx to 5xx", entry)
+			}
+			for code := digit * 100; code < (digit+1)*100; code++ {
+				codes[code] = true
+			}
+			continue
+		}
+		code, err := strconv.Atoi(entry)
+		if err != nil || code < 100 || code > 599 {
+			return fmt.Errorf("invalid REPORT_SUCCESS_STATUS_CODES entry %q: codes must be between 100 and 599", entry)
+		}
+		codes[code] = true
+	}
+	cfg.ReportSuccessStatusCodes = codes
+	return nil
+}
+
+// loadReportAcceptance reads the response body check from
+// REPORT_ACCEPTANCE_FIELD, REPORT_ACCEPTANCE_VALUE (default "true") and
+// REPORT_RECEIPT_ID_FIELD. Fields are JSONPath-style key paths such as
+// "$.result.accepted" or "accepted". Like the status codes, they are also
+// read when the rest of the configuration comes from the CRD.
+func loadReportAcceptance(cfg *Config) error {
+	field, err := parseFieldPath("REPORT_ACCEPTANCE_FIELD")
+	if err != nil {
+		return err
+	}
+	receiptIDField, err := parseFieldPath("REPORT_RECEIPT_ID_FIELD")
+	if err != nil {
+		return err
+	}
+	if field == nil && receiptIDField == nil {
+		cfg.ReportAcceptance = nil
+		return nil
+	}
+	cfg.ReportAcceptance = &ResponseAcceptance{
+		Field:          field,
+		Value:          getEnv("REPORT_ACCEPTANCE_VALUE", "true"),
+		ReceiptIDField: receiptIDField,
+	}
+	return nil
+}
+
+// parseFieldPath splits the JSONPath-style key path in the environment
+// variable into its keys, or returns nil when it is unset
+func parseFieldPath(key string) ([]string, error) {
+	value := strings.TrimSpace(os.Getenv(key))
+	if value == "" {
+		return nil, nil
+	}
+	path := strings.TrimPrefix(strings.TrimPrefix(value, "$"), ".")
+	keys := strings.Split(path, ".")
+	if slices.Contains(keys, "") {
+		return nil, fmt.Errorf("invalid %s %q: must be a dot-separated path of keys, e.g. $.result.accepted", key, value)
+	}
+	return keys, nil
+}
+
+// loadReportBatchSize reads REPORT_BATCH_SIZE. Like the status codes, it
+// depends on the collector's request size limit and is also read when the
+// rest of the configuration comes from the CRD.
+func loadReportBatchSize(cfg *Config) error {
+	size, err := strconv.Atoi(getEnv("REPORT_BATCH_SIZE", "0"))
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_BATCH_SIZE: %w", err)
+	}
+	if size < 0 {
+		return fmt.Errorf("invalid REPORT_BATCH_SIZE: must not be negative, got %d", size)
+	}
+	cfg.ReportBatchSize = size
+	return nil
+}
+
+// loadReportShutdownTimeout reads REPORT_SHUTDOWN_TIMEOUT. It must fit into
+// the pod's termination grace period, so like the dry-run setting it is also
+// read when the rest of the configuration comes from the CRD.
+func loadReportShutdownTimeout(cfg *Config) error {
+	timeout, err := time.ParseDuration(getEnv("REPORT_SHUTDOWN_TIMEOUT", DefaultReportShutdownTimeout.String()))
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_SHUTDOWN_TIMEOUT: %w", err)
+	}
+	if timeout < 0 {
+		return fmt.Errorf("invalid REPORT_SHUTDOWN_TIMEOUT: must not be negative, got %s", timeout)
+	}
+	cfg.ReportShutdownTimeout = timeout
+	return nil
+}
+
+// loadReportInitialJitter reads REPORT_INITIAL_JITTER, a fraction of the
+// report interval between 0 and 1. Like the shutdown timeout, it is also
+// read when the rest of the configuration comes from the CRD.
+func loadReportInitialJitter(cfg *Config) error {
+	value := getEnv("REPORT_INITIAL_JITTER", strconv.FormatFloat(DefaultReportInitialJitter, 'f', -1, 64))
+	jitter, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_INITIAL_JITTER: %w", err)
+	}
+	if jitter < 0 || jitter > 1 {
+		return fmt.Errorf("invalid REPORT_INITIAL_JITTER: must be between 0 and 1, got %s", value)
+	}
+	cfg.ReportInitialJitter = jitter
+	return nil
+}
+
+// loadReportSigning reads the report signing secret from
+// REPORT_SIGNING_SECRET. Secrets don't belong in the CRD, so it is also read
+// when the rest of the configuration comes from there.
+func loadReportSigning(cfg *Config) {
+	cfg.ReportSigningSecret = getEnv("REPORT_SIGNING_SECRET", "")
+}
+
+// loadReportUserAgent reads REPORT_USER_AGENT, rejecting values that can't
+// be sent as a header. Like the proxy, it is a setting of the pod, so it is
+// also read when the rest of the configuration comes from the CRD.
+func loadReportUserAgent(cfg *Config) error {
+	cfg.ReportUserAgent = getEnv("REPORT_USER_AGENT", "")
+	if !httpguts.ValidHeaderFieldValue(cfg.ReportUserAgent) {
+		return fmt.Errorf("invalid REPORT_USER_AGENT %q: not a valid header value", cfg.ReportUserAgent)
+	}
+	return nil
+}
+
+// loadDryRun reads REPORT_DRY_RUN. Dry-run is a rollout setting of the pod
+// rather than of the observer, so it is also read when the rest of the
+// configuration comes from the CRD.
+func loadDryRun(cfg *Config) error {
+	dryRun, err := strconv.ParseBool(getEnv("REPORT_DRY_RUN", "false"))
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_DRY_RUN: %w", err)
+	}
+	cfg.DryRun = dryRun
+	return nil
+}
+
+// loadReportDaysUntilExpiry reads REPORT_DAYS_UNTIL_EXPIRY. Like the dry-run
+// setting, it is also read when the rest of the configuration comes from the
+// CRD.
+func loadReportDaysUntilExpiry(cfg *Config) error {
+	enabled, err := strconv.ParseBool(getEnv("REPORT_DAYS_UNTIL_EXPIRY", "false"))
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_DAYS_UNTIL_EXPIRY: %w", err)
+	}
+	cfg.ReportDaysUntilExpiry = enabled
+	return nil
+}
+
+// loadReportCertificateMode reads REPORT_CERTIFICATE_MODE, embedded or
+// normalized. Like the dry-run setting, it is also read when the rest of the
+// configuration comes from the CRD.
+func loadReportCertificateMode(cfg *Config) error {
+	value := strings.ToLower(getEnv("REPORT_CERTIFICATE_MODE", CertificateModeEmbedded))
+	switch value {
+	case CertificateModeEmbedded, CertificateModeNormalized:
+		cfg.ReportCertificateMode = value
+	default:
+		return fmt.Errorf("invalid REPORT_CERTIFICATE_MODE %q: must be %s or %s",
+			value, CertificateModeEmbedded, CertificateModeNormalized)
+	}
+	return nil
+}
+
+// loadReportExpiryPrecision reads REPORT_EXPIRY_PRECISION, one of exact, day
+// or hour. Like the dry-run setting, it is also read when the rest of the
+// configuration comes from the CRD.
+func loadReportExpiryPrecision(cfg *Config) error {
+	value := strings.ToLower(getEnv("REPORT_EXPIRY_PRECISION", ExpiryPrecisionExact))
+	switch value {
+	case ExpiryPrecisionExact:
+		cfg.ReportExpiryPrecision = 0
+	case ExpiryPrecisionDay:
+		cfg.ReportExpiryPrecision = 24 * time.Hour
+	case ExpiryPrecisionHour:
+		cfg.ReportExpiryPrecision = time.Hour
+	default:
+		return fmt.Errorf("invalid REPORT_EXPIRY_PRECISION %q: must be %s, %s or %s",
+			value, ExpiryPrecisionExact, ExpiryPrecisionDay, ExpiryPrecisionHour)
+	}
+	return nil
+}
+
+// loadBusinessHours reads BUSINESS_HOURS, e.g. "09:00-17:00", and the time
+// zone they are in from BUSINESS_HOURS_TIMEZONE. Like the dry-run setting, it
+// is also read when the rest of the configuration comes from the CRD.
+func loadBusinessHours(cfg *Config) error {
+	location, err := time.LoadLocation(getEnv("BUSINESS_HOURS_TIMEZONE", "UTC"))
+	if err != nil {
+		return fmt.Errorf("invalid BUSINESS_HOURS_TIMEZONE: %w", err)
+	}
+
+	value := getEnv("BUSINESS_HOURS", "09:00-17:00")
+	startValue, endValue, ok := strings.Cut(value, "-")
+	if !ok {
+		return fmt.Errorf("invalid BUSINESS_HOURS %q: must be HH:MM-HH:MM", value)
+	}
+	start, err := parseTimeOfDay(startValue)
+	if err != nil {
+		return fmt.Errorf("invalid BUSINESS_HOURS %q: %w", value, err)
+	}
+	end, err := parseTimeOfDay(endValue)
+	if err != nil {
+		return fmt.Errorf("invalid BUSINESS_HOURS %q: %w", value, err)
+	}
+	if end <= start {
+		return fmt.Errorf("invalid BUSINESS_HOURS %q: end must be after start", value)
+	}
+
+	cfg.BusinessHours = BusinessHours{Location: location, Start: start, End: end}
+	return nil
+}
+
+// parseTimeOfDay parses HH:MM into the offset from midnight. 24:00 is
+// accepted as the end of the day.
+func parseTimeOfDay(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "24:00" {
+		return 24 * time.Hour, nil
+	}
+	t, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a time of day", value)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+// loadReportSink reads the report sink settings. The sink is a setting of the
+// pod: it applies to the reporter of the selected ClusterObserver, while the
+// reporters started with --reporter-per-observer always use HTTP.
+func loadReportSink(cfg *Config) error {
+	cfg.ReportSink = strings.ToLower(getEnv("REPORT_SINK", SinkHTTP))
+	switch cfg.ReportSink {
+	case SinkHTTP:
+		return nil
+	case SinkFile:
+		return loadReportFile(cfg)
+	case SinkOTLP:
+		return loadReportOTLP(cfg)
+	default:
+		return fmt.Errorf("invalid REPORT_SINK %q: must be %s, %s or %s", cfg.ReportSink, SinkHTTP, SinkFile, SinkOTLP)
+	}
+}
+
+// loadReportFile reads the file sink settings
+func loadReportFile(cfg *Config) error {
+	cfg.ReportFilePath = getEnv("REPORT_FILE_PATH", "")
+	if cfg.ReportFilePath == "" {
+		return fmt.Errorf("REPORT_FILE_PATH is required when REPORT_SINK is %s", SinkFile)
+	}
+
+	maxSize, err := strconv.ParseInt(getEnv("REPORT_FILE_MAX_SIZE", strconv.Itoa(DefaultReportFileMaxSize)), 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_FILE_MAX_SIZE: %w", err)
+	}
+	if maxSize <= 0 {
+		return fmt.Errorf("invalid REPORT_FILE_MAX_SIZE: must be positive, got %d", maxSize)
+	}
+	cfg.ReportFileMaxSize = maxSize
+
+	maxBackups, err := strconv.Atoi(getEnv("REPORT_FILE_MAX_BACKUPS", strconv.Itoa(DefaultReportFileMaxBackups)))
+	if err != nil {
+		return fmt.Errorf("invalid REPORT_FILE_MAX_BACKUPS: %w", err)
+	}
+	if maxBackups < 0 {
+		return fmt.Errorf("invalid REPORT_FILE_MAX_BACKUPS: must not be negative, got %d", maxBackups)
+	}
+	cfg.ReportFileMaxBackups = maxBackups
+
+	return nil
+}
+
+// loadReportOTLP reads the OTLP sink settings. Headers are a comma-separated
+// list of key=value pairs, e.g. for authentication.
+func loadReportOTLP(cfg *Config) error {
+	cfg.ReportOTLPEndpoint = getEnv("REPORT_OTLP_ENDPOINT", DefaultOTLPEndpoint)
+	if err := ValidateEndpoint(cfg.ReportOTLPEndpoint); err != nil {
+		return fmt.Errorf("invalid REPORT_OTLP_ENDPOINT: %w", err)
+	}
+
+	headers := make(map[string]string)
+	for _, entry := range getEnvList("REPORT_OTLP_HEADERS") {
+		key, value, ok := strings.Cut(entry, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return fmt.Errorf("invalid REPORT_OTLP_HEADERS entry %q: must be key=value", entry)
+		}
+		headers[key] = strings.TrimSpace(value)
+	}
+	cfg.ReportOTLPHeaders = headers
+
+	return nil
+}
+
+// ExpiryThresholds returns the configured certificate expiry thresholds
+func (c *Config) ExpiryThresholds() cache.ExpiryThresholds {
+	return cache.ExpiryThresholds{
+		Warning:  c.WarningThreshold,
+		Critical: c.CriticalThreshold,
+	}
+}
+
+// DefaultExpiryThresholds returns the thresholds used when no configuration is available
+func DefaultExpiryThresholds() cache.ExpiryThresholds {
+	return cache.ExpiryThresholds{
+		Warning:  DefaultWarningThreshold,
+		Critical: DefaultCriticalThreshold,
+	}
+}
+
+// parseThresholds parses the warning and critical thresholds, falling back to
+// the defaults for empty values. The critical threshold may not exceed the
+// warning threshold.
+func parseThresholds(warningStr, criticalStr string) (time.Duration, time.Duration, error) {
+	warning, critical := DefaultWarningThreshold, DefaultCriticalThreshold
+	var err error
+	if warningStr != "" {
+		if warning, err = time.ParseDuration(warningStr); err != nil {
+			return 0, 0, fmt.Errorf("invalid warning threshold: %w", err)
+		}
+	}
+	if criticalStr != "" {
+		if critical, err = time.ParseDuration(criticalStr); err != nil {
+			return 0, 0, fmt.Errorf("invalid critical threshold: %w", err)
+		}
+	}
+	if critical > warning {
+		return 0, 0, fmt.Errorf("critical threshold %s exceeds warning threshold %s", critical, warning)
+	}
+	return warning, critical, nil
+}
+
+// parseMinRenewalLeadTime parses the renewal SLA, falling back to the
+// default for an empty value
+func parseMinRenewalLeadTime(value string) (time.Duration, error) {
+	if value == "" {
+		return DefaultMinRenewalLeadTime, nil
+	}
+	leadTime, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid minimum renewal lead time: %w", err)
+	}
+	if leadTime < 0 {
+		return 0, fmt.Errorf("invalid minimum renewal lead time: must not be negative, got %s", leadTime)
+	}
+	return leadTime, nil
+}
+
+// reportLabelKey matches the report label keys allowed, which double as
+// Prometheus label names
+var reportLabelKey = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+// ParseReportLabels parses comma-separated key=value report labels, e.g.
+// "region=eu,env=prod". Values may be empty and contain "=". Returns nil for
+// an empty string.
+func ParseReportLabels(value string) (map[string]string, error) {
+	var reportLabels map[string]string
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, labelValue, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("entry %q must be key=value", entry)
+		}
+		key = strings.TrimSpace(key)
+		if _, duplicate := reportLabels[key]; duplicate {
+			return nil, fmt.Errorf("duplicate key %q", key)
+		}
+		if reportLabels == nil {
+			reportLabels = make(map[string]string)
+		}
+		reportLabels[key] = strings.TrimSpace(labelValue)
+	}
+	if err := ValidateReportLabels(reportLabels); err != nil {
+		return nil, err
+	}
+	return reportLabels, nil
+}
+
+// ValidateReportLabels checks that every key is a non-empty Prometheus label
+// name. Names starting with "__" are reserved by Prometheus and cluster is
+// already carried by every report.
+func ValidateReportLabels(reportLabels map[string]string) error {
+	for _, key := range slices.Sorted(maps.Keys(reportLabels)) {
+		switch {
+		case key == "":
+			return fmt.Errorf("label keys must not be empty")
+		case !reportLabelKey.MatchString(key):
+			return fmt.Errorf("label key %q must consist of letters, digits and underscores, "+
+				"and not start with a digit", key)
+		case strings.HasPrefix(key, "__"):
+			return fmt.Errorf("label key %q must not start with __", key)
+		case key == "cluster":
+			return fmt.Errorf("label key %q is reserved for the cluster name", key)
+		}
+	}
+	return nil
+}
+
+// LoadSecretNamePattern loads the naming convention for referenced secrets
+// from SECRET_NAME_PATTERN. The pattern must match the whole secret name.
+// Returns nil if it is not set (the check is disabled).
+func LoadSecretNamePattern() (*regexp.Regexp, error) {
+	pattern := getEnv("SECRET_NAME_PATTERN", "")
+	if pattern == "" {
+		return nil, nil
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid SECRET_NAME_PATTERN: %w", err)
+	}
+	return re, nil
+}
+
+// LoadCertificateKeys loads the secret keys the certificate is read from,
+// tried in order, from the comma-separated CERTIFICATE_SECRET_KEYS.
+// Returns nil if it is not set, in which case tls.crt is used.
+func LoadCertificateKeys() []string {
+	return getEnvList("CERTIFICATE_SECRET_KEYS")
+}
+
+// LoadPKCS12 loads where PKCS#12 keystores and their passwords are read from
+// in secrets, PKCS12_KEYSTORE_KEY and PKCS12_PASSWORD_KEY. Unset keys fall
+// back to certutil.DefaultPKCS12KeystoreKey and DefaultPKCS12PasswordKey.
+func LoadPKCS12() certutil.PKCS12 {
+	return certutil.PKCS12{
+		KeystoreKey: getEnv("PKCS12_KEYSTORE_KEY", certutil.DefaultPKCS12KeystoreKey),
+		PasswordKey: getEnv("PKCS12_PASSWORD_KEY", certutil.DefaultPKCS12PasswordKey),
+	}
+}
+
+// LoadLogOptions applies LOG_FORMAT and LOG_LEVEL to the options of the
+// controller-runtime zap logger. LOG_FORMAT is console or json. LOG_LEVEL is
+// debug, info, warn or error, or a positive verbosity as with V(n). Unset
+// variables leave the options as they are, and the --zap-encoder and
+// --zap-log-level flags bound after this still override them.
+func LoadLogOptions(opts *zap.Options) error {
+	switch format := strings.ToLower(getEnv("LOG_FORMAT", "")); format {
+	case "":
+	case LogFormatConsole:
+		zap.ConsoleEncoder()(opts)
+	case LogFormatJSON:
+		zap.JSONEncoder()(opts)
+	default:
+		return fmt.Errorf("invalid LOG_FORMAT %q: must be %s or %s", format, LogFormatConsole, LogFormatJSON)
+	}
+
+	value := getEnv("LOG_LEVEL", "")
+	if value == "" {
+		return nil
+	}
+	if verbosity, err := strconv.Atoi(value); err == nil {
+		if verbosity < 1 {
+			return fmt.Errorf("invalid LOG_LEVEL %d: verbosity must be positive", verbosity)
+		}
+		opts.Level = uberzap.NewAtomicLevelAt(zapcore.Level(-verbosity))
+		return nil
+	}
+	level, err := zapcore.ParseLevel(value)
+	if err != nil || level > zapcore.ErrorLevel {
+		return fmt.Errorf("invalid LOG_LEVEL %q: must be debug, info, warn, error or a positive verbosity", value)
+	}
+	opts.Level = uberzap.NewAtomicLevelAt(level)
+	return nil
+}
+
+// LoadACMEIssuers loads the table of ACME certificate authorities from the
+// comma-separated ACME_ISSUERS, e.g. "O=Let's Encrypt=letsencrypt". Each entry
+// maps an issuer substring to a provider name at its last "=". The table
+// replaces cache.DefaultACMEIssuers, which is returned if it is not set.
+func LoadACMEIssuers() (map[string]string, error) {
+	entries := getEnvList("ACME_ISSUERS")
+	if len(entries) == 0 {
+		return cache.DefaultACMEIssuers, nil
+	}
+
+	issuers := make(map[string]string, len(entries))
+	for _, entry := range entries {
+		i := strings.LastIndex(entry, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid ACME_ISSUERS entry %q: must be issuer=provider", entry)
+		}
+		issuer, provider := strings.TrimSpace(entry[:i]), strings.TrimSpace(entry[i+1:])
+		if issuer == "" || provider == "" {
+			return nil, fmt.Errorf("invalid ACME_ISSUERS entry %q: must be issuer=provider", entry)
+		}
+		issuers[issuer] = provider
+	}
+	return issuers, nil
+}
+
+// Observes reports whether a cache entry is in the scope of the configuration.
+// The selector only applies to ingresses, as when observing them.
+func (c *Config) Observes(info *cache.IngressInfo) bool {
+	if !c.NamespaceFilter().Allows(info.Namespace) {
+		return false
+	}
+	if info.Kind != "" || c.IngressSelector == nil {
+		return true
+	}
+	return c.IngressSelector.Matches(labels.Set(info.Labels))
+}
+
+// NamespaceFilter returns the namespace scope described by the configuration
+func (c *Config) NamespaceFilter() NamespaceFilter {
+	return NamespaceFilter{
+		Include: c.WatchNamespaces,
+		Exclude: c.ExcludeNamespaces,
+	}
+}
+
+// NamespaceFilter decides which namespaces are observed.
+// Exclude takes precedence over Include; an empty Include allows every
+// namespace that isn't excluded. The zero value allows all namespaces.
+type NamespaceFilter struct {
+	Include []string
+	Exclude []string
+}
+
+// Allows reports whether resources in the given namespace should be observed
+func (f NamespaceFilter) Allows(namespace string) bool {
+	if slices.Contains(f.Exclude, namespace) {
+		return false
+	}
+	return len(f.Include) == 0 || slices.Contains(f.Include, namespace)
+}
+
 // getEnv retrieves environment variable with fallback to default value
 func getEnv(key, defaultValue string) string {
 	if value := os.Getenv(key); value != "" {
@@ -38,3 +945,15 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvList retrieves a comma-separated environment variable as a list,
+// dropping empty entries
+func getEnvList(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
@@ -2,39 +2,216 @@ package config
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
 	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	observerv1alpha1 "github.com/ugurcancaykara/cert-observer/api/v1alpha1"
 )
 
-// LoadFromCRD attempts to load configuration from a ClusterObserver CRD
-// Returns nil if no CRD is found (reporter will not start)
-func LoadFromCRD(ctx context.Context, k8sClient client.Client) (*Config, error) {
-	// Try to get ClusterObserver from default namespace
-	observer := &observerv1alpha1.ClusterObserver{}
-	err := k8sClient.Get(ctx, types.NamespacedName{
-		Name:      "clusterobserver-sample",
-		Namespace: "default",
-	}, observer)
+// ErrClusterNameConflict is returned when the ClusterObserver uses the Block
+// conflict policy and an older ClusterObserver already claims its cluster name
+var ErrClusterNameConflict = errors.New("cluster name is already claimed by another ClusterObserver")
 
+// MinReportInterval is the shortest report interval a ClusterObserver may
+// request, so that a typo can't flood the collector
+const MinReportInterval = 5 * time.Second
+
+// ErrObserverNotFound is returned when no ClusterObserver to load the
+// configuration from exists
+var ErrObserverNotFound = errors.New("no ClusterObserver found")
+
+// LoadFromCRD loads configuration from a ClusterObserver. The observer is
+// looked up by key when a name is set; otherwise the single ClusterObserver
+// in key.Namespace (or the whole cluster when empty) is used. It returns
+// ErrObserverNotFound when there is no observer to load from.
+func LoadFromCRD(ctx context.Context, k8sClient client.Reader, key types.NamespacedName) (*Config, error) {
+	observer, err := FindObserver(ctx, k8sClient, key)
 	if err != nil {
-		// Log the error for debugging
-		// CRD not found - return nil (no reporting)
-		return nil, nil
+		return nil, err
+	}
+
+	if observer.Spec.ConflictPolicy == observerv1alpha1.ConflictPolicyBlock {
+		var observers observerv1alpha1.ClusterObserverList
+		if err := k8sClient.List(ctx, &observers); err != nil {
+			return nil, err
+		}
+		if FindClusterNameConflict(observer, observers.Items) != nil {
+			return nil, ErrClusterNameConflict
+		}
+	}
+
+	return FromObserver(observer)
+}
+
+// FindObserver returns the ClusterObserver to load the configuration from.
+// See LoadFromCRD for how it is selected.
+func FindObserver(ctx context.Context, k8sClient client.Reader, key types.NamespacedName) (*observerv1alpha1.ClusterObserver, error) {
+	if key.Name != "" {
+		observer := &observerv1alpha1.ClusterObserver{}
+		if err := k8sClient.Get(ctx, key, observer); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil, fmt.Errorf("%w: %s", ErrObserverNotFound, key)
+			}
+			return nil, fmt.Errorf("failed to get ClusterObserver %s: %w", key, err)
+		}
+		return observer, nil
+	}
+
+	var observers observerv1alpha1.ClusterObserverList
+	if err := k8sClient.List(ctx, &observers, client.InNamespace(key.Namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list ClusterObservers: %w", err)
+	}
+	switch len(observers.Items) {
+	case 0:
+		return nil, ErrObserverNotFound
+	case 1:
+		return &observers.Items[0], nil
+	default:
+		return nil, fmt.Errorf("found %d ClusterObservers, select one with --observer-name and --observer-namespace",
+			len(observers.Items))
+	}
+}
+
+// FromObserver builds the configuration described by a ClusterObserver spec
+func FromObserver(observer *observerv1alpha1.ClusterObserver) (*Config, error) {
+	if err := ValidateEndpoint(observer.Spec.ReportEndpoint); err != nil {
+		return nil, fmt.Errorf("invalid reportEndpoint: %w", err)
 	}
 
 	// Parse report interval
 	interval, err := time.ParseDuration(observer.Spec.ReportInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid reportInterval: %w", err)
+	}
+	if interval < MinReportInterval {
+		return nil, fmt.Errorf("invalid reportInterval: must be at least %s, got %s", MinReportInterval, interval)
+	}
+
+	// Parse ingress label selector
+	selector, err := IngressSelector(observer.Spec.Selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid selector: %w", err)
+	}
+
+	// Parse expiry thresholds
+	warning, critical, err := parseThresholds(observer.Spec.WarningThreshold, observer.Spec.CriticalThreshold)
 	if err != nil {
 		return nil, err
 	}
+	minRenewalLeadTime, err := parseMinRenewalLeadTime(observer.Spec.MinRenewalLeadTime)
+	if err != nil {
+		return nil, err
+	}
+	if err := ValidateReportLabels(observer.Spec.ReportLabels); err != nil {
+		return nil, fmt.Errorf("invalid reportLabels: %w", err)
+	}
 
-	return &Config{
-		ClusterName:    observer.Spec.ClusterName,
-		ReportEndpoint: observer.Spec.ReportEndpoint,
-		ReportInterval: interval,
-	}, nil
+	cfg := &Config{
+		Source:             client.ObjectKeyFromObject(observer),
+		ClusterName:        observer.Spec.ClusterName,
+		ReportEndpoint:     observer.Spec.ReportEndpoint,
+		ReportInterval:     interval,
+		WatchNamespaces:    observer.Spec.WatchNamespaces,
+		ExcludeNamespaces:  observer.Spec.ExcludeNamespaces,
+		IngressSelector:    selector,
+		WarningThreshold:   warning,
+		CriticalThreshold:  critical,
+		MinRenewalLeadTime: minRenewalLeadTime,
+		ReportLabels:       maps.Clone(observer.Spec.ReportLabels),
+	}
+	if err := loadDeadLetterQueue(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportSink(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportTLS(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportProxy(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportSuccessStatusCodes(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportAcceptance(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportBatchSize(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportShutdownTimeout(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportInitialJitter(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadDryRun(cfg); err != nil {
+		return nil, err
+	}
+	loadReportSigning(cfg)
+	if err := loadReportUserAgent(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportDaysUntilExpiry(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportExpiryPrecision(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadReportCertificateMode(cfg); err != nil {
+		return nil, err
+	}
+	if err := loadBusinessHours(cfg); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// IngressSelector converts the spec label selector into a labels.Selector.
+// A nil selector matches every ingress.
+func IngressSelector(selector *metav1.LabelSelector) (labels.Selector, error) {
+	if selector == nil {
+		return labels.Everything(), nil
+	}
+	return metav1.LabelSelectorAsSelector(selector)
+}
+
+// FindClusterNameConflict returns the ClusterObserver that claimed the same
+// cluster name before the given observer, or nil if there is none. The oldest
+// observer owns the cluster name; ties are broken by namespace/name.
+func FindClusterNameConflict(
+	observer *observerv1alpha1.ClusterObserver,
+	observers []observerv1alpha1.ClusterObserver,
+) *observerv1alpha1.ClusterObserver {
+	for i := range observers {
+		other := &observers[i]
+		if other.Namespace == observer.Namespace && other.Name == observer.Name {
+			continue
+		}
+		if other.Spec.ClusterName != observer.Spec.ClusterName {
+			continue
+		}
+		if claimedBefore(other, observer) {
+			return other
+		}
+	}
+	return nil
+}
+
+// claimedBefore reports whether a was created before b
+func claimedBefore(a, b *observerv1alpha1.ClusterObserver) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Namespace+"/"+a.Name < b.Namespace+"/"+b.Name
 }
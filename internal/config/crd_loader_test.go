@@ -0,0 +1,332 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"os"
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	observerv1alpha1 "github.com/ugurcancaykara/cert-observer/api/v1alpha1"
+)
+
+func newObserver(namespace, name, clusterName string, created time.Time) observerv1alpha1.ClusterObserver {
+	return observerv1alpha1.ClusterObserver{
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace:         namespace,
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: observerv1alpha1.ClusterObserverSpec{
+			ClusterName: clusterName,
+		},
+	}
+}
+
+func TestFindClusterNameConflict(t *testing.T) {
+	now := time.Now()
+	first := newObserver("default", "first", "prod", now.Add(-time.Hour))
+	second := newObserver("default", "second", "prod", now)
+	other := newObserver("default", "other", "staging", now.Add(-2*time.Hour))
+	observers := []observerv1alpha1.ClusterObserver{first, second, other}
+
+	if got := FindClusterNameConflict(&first, observers); got != nil {
+		t.Errorf("oldest observer reported conflict with %s", got.Name)
+	}
+	got := FindClusterNameConflict(&second, observers)
+	if got == nil || got.Name != "first" {
+		t.Errorf("FindClusterNameConflict() = %v, want first", got)
+	}
+	if got := FindClusterNameConflict(&other, observers); got != nil {
+		t.Errorf("observer with unique cluster name reported conflict with %s", got.Name)
+	}
+}
+
+func TestFindClusterNameConflict_SameCreationTime(t *testing.T) {
+	now := time.Now()
+	a := newObserver("default", "a", "prod", now)
+	b := newObserver("default", "b", "prod", now)
+	observers := []observerv1alpha1.ClusterObserver{a, b}
+
+	if got := FindClusterNameConflict(&a, observers); got != nil {
+		t.Errorf("observer a reported conflict with %s", got.Name)
+	}
+	if got := FindClusterNameConflict(&b, observers); got == nil || got.Name != "a" {
+		t.Errorf("FindClusterNameConflict() = %v, want a", got)
+	}
+}
+
+func TestIngressSelector(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *metav1.LabelSelector
+		labels   map[string]string
+		want     bool
+	}{
+		{
+			name:     "nil selector matches everything",
+			selector: nil,
+			labels:   map[string]string{},
+			want:     true,
+		},
+		{
+			name:     "matchLabels match",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"observe": "true"}},
+			labels:   map[string]string{"observe": "true", "team": "web"},
+			want:     true,
+		},
+		{
+			name:     "matchLabels mismatch",
+			selector: &metav1.LabelSelector{MatchLabels: map[string]string{"observe": "true"}},
+			labels:   map[string]string{"observe": "false"},
+			want:     false,
+		},
+		{
+			name: "matchExpressions In",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "team", Operator: metav1.LabelSelectorOpIn, Values: []string{"web", "api"}},
+			}},
+			labels: map[string]string{"team": "api"},
+			want:   true,
+		},
+		{
+			name: "matchExpressions DoesNotExist",
+			selector: &metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+				{Key: "skip-observe", Operator: metav1.LabelSelectorOpDoesNotExist},
+			}},
+			labels: map[string]string{"skip-observe": ""},
+			want:   false,
+		},
+		{
+			name: "matchLabels and matchExpressions combined",
+			selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"observe": "true"},
+				MatchExpressions: []metav1.LabelSelectorRequirement{
+					{Key: "team", Operator: metav1.LabelSelectorOpNotIn, Values: []string{"legacy"}},
+				},
+			},
+			labels: map[string]string{"observe": "true", "team": "legacy"},
+			want:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			selector, err := IngressSelector(tt.selector)
+			if err != nil {
+				t.Fatalf("IngressSelector() error = %v", err)
+			}
+			if got := selector.Matches(labels.Set(tt.labels)); got != tt.want {
+				t.Errorf("Matches(%v) = %v, want %v", tt.labels, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngressSelector_Invalid(t *testing.T) {
+	_, err := IngressSelector(&metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{
+		{Key: "team", Operator: "Bogus"},
+	}})
+	if err == nil {
+		t.Error("IngressSelector() expected error for invalid operator")
+	}
+}
+
+func newObserverClient(t *testing.T, observers ...observerv1alpha1.ClusterObserver) client.WithWatch {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := observerv1alpha1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	builder := fake.NewClientBuilder().WithScheme(scheme)
+	for i := range observers {
+		builder = builder.WithObjects(&observers[i])
+	}
+	return builder.Build()
+}
+
+func TestLoadFromCRD(t *testing.T) {
+	os.Clearenv()
+	now := time.Now()
+	prod := newObserver("observers", "prod", "prod-cluster", now)
+	staging := newObserver("default", "staging", "staging-cluster", now)
+	for _, observer := range []*observerv1alpha1.ClusterObserver{&prod, &staging} {
+		observer.Spec.ReportEndpoint = "http://collector:8080/report"
+		observer.Spec.ReportInterval = "30s"
+	}
+
+	tests := []struct {
+		name        string
+		observers   []observerv1alpha1.ClusterObserver
+		key         types.NamespacedName
+		wantCluster string
+		wantErr     error
+	}{
+		{
+			name:        "named observer found",
+			observers:   []observerv1alpha1.ClusterObserver{prod, staging},
+			key:         types.NamespacedName{Name: "staging", Namespace: "default"},
+			wantCluster: "staging-cluster",
+		},
+		{
+			name:      "named observer not found",
+			observers: []observerv1alpha1.ClusterObserver{prod},
+			key:       types.NamespacedName{Name: "staging", Namespace: "default"},
+			wantErr:   ErrObserverNotFound,
+		},
+		{
+			name:        "single observer discovered",
+			observers:   []observerv1alpha1.ClusterObserver{prod},
+			wantCluster: "prod-cluster",
+		},
+		{
+			name:    "no observer to discover",
+			wantErr: ErrObserverNotFound,
+		},
+		{
+			name:      "multiple observers discovered",
+			observers: []observerv1alpha1.ClusterObserver{prod, staging},
+		},
+		{
+			name:        "discovery limited to namespace",
+			observers:   []observerv1alpha1.ClusterObserver{prod, staging},
+			key:         types.NamespacedName{Namespace: "observers"},
+			wantCluster: "prod-cluster",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := LoadFromCRD(context.Background(), newObserverClient(t, tt.observers...), tt.key)
+			if tt.wantCluster == "" {
+				if err == nil {
+					t.Fatalf("LoadFromCRD() = %+v, want error", cfg)
+				}
+				if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+					t.Errorf("LoadFromCRD() error = %v, want %v", err, tt.wantErr)
+				}
+				if tt.wantErr == nil && errors.Is(err, ErrObserverNotFound) {
+					t.Errorf("LoadFromCRD() error = %v, want an error other than %v", err, ErrObserverNotFound)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFromCRD() error = %v", err)
+			}
+			if cfg.ClusterName != tt.wantCluster {
+				t.Errorf("ClusterName = %q, want %q", cfg.ClusterName, tt.wantCluster)
+			}
+		})
+	}
+}
+
+func TestLoadFromCRD_APIError(t *testing.T) {
+	apiErr := errors.New("connection refused")
+	k8sClient := interceptor.NewClient(newObserverClient(t), interceptor.Funcs{
+		Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+			return apiErr
+		},
+	})
+
+	_, err := LoadFromCRD(context.Background(), k8sClient, types.NamespacedName{Name: "prod", Namespace: "default"})
+	if !errors.Is(err, apiErr) {
+		t.Errorf("LoadFromCRD() error = %v, want %v", err, apiErr)
+	}
+	if errors.Is(err, ErrObserverNotFound) {
+		t.Errorf("LoadFromCRD() reported an API error as not found: %v", err)
+	}
+}
+
+func TestFromObserver_ReportLabels(t *testing.T) {
+	os.Clearenv()
+	observer := newObserver("default", "prod", "prod-cluster", time.Now())
+	observer.Spec.ReportEndpoint = "http://collector:8080/report"
+	observer.Spec.ReportInterval = "30s"
+	observer.Spec.ReportLabels = map[string]string{"region": "eu", "env": "prod"}
+
+	cfg, err := FromObserver(&observer)
+	if err != nil {
+		t.Fatalf("FromObserver() error = %v", err)
+	}
+	if !maps.Equal(cfg.ReportLabels, observer.Spec.ReportLabels) {
+		t.Errorf("ReportLabels = %v, want %v", cfg.ReportLabels, observer.Spec.ReportLabels)
+	}
+
+	observer.Spec.ReportLabels = map[string]string{"": "eu"}
+	if _, err := FromObserver(&observer); err == nil {
+		t.Error("FromObserver() error = nil, want an error for an empty key")
+	}
+}
+
+func TestFromObserver_ReportSink(t *testing.T) {
+	observer := newObserver("default", "prod", "prod-cluster", time.Now())
+	observer.Spec.ReportEndpoint = "http://collector:8080/report"
+	observer.Spec.ReportInterval = "30s"
+
+	tests := []struct {
+		name     string
+		envVars  map[string]string
+		wantSink string
+		wantPath string
+		wantOTLP string
+		wantErr  bool
+	}{
+		{name: "default", wantSink: SinkHTTP},
+		{
+			name:     "file",
+			envVars:  map[string]string{"REPORT_SINK": "file", "REPORT_FILE_PATH": "/data/reports.ndjson"},
+			wantSink: SinkFile,
+			wantPath: "/data/reports.ndjson",
+		},
+		{name: "file without path", envVars: map[string]string{"REPORT_SINK": "file"}, wantErr: true},
+		{
+			name:     "otlp",
+			envVars:  map[string]string{"REPORT_SINK": "otlp", "REPORT_OTLP_ENDPOINT": "http://otel-collector:4318/v1/metrics"},
+			wantSink: SinkOTLP,
+			wantOTLP: "http://otel-collector:4318/v1/metrics",
+		},
+		{
+			name:    "otlp with invalid endpoint",
+			envVars: map[string]string{"REPORT_SINK": "otlp", "REPORT_OTLP_ENDPOINT": "otel"},
+			wantErr: true,
+		},
+		{name: "unknown sink", envVars: map[string]string{"REPORT_SINK": "kafka"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for k, v := range tt.envVars {
+				if err := os.Setenv(k, v); err != nil {
+					t.Fatalf("failed to set env var: %v", err)
+				}
+			}
+
+			cfg, err := FromObserver(&observer)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("FromObserver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if cfg.ReportSink != tt.wantSink {
+				t.Errorf("ReportSink = %q, want %q", cfg.ReportSink, tt.wantSink)
+			}
+			if cfg.ReportFilePath != tt.wantPath {
+				t.Errorf("ReportFilePath = %q, want %q", cfg.ReportFilePath, tt.wantPath)
+			}
+			if cfg.ReportOTLPEndpoint != tt.wantOTLP {
+				t.Errorf("ReportOTLPEndpoint = %q, want %q", cfg.ReportOTLPEndpoint, tt.wantOTLP)
+			}
+		})
+	}
+}
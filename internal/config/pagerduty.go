@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// DefaultPagerDutyEventsURL is the PagerDuty Events API v2 enqueue endpoint
+const DefaultPagerDutyEventsURL = "https://events.pagerduty.com/v2/enqueue"
+
+// PagerDutyConfig holds the configuration for PagerDuty expiry alerts
+type PagerDutyConfig struct {
+	RoutingKey        string
+	EventsURL         string
+	CriticalThreshold time.Duration
+	CheckInterval     time.Duration
+}
+
+// LoadPagerDuty loads PagerDuty alerting configuration from environment variables
+// Returns nil if PAGERDUTY_ROUTING_KEY is not set (alerting is disabled)
+func LoadPagerDuty() (*PagerDutyConfig, error) {
+	routingKey := getEnv("PAGERDUTY_ROUTING_KEY", "")
+	if routingKey == "" {
+		return nil, nil
+	}
+
+	cfg := &PagerDutyConfig{
+		RoutingKey: routingKey,
+		EventsURL:  getEnv("PAGERDUTY_EVENTS_URL", DefaultPagerDutyEventsURL),
+	}
+
+	threshold, err := time.ParseDuration(getEnv("PAGERDUTY_CRITICAL_THRESHOLD", "168h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PAGERDUTY_CRITICAL_THRESHOLD: %w", err)
+	}
+	if threshold <= 0 {
+		return nil, fmt.Errorf("invalid PAGERDUTY_CRITICAL_THRESHOLD: must be positive, got %s", threshold)
+	}
+	cfg.CriticalThreshold = threshold
+
+	interval, err := time.ParseDuration(getEnv("PAGERDUTY_CHECK_INTERVAL", "1m"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid PAGERDUTY_CHECK_INTERVAL: %w", err)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid PAGERDUTY_CHECK_INTERVAL: must be positive, got %s", interval)
+	}
+	cfg.CheckInterval = interval
+
+	return cfg, nil
+}
@@ -0,0 +1,119 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadPagerDuty(t *testing.T) {
+	tests := []struct {
+		name          string
+		envVars       map[string]string
+		wantNil       bool
+		wantThreshold time.Duration
+		wantInterval  time.Duration
+		wantURL       string
+		wantErr       bool
+	}{
+		{
+			name:    "disabled without routing key",
+			envVars: map[string]string{},
+			wantNil: true,
+		},
+		{
+			name:          "default values",
+			envVars:       map[string]string{"PAGERDUTY_ROUTING_KEY": "key"},
+			wantThreshold: 7 * 24 * time.Hour,
+			wantInterval:  time.Minute,
+			wantURL:       DefaultPagerDutyEventsURL,
+		},
+		{
+			name: "custom values",
+			envVars: map[string]string{
+				"PAGERDUTY_ROUTING_KEY":        "key",
+				"PAGERDUTY_EVENTS_URL":         "http://stub/v2/enqueue",
+				"PAGERDUTY_CRITICAL_THRESHOLD": "72h",
+				"PAGERDUTY_CHECK_INTERVAL":     "5m",
+			},
+			wantThreshold: 72 * time.Hour,
+			wantInterval:  5 * time.Minute,
+			wantURL:       "http://stub/v2/enqueue",
+		},
+		{
+			name: "invalid threshold",
+			envVars: map[string]string{
+				"PAGERDUTY_ROUTING_KEY":        "key",
+				"PAGERDUTY_CRITICAL_THRESHOLD": "soon",
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero threshold",
+			envVars: map[string]string{
+				"PAGERDUTY_ROUTING_KEY":        "key",
+				"PAGERDUTY_CRITICAL_THRESHOLD": "0s",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative threshold",
+			envVars: map[string]string{
+				"PAGERDUTY_ROUTING_KEY":        "key",
+				"PAGERDUTY_CRITICAL_THRESHOLD": "-24h",
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero check interval",
+			envVars: map[string]string{
+				"PAGERDUTY_ROUTING_KEY":    "key",
+				"PAGERDUTY_CHECK_INTERVAL": "0s",
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative check interval",
+			envVars: map[string]string{
+				"PAGERDUTY_ROUTING_KEY":    "key",
+				"PAGERDUTY_CHECK_INTERVAL": "-1m",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for k, v := range tt.envVars {
+				if err := os.Setenv(k, v); err != nil {
+					t.Fatalf("failed to set env var %s: %v", k, err)
+				}
+			}
+
+			cfg, err := LoadPagerDuty()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadPagerDuty() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantNil {
+				if cfg != nil {
+					t.Errorf("LoadPagerDuty() = %+v, want nil", cfg)
+				}
+				return
+			}
+
+			if cfg.CriticalThreshold != tt.wantThreshold {
+				t.Errorf("CriticalThreshold = %v, want %v", cfg.CriticalThreshold, tt.wantThreshold)
+			}
+			if cfg.CheckInterval != tt.wantInterval {
+				t.Errorf("CheckInterval = %v, want %v", cfg.CheckInterval, tt.wantInterval)
+			}
+			if cfg.EventsURL != tt.wantURL {
+				t.Errorf("EventsURL = %v, want %v", cfg.EventsURL, tt.wantURL)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+)
+
+const (
+	// DefaultMetricsAddr is the address the metrics and query server binds to
+	DefaultMetricsAddr = ":9090"
+	// DefaultHealthAddr is the address the health probe server binds to
+	DefaultHealthAddr = ":8081"
+)
+
+// ServerConfig holds the bind addresses of the observer's HTTP servers
+type ServerConfig struct {
+	// MetricsAddr serves /metrics and the query API, DefaultMetricsAddr
+	// unless METRICS_ADDR is set
+	MetricsAddr string
+	// HealthAddr serves /healthz and /readyz, DefaultHealthAddr unless
+	// HEALTH_ADDR is set
+	HealthAddr string
+}
+
+// LoadServer loads the server bind addresses from METRICS_ADDR and
+// HEALTH_ADDR, falling back to the defaults
+func LoadServer() (*ServerConfig, error) {
+	cfg := &ServerConfig{
+		MetricsAddr: getEnv("METRICS_ADDR", DefaultMetricsAddr),
+		HealthAddr:  getEnv("HEALTH_ADDR", DefaultHealthAddr),
+	}
+	if err := ValidateBindAddress(cfg.MetricsAddr); err != nil {
+		return nil, fmt.Errorf("invalid METRICS_ADDR: %w", err)
+	}
+	if err := ValidateBindAddress(cfg.HealthAddr); err != nil {
+		return nil, fmt.Errorf("invalid HEALTH_ADDR: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Validate checks both addresses and that the servers don't bind to the same
+// fixed address
+func (c *ServerConfig) Validate() error {
+	if err := ValidateBindAddress(c.MetricsAddr); err != nil {
+		return fmt.Errorf("invalid metrics address: %w", err)
+	}
+	if err := ValidateBindAddress(c.HealthAddr); err != nil {
+		return fmt.Errorf("invalid health address: %w", err)
+	}
+	if _, port, _ := net.SplitHostPort(c.MetricsAddr); c.MetricsAddr == c.HealthAddr && port != "0" {
+		return fmt.Errorf("metrics and health servers can't both bind to %s", c.MetricsAddr)
+	}
+	return nil
+}
+
+// ValidateBindAddress checks that addr is a host:port address a server can
+// listen on, e.g. ":9090" or "127.0.0.1:9090". The host may be empty for all
+// interfaces, and the port must be numeric.
+func ValidateBindAddress(addr string) error {
+	_, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("must be host:port, got %q", addr)
+	}
+	if _, err := strconv.ParseUint(port, 10, 16); err != nil {
+		return fmt.Errorf("port must be a number between 0 and 65535, got %q", port)
+	}
+	return nil
+}
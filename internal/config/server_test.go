@@ -0,0 +1,81 @@
+package config
+
+import (
+	"os"
+	"testing"
+)
+
+func TestLoadServer(t *testing.T) {
+	tests := []struct {
+		name        string
+		envVars     map[string]string
+		wantMetrics string
+		wantHealth  string
+		wantErr     bool
+	}{
+		{
+			name:        "default values",
+			envVars:     map[string]string{},
+			wantMetrics: ":9090",
+			wantHealth:  ":8081",
+		},
+		{
+			name:        "custom values",
+			envVars:     map[string]string{"METRICS_ADDR": "127.0.0.1:19090", "HEALTH_ADDR": "[::1]:18081"},
+			wantMetrics: "127.0.0.1:19090",
+			wantHealth:  "[::1]:18081",
+		},
+		{
+			name:        "random ports",
+			envVars:     map[string]string{"METRICS_ADDR": ":0", "HEALTH_ADDR": ":0"},
+			wantMetrics: ":0",
+			wantHealth:  ":0",
+		},
+		{
+			name:    "missing port",
+			envVars: map[string]string{"METRICS_ADDR": "localhost"},
+			wantErr: true,
+		},
+		{
+			name:    "named port",
+			envVars: map[string]string{"HEALTH_ADDR": ":http"},
+			wantErr: true,
+		},
+		{
+			name:    "port out of range",
+			envVars: map[string]string{"METRICS_ADDR": ":70000"},
+			wantErr: true,
+		},
+		{
+			name:    "same address",
+			envVars: map[string]string{"METRICS_ADDR": ":8081"},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for k, v := range tt.envVars {
+				if err := os.Setenv(k, v); err != nil {
+					t.Fatalf("failed to set env var %s: %v", k, err)
+				}
+			}
+
+			cfg, err := LoadServer()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+
+			if cfg.MetricsAddr != tt.wantMetrics {
+				t.Errorf("MetricsAddr = %q, want %q", cfg.MetricsAddr, tt.wantMetrics)
+			}
+			if cfg.HealthAddr != tt.wantHealth {
+				t.Errorf("HealthAddr = %q, want %q", cfg.HealthAddr, tt.wantHealth)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// SlackConfig holds the configuration for Slack expiry notifications
+type SlackConfig struct {
+	WebhookURL      string
+	ExpiryThreshold time.Duration
+	CheckInterval   time.Duration
+}
+
+// LoadSlack loads Slack notification configuration from environment variables
+// Returns nil if SLACK_WEBHOOK_URL is not set (notifications are disabled)
+func LoadSlack() (*SlackConfig, error) {
+	webhookURL := getEnv("SLACK_WEBHOOK_URL", "")
+	if webhookURL == "" {
+		return nil, nil
+	}
+	if err := ValidateEndpoint(webhookURL); err != nil {
+		return nil, fmt.Errorf("invalid SLACK_WEBHOOK_URL: %w", err)
+	}
+
+	cfg := &SlackConfig{WebhookURL: webhookURL}
+
+	threshold, err := time.ParseDuration(getEnv("SLACK_EXPIRY_THRESHOLD", "720h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLACK_EXPIRY_THRESHOLD: %w", err)
+	}
+	cfg.ExpiryThreshold = threshold
+
+	interval, err := time.ParseDuration(getEnv("SLACK_CHECK_INTERVAL", "1h"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid SLACK_CHECK_INTERVAL: %w", err)
+	}
+	if interval <= 0 {
+		return nil, fmt.Errorf("invalid SLACK_CHECK_INTERVAL: must be positive, got %s", interval)
+	}
+	cfg.CheckInterval = interval
+
+	return cfg, nil
+}
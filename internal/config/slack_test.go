@@ -0,0 +1,93 @@
+package config
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestLoadSlack(t *testing.T) {
+	tests := []struct {
+		name          string
+		envVars       map[string]string
+		wantNil       bool
+		wantThreshold time.Duration
+		wantInterval  time.Duration
+		wantErr       bool
+	}{
+		{
+			name:    "disabled without webhook URL",
+			envVars: map[string]string{},
+			wantNil: true,
+		},
+		{
+			name:          "default values",
+			envVars:       map[string]string{"SLACK_WEBHOOK_URL": "https://hooks.slack.com/services/T0/B0/x"},
+			wantThreshold: 30 * 24 * time.Hour,
+			wantInterval:  time.Hour,
+		},
+		{
+			name: "custom values",
+			envVars: map[string]string{
+				"SLACK_WEBHOOK_URL":      "https://hooks.slack.com/services/T0/B0/x",
+				"SLACK_EXPIRY_THRESHOLD": "168h",
+				"SLACK_CHECK_INTERVAL":   "15m",
+			},
+			wantThreshold: 7 * 24 * time.Hour,
+			wantInterval:  15 * time.Minute,
+		},
+		{
+			name:    "invalid webhook URL",
+			envVars: map[string]string{"SLACK_WEBHOOK_URL": "hooks.slack.com/services/T0/B0/x"},
+			wantErr: true,
+		},
+		{
+			name: "invalid threshold",
+			envVars: map[string]string{
+				"SLACK_WEBHOOK_URL":      "https://hooks.slack.com/services/T0/B0/x",
+				"SLACK_EXPIRY_THRESHOLD": "soon",
+			},
+			wantErr: true,
+		},
+		{
+			name: "zero interval",
+			envVars: map[string]string{
+				"SLACK_WEBHOOK_URL":    "https://hooks.slack.com/services/T0/B0/x",
+				"SLACK_CHECK_INTERVAL": "0s",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			os.Clearenv()
+			for k, v := range tt.envVars {
+				if err := os.Setenv(k, v); err != nil {
+					t.Fatalf("failed to set env var %s: %v", k, err)
+				}
+			}
+
+			cfg, err := LoadSlack()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("LoadSlack() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if tt.wantNil {
+				if cfg != nil {
+					t.Errorf("LoadSlack() = %+v, want nil", cfg)
+				}
+				return
+			}
+
+			if cfg.ExpiryThreshold != tt.wantThreshold {
+				t.Errorf("ExpiryThreshold = %v, want %v", cfg.ExpiryThreshold, tt.wantThreshold)
+			}
+			if cfg.CheckInterval != tt.wantInterval {
+				t.Errorf("CheckInterval = %v, want %v", cfg.CheckInterval, tt.wantInterval)
+			}
+		})
+	}
+}
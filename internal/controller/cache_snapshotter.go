@@ -0,0 +1,93 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+// CacheSnapshotter periodically writes the cache to a file so that it can be
+// restored after a restart instead of starting empty
+type CacheSnapshotter struct {
+	Cache    *cache.IngressCache
+	Path     string
+	Interval time.Duration
+	Log      logr.Logger
+}
+
+// Restore loads the snapshot file into the cache. A missing file is not an
+// error; a corrupt file returns an error and leaves the cache empty.
+func (s *CacheSnapshotter) Restore() error {
+	file, err := os.Open(s.Path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.Log.Info("no cache snapshot found, starting with an empty cache", "path", s.Path)
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to open cache snapshot: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	if err := s.Cache.Restore(file); err != nil {
+		return err
+	}
+	s.Log.Info("restored cache from snapshot", "path", s.Path, "entries", s.Cache.Len())
+	return nil
+}
+
+// Start writes a snapshot every interval and once more on shutdown
+func (s *CacheSnapshotter) Start(ctx context.Context) error {
+	s.Log.Info("starting cache snapshotter", "interval", s.Interval, "path", s.Path)
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			if err := s.Write(); err != nil {
+				s.Log.Error(err, "failed to write final cache snapshot")
+			}
+			s.Log.Info("stopping cache snapshotter")
+			return nil
+		case <-ticker.C:
+			if err := s.Write(); err != nil {
+				s.Log.Error(err, "failed to write cache snapshot")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false since every replica keeps its own cache
+func (s *CacheSnapshotter) NeedLeaderElection() bool {
+	return false
+}
+
+// Write stores the current cache in the snapshot file. The snapshot is
+// written to a temporary file first so a crash never leaves a partial file.
+func (s *CacheSnapshotter) Write() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.Path), filepath.Base(s.Path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache snapshot: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if err := s.Cache.Snapshot(tmp); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache snapshot: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.Path); err != nil {
+		return fmt.Errorf("failed to replace cache snapshot: %w", err)
+	}
+	return nil
+}
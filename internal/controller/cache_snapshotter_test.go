@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+var _ = Describe("Cache Snapshotter", func() {
+	newSnapshotter := func(path string) *CacheSnapshotter {
+		return &CacheSnapshotter{
+			Cache: cache.NewIngressCache("test-cluster"),
+			Path:  path,
+			Log:   logr.Discard(),
+		}
+	}
+
+	It("should restore a written snapshot into a new cache", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "cache.json")
+
+		writer := newSnapshotter(path)
+		writer.Cache.Add(&cache.IngressInfo{Namespace: "default", Name: "webapp", Hosts: []cache.HostInfo{{Host: "webapp.local"}}})
+		Expect(writer.Write()).To(Succeed())
+
+		reader := newSnapshotter(path)
+		Expect(reader.Restore()).To(Succeed())
+		Expect(reader.Cache.GetAll()).To(HaveLen(1))
+	})
+
+	It("should start empty when the snapshot is missing", func() {
+		snapshotter := newSnapshotter(filepath.Join(GinkgoT().TempDir(), "missing.json"))
+		Expect(snapshotter.Restore()).To(Succeed())
+		Expect(snapshotter.Cache.GetAll()).To(BeEmpty())
+	})
+
+	It("should report a corrupt snapshot", func() {
+		path := filepath.Join(GinkgoT().TempDir(), "cache.json")
+		Expect(os.WriteFile(path, []byte("not json"), 0o600)).To(Succeed())
+
+		snapshotter := newSnapshotter(path)
+		Expect(snapshotter.Restore()).NotTo(Succeed())
+		Expect(snapshotter.Cache.GetAll()).To(BeEmpty())
+	})
+})
@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	networkingv1 "k8s.io/api/networking/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+// CacheSweeper periodically removes cache entries for ingresses that no
+// longer exist, e.g. because a delete event was missed during a restart
+type CacheSweeper struct {
+	Client   client.Reader
+	Cache    *cache.IngressCache
+	Interval time.Duration
+	Log      logr.Logger
+}
+
+// Start runs the sweep loop until the context is cancelled
+func (s *CacheSweeper) Start(ctx context.Context) error {
+	s.Log.Info("starting cache sweeper", "interval", s.Interval)
+
+	ticker := time.NewTicker(s.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.Log.Info("stopping cache sweeper")
+			return nil
+		case <-ticker.C:
+			if err := s.Sweep(ctx); err != nil {
+				s.Log.Error(err, "failed to sweep cache")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false since every replica keeps its own cache
+func (s *CacheSweeper) NeedLeaderElection() bool {
+	return false
+}
+
+// Sweep lists all ingresses and prunes cache entries without a live ingress
+func (s *CacheSweeper) Sweep(ctx context.Context) error {
+	listedAt := time.Now()
+
+	var ingressList networkingv1.IngressList
+	if err := s.Client.List(ctx, &ingressList); err != nil {
+		return err
+	}
+
+	live := make([]string, 0, len(ingressList.Items))
+	for _, ingress := range ingressList.Items {
+		live = append(live, ingress.Namespace+"/"+ingress.Name)
+	}
+
+	if removed := s.Cache.Prune("", live, listedAt); removed > 0 {
+		s.Log.Info("pruned stale cache entries", "count", removed)
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+var _ = Describe("Cache Sweeper", func() {
+	It("should prune entries for ingresses that no longer exist", func() {
+		ctx := context.Background()
+
+		live := &networkingv1.Ingress{ObjectMeta: metav1.ObjectMeta{Name: "live", Namespace: "default"}}
+		ingressCache := cache.NewIngressCache("test-cluster")
+		ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "live", Hosts: []cache.HostInfo{{Host: "live.local"}}})
+		ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "ghost", Hosts: []cache.HostInfo{{Host: "ghost.local"}}})
+		ingressCache.Add(&cache.IngressInfo{Namespace: "team-a", Name: "ghost", Hosts: []cache.HostInfo{{Host: "other.local"}}})
+
+		// Make sure the seeded entries predate the sweep's listing
+		time.Sleep(time.Millisecond)
+
+		sweeper := &CacheSweeper{
+			Client:   fake.NewClientBuilder().WithObjects(live).Build(),
+			Cache:    ingressCache,
+			Interval: time.Minute,
+			Log:      logr.Discard(),
+		}
+		Expect(sweeper.Sweep(ctx)).To(Succeed())
+
+		entries := ingressCache.GetAll()
+		Expect(entries).To(HaveLen(1))
+		Expect(entries[0].Name).To(Equal("live"))
+	})
+})
@@ -17,24 +17,82 @@ limitations under the License.
 package controller
 
 import (
+	"cmp"
 	"context"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"sync"
 	"time"
 
+	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
 	observerv1alpha1 "github.com/ugurcancaykara/cert-observer/api/v1alpha1"
 	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+	"github.com/ugurcancaykara/cert-observer/internal/reporter"
 )
 
+// DefaultObserverRequeueInterval is the base requeue interval used when
+// RequeueInterval is not set
+const DefaultObserverRequeueInterval = 30 * time.Second
+
+// DefaultReportFailureThreshold is the number of consecutive failed reports
+// after which the Reporting condition turns false, used when
+// ReportFailureThreshold is not set
+const DefaultReportFailureThreshold = 3
+
+// ReloadableReporter receives configuration changes and exposes its report
+// delivery counters, e.g. the HTTPReporter
+type ReloadableReporter interface {
+	Update(cfg *config.Config)
+	DeliveryStats() reporter.DeliveryStats
+}
+
+// ReporterManager runs a reporter per ClusterObserver, e.g. reporter.Manager
+type ReporterManager interface {
+	Apply(key types.NamespacedName, cfg *config.Config)
+	Remove(key types.NamespacedName)
+	DeliveryStats(key types.NamespacedName) (reporter.DeliveryStats, bool)
+}
+
 // ClusterObserverReconciler reconciles a ClusterObserver object
 type ClusterObserverReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
 	Cache  *cache.IngressCache
+	// RequeueInterval is the base delay between reconciles of an observer
+	RequeueInterval time.Duration
+	// RequeueJitter spreads requeues by up to this fraction of the interval
+	// in either direction, so observers don't reconcile in lockstep
+	RequeueJitter float64
+	// Reporter is updated when the ClusterObserver at ReporterSource
+	// changes; nil when reporting is disabled
+	Reporter       ReloadableReporter
+	ReporterSource types.NamespacedName
+	// Reporters runs a reporter for each ClusterObserver, started on create,
+	// reconfigured on update and stopped on delete; nil when disabled
+	Reporters ReporterManager
+	// ReportFailureThreshold is the number of consecutive failed reports
+	// after which the observer is no longer considered reporting
+	ReportFailureThreshold int
+	// MaxConcurrentReconciles is the number of ClusterObservers reconciled
+	// in parallel; 1 when unset
+	MaxConcurrentReconciles int
+
+	// reporterMu guards reporterGeneration, the spec generation of
+	// ReporterSource last applied to Reporter
+	reporterMu         sync.Mutex
+	reporterGeneration int64
 }
 
 // +kubebuilder:rbac:groups=observer.cert-observer.io,resources=clusterobservers,verbs=get;list;watch;create;update;patch;delete
@@ -50,21 +108,60 @@ func (r *ClusterObserverReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	observer := &observerv1alpha1.ClusterObserver{}
 	if err := r.Get(ctx, req.NamespacedName, observer); err != nil {
 		if errors.IsNotFound(err) {
+			// Observer deleted, stop its reporter
+			if r.Reporters != nil {
+				r.Reporters.Remove(req.NamespacedName)
+			}
 			return ctrl.Result{}, nil
 		}
 		logger.Error(err, "failed to get ClusterObserver")
 		return ctrl.Result{}, err
 	}
 
-	// Validate report interval
-	if _, err := time.ParseDuration(observer.Spec.ReportInterval); err != nil {
-		logger.Error(err, "invalid report interval", "interval", observer.Spec.ReportInterval)
+	// Validate the spec; an invalid one is reported through the ConfigValid
+	// condition rather than retried, since only a spec change can fix it
+	cfg, cfgErr := config.FromObserver(observer)
+	setConfigValidCondition(observer, cfgErr)
+	if cfgErr != nil {
+		logger.Error(cfgErr, "invalid ClusterObserver configuration, keeping current reporter settings")
+	}
+
+	// Hot-reload the reporter from the observer it was configured by
+	if cfgErr == nil && r.Reporter != nil && req.NamespacedName == r.ReporterSource {
+		r.updateReporter(logger, observer, cfg)
+	}
+
+	// Flag observers that claim a cluster name already owned by an older observer
+	owner, err := r.updateConflictCondition(ctx, observer)
+	if err != nil {
+		logger.Error(err, "failed to check for cluster name conflicts")
 		return ctrl.Result{}, err
 	}
+	blocked := owner != nil && observer.Spec.ConflictPolicy == observerv1alpha1.ConflictPolicyBlock
+
+	// Run the observer's own reporter unless its cluster name is blocked
+	if r.Reporters != nil {
+		switch {
+		case blocked:
+			r.Reporters.Remove(req.NamespacedName)
+		case cfgErr == nil:
+			r.Reporters.Apply(req.NamespacedName, cfg)
+		}
+	}
+
+	r.setReportingCondition(observer, blocked)
+	setReadyCondition(observer)
+
+	// Surface the last delivered report, e.g. in kubectl get output
+	if delivery, ok := r.deliveryStats(req.NamespacedName); ok && !delivery.LastSuccess.IsZero() {
+		observer.Status.LastReportTime = &metav1.Time{Time: delivery.LastSuccess}
+	}
 
-	// Update status with current ingress count
-	ingresses := r.Cache.GetAll()
+	// Update status with current ingress and secret counts
+	ingresses := r.Cache.GetAllShared()
 	observer.Status.IngressCount = len(ingresses)
+	observer.Status.NamespaceBreakdown = namespaceBreakdown(ingresses)
+	observer.Status.TLSSecretCount = r.Cache.TLSSecretCount()
 
 	if err := r.Status().Update(ctx, observer); err != nil {
 		logger.Error(err, "failed to update ClusterObserver status")
@@ -72,11 +169,226 @@ func (r *ClusterObserverReconciler) Reconcile(ctx context.Context, req ctrl.Requ
 	}
 
 	logger.Info("reconciled ClusterObserver",
-		"name", observer.Name,
 		"cluster", observer.Spec.ClusterName,
 		"ingress_count", observer.Status.IngressCount)
 
-	return ctrl.Result{RequeueAfter: 30 * time.Second}, nil
+	return ctrl.Result{RequeueAfter: r.requeueAfter()}, nil
+}
+
+// namespaceBreakdown counts ingresses per namespace, sorted by count in
+// descending order and by namespace for equal counts
+func namespaceBreakdown(ingresses []*cache.IngressInfo) []observerv1alpha1.NamespaceIngressCount {
+	counts := make(map[string]int)
+	for _, info := range ingresses {
+		counts[info.Namespace]++
+	}
+
+	breakdown := make([]observerv1alpha1.NamespaceIngressCount, 0, len(counts))
+	for namespace, count := range counts {
+		breakdown = append(breakdown, observerv1alpha1.NamespaceIngressCount{
+			Namespace:    namespace,
+			IngressCount: count,
+		})
+	}
+	slices.SortFunc(breakdown, func(a, b observerv1alpha1.NamespaceIngressCount) int {
+		if c := cmp.Compare(b.IngressCount, a.IngressCount); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Namespace, b.Namespace)
+	})
+	return breakdown
+}
+
+// updateReporter applies the configuration of the observer at
+// ReporterSource to the Reporter, unless a newer generation of its spec was
+// already applied, e.g. by the ConfigReloader while the informer cache lags
+// behind. It reports whether the configuration was applied. The cache and
+// metrics keep the cluster name they were started with, so a changed
+// clusterName is logged and only applied on restart, keeping reports and
+// metrics in agreement.
+func (r *ClusterObserverReconciler) updateReporter(logger logr.Logger, observer *observerv1alpha1.ClusterObserver,
+	cfg *config.Config) bool {
+	r.reporterMu.Lock()
+	defer r.reporterMu.Unlock()
+	if observer.Generation < r.reporterGeneration {
+		return false
+	}
+	changed := observer.Generation != r.reporterGeneration
+	r.reporterGeneration = observer.Generation
+	if r.Cache != nil && cfg.ClusterName != r.Cache.ClusterName() {
+		if changed {
+			logger.Info("clusterName changed, restart the controller to apply it",
+				"running", r.Cache.ClusterName(), "configured", cfg.ClusterName)
+		}
+		cfg.ClusterName = r.Cache.ClusterName()
+	}
+	r.Reporter.Update(cfg)
+	return true
+}
+
+// requeueAfter returns the base requeue interval with random jitter applied
+func (r *ClusterObserverReconciler) requeueAfter() time.Duration {
+	base := r.RequeueInterval
+	if base <= 0 {
+		base = DefaultObserverRequeueInterval
+	}
+	if r.RequeueJitter <= 0 {
+		return base
+	}
+	// Uniform in [base*(1-jitter), base*(1+jitter))
+	offset := (2*rand.Float64() - 1) * r.RequeueJitter * float64(base)
+	return base + time.Duration(offset)
+}
+
+// setConfigValidCondition sets the ConfigValid condition from the result of
+// parsing the observer's spec
+func setConfigValidCondition(observer *observerv1alpha1.ClusterObserver, cfgErr error) {
+	condition := metav1.Condition{
+		Type:               observerv1alpha1.ConditionTypeConfigValid,
+		Status:             metav1.ConditionTrue,
+		Reason:             "ConfigValid",
+		Message:            "the spec describes a valid configuration",
+		ObservedGeneration: observer.Generation,
+	}
+	if cfgErr != nil {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "InvalidConfig"
+		condition.Message = cfgErr.Error()
+	}
+	meta.SetStatusCondition(&observer.Status.Conditions, condition)
+}
+
+// setReportingCondition sets the Reporting condition from the delivery
+// counters of the observer's reporter
+func (r *ClusterObserverReconciler) setReportingCondition(observer *observerv1alpha1.ClusterObserver, blocked bool) {
+	condition := metav1.Condition{
+		Type:               observerv1alpha1.ConditionTypeReporting,
+		ObservedGeneration: observer.Generation,
+	}
+
+	threshold := r.ReportFailureThreshold
+	if threshold <= 0 {
+		threshold = DefaultReportFailureThreshold
+	}
+
+	delivery, ok := r.deliveryStats(client.ObjectKeyFromObject(observer))
+	switch {
+	case blocked:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReporterBlocked"
+		condition.Message = "the cluster name is claimed by another ClusterObserver"
+	case !ok:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "NoReporter"
+		condition.Message = "no reporter runs for this ClusterObserver"
+	case delivery.ConsecutiveFailures >= threshold:
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = "ReportsFailing"
+		condition.Message = fmt.Sprintf("the last %d reports failed", delivery.ConsecutiveFailures)
+	case delivery.LastSuccess.IsZero():
+		condition.Status = metav1.ConditionUnknown
+		condition.Reason = "Pending"
+		condition.Message = "no report has been delivered yet"
+	default:
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = "ReportsDelivered"
+		condition.Message = "reports are being delivered"
+	}
+	meta.SetStatusCondition(&observer.Status.Conditions, condition)
+}
+
+// deliveryStats returns the delivery counters of the reporter driven by the
+// observer, or false if no reporter runs for it
+func (r *ClusterObserverReconciler) deliveryStats(key types.NamespacedName) (reporter.DeliveryStats, bool) {
+	if r.Reporters != nil {
+		return r.Reporters.DeliveryStats(key)
+	}
+	if r.Reporter != nil && key == r.ReporterSource {
+		return r.Reporter.DeliveryStats(), true
+	}
+	return reporter.DeliveryStats{}, false
+}
+
+// setReadyCondition sets the Ready condition, which is true when both
+// ConfigValid and Reporting are. Otherwise it carries the reason of the
+// first one that isn't.
+func setReadyCondition(observer *observerv1alpha1.ClusterObserver) {
+	for _, conditionType := range []string{
+		observerv1alpha1.ConditionTypeConfigValid,
+		observerv1alpha1.ConditionTypeReporting,
+	} {
+		condition := meta.FindStatusCondition(observer.Status.Conditions, conditionType)
+		if condition != nil && condition.Status == metav1.ConditionTrue {
+			continue
+		}
+		ready := metav1.Condition{
+			Type:               observerv1alpha1.ConditionTypeReady,
+			Status:             metav1.ConditionFalse,
+			Reason:             "NotReady",
+			Message:            conditionType + " is not true",
+			ObservedGeneration: observer.Generation,
+		}
+		if condition != nil {
+			ready.Reason = condition.Reason
+			ready.Message = condition.Message
+		}
+		meta.SetStatusCondition(&observer.Status.Conditions, ready)
+		return
+	}
+
+	meta.SetStatusCondition(&observer.Status.Conditions, metav1.Condition{
+		Type:               observerv1alpha1.ConditionTypeReady,
+		Status:             metav1.ConditionTrue,
+		Reason:             "Ready",
+		Message:            "the configuration is valid and reports are being delivered",
+		ObservedGeneration: observer.Generation,
+	})
+}
+
+// updateConflictCondition sets the Conflicting condition on the observer
+// depending on whether an older ClusterObserver uses the same cluster name.
+// It returns that older observer, or nil if there is none.
+func (r *ClusterObserverReconciler) updateConflictCondition(
+	ctx context.Context,
+	observer *observerv1alpha1.ClusterObserver,
+) (*observerv1alpha1.ClusterObserver, error) {
+	logger := log.FromContext(ctx)
+
+	var observers observerv1alpha1.ClusterObserverList
+	if err := r.List(ctx, &observers); err != nil {
+		return nil, err
+	}
+
+	owner := config.FindClusterNameConflict(observer, observers.Items)
+	if owner == nil {
+		meta.SetStatusCondition(&observer.Status.Conditions, metav1.Condition{
+			Type:               observerv1alpha1.ConditionTypeConflicting,
+			Status:             metav1.ConditionFalse,
+			Reason:             "ClusterNameUnique",
+			Message:            "no other ClusterObserver claims this cluster name",
+			ObservedGeneration: observer.Generation,
+		})
+		return nil, nil
+	}
+
+	reason := "ClusterNameClaimed"
+	if observer.Spec.ConflictPolicy == observerv1alpha1.ConflictPolicyBlock {
+		reason = "ReporterBlocked"
+	}
+	meta.SetStatusCondition(&observer.Status.Conditions, metav1.Condition{
+		Type:   observerv1alpha1.ConditionTypeConflicting,
+		Status: metav1.ConditionTrue,
+		Reason: reason,
+		Message: fmt.Sprintf("cluster name %q is already claimed by %s/%s",
+			observer.Spec.ClusterName, owner.Namespace, owner.Name),
+		ObservedGeneration: observer.Generation,
+	})
+	logger.Info("cluster name conflicts with another ClusterObserver",
+		"cluster", observer.Spec.ClusterName,
+		"owner", client.ObjectKeyFromObject(owner),
+		"policy", observer.Spec.ConflictPolicy)
+
+	return owner, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -84,5 +396,11 @@ func (r *ClusterObserverReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&observerv1alpha1.ClusterObserver{}).
 		Named("clusterobserver").
+		WithOptions(r.controllerOptions()).
 		Complete(r)
 }
+
+// controllerOptions returns the options of the ClusterObserver controller
+func (r *ClusterObserverReconciler) controllerOptions() controller.Options {
+	return controller.Options{MaxConcurrentReconciles: max(r.MaxConcurrentReconciles, 1)}
+}
@@ -18,17 +18,31 @@ package controller
 
 import (
 	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	observerv1alpha1 "github.com/ugurcancaykara/cert-observer/api/v1alpha1"
 	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+	"github.com/ugurcancaykara/cert-observer/internal/reporter"
 )
 
 var _ = Describe("ClusterObserver Controller", func() {
@@ -85,5 +99,489 @@ var _ = Describe("ClusterObserver Controller", func() {
 			})
 			Expect(err).NotTo(HaveOccurred())
 		})
+
+		It("should flag a later observer claiming the same cluster name", func() {
+			By("Creating a second observer with the same cluster name")
+			duplicateName := types.NamespacedName{
+				Name:      resourceName + "-duplicate",
+				Namespace: "default",
+			}
+			duplicate := &observerv1alpha1.ClusterObserver{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      duplicateName.Name,
+					Namespace: duplicateName.Namespace,
+				},
+				Spec: observerv1alpha1.ClusterObserverSpec{
+					ClusterName:    "test-cluster",
+					ReportEndpoint: "http://test-server:8080/report",
+					ReportInterval: "30s",
+					ConflictPolicy: observerv1alpha1.ConflictPolicyBlock,
+				},
+			}
+			Expect(k8sClient.Create(ctx, duplicate)).To(Succeed())
+			defer func() {
+				Expect(k8sClient.Delete(ctx, duplicate)).To(Succeed())
+			}()
+
+			controllerReconciler := &ClusterObserverReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+				Cache:  cache.NewIngressCache("test-cluster"),
+			}
+
+			By("Reconciling both observers")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: duplicateName})
+			Expect(err).NotTo(HaveOccurred())
+
+			By("Checking that only the later observer is flagged")
+			original := &observerv1alpha1.ClusterObserver{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, original)).To(Succeed())
+			Expect(meta.IsStatusConditionFalse(original.Status.Conditions,
+				observerv1alpha1.ConditionTypeConflicting)).To(BeTrue())
+
+			Expect(k8sClient.Get(ctx, duplicateName, duplicate)).To(Succeed())
+			condition := meta.FindStatusCondition(duplicate.Status.Conditions, observerv1alpha1.ConditionTypeConflicting)
+			Expect(condition).NotTo(BeNil())
+			Expect(condition.Status).To(Equal(metav1.ConditionTrue))
+			Expect(condition.Reason).To(Equal("ReporterBlocked"))
+		})
+	})
+
+	Context("When reconciling against a fake client", func() {
+		ctx := context.Background()
+
+		It("should jitter RequeueAfter around the configured base", func() {
+			observerScheme := runtime.NewScheme()
+			Expect(observerv1alpha1.AddToScheme(observerScheme)).To(Succeed())
+
+			observer := &observerv1alpha1.ClusterObserver{
+				ObjectMeta: metav1.ObjectMeta{Name: "jitter", Namespace: "default"},
+				Spec: observerv1alpha1.ClusterObserverSpec{
+					ClusterName:    "jitter-cluster",
+					ReportEndpoint: "http://test-server:8080/report",
+					ReportInterval: "30s",
+				},
+			}
+			controllerReconciler := &ClusterObserverReconciler{
+				Client: fake.NewClientBuilder().WithScheme(observerScheme).
+					WithObjects(observer).WithStatusSubresource(observer).Build(),
+				Scheme:          observerScheme,
+				Cache:           cache.NewIngressCache("jitter-cluster"),
+				RequeueInterval: 10 * time.Second,
+				RequeueJitter:   0.2,
+			}
+
+			seen := make(map[time.Duration]bool)
+			for range 20 {
+				result, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "jitter", Namespace: "default"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(result.RequeueAfter).To(BeNumerically(">=", 8*time.Second))
+				Expect(result.RequeueAfter).To(BeNumerically("<=", 12*time.Second))
+				seen[result.RequeueAfter] = true
+			}
+			Expect(len(seen)).To(BeNumerically(">", 1), "requeue intervals should vary")
+		})
+
+		It("should update the reporter when its ClusterObserver changes", func() {
+			observerScheme := runtime.NewScheme()
+			Expect(observerv1alpha1.AddToScheme(observerScheme)).To(Succeed())
+
+			source := types.NamespacedName{Name: "reload", Namespace: "default"}
+			observer := &observerv1alpha1.ClusterObserver{
+				ObjectMeta: metav1.ObjectMeta{Name: source.Name, Namespace: source.Namespace},
+				Spec: observerv1alpha1.ClusterObserverSpec{
+					ClusterName:    "reload-cluster",
+					ReportEndpoint: "http://collector:8080/report",
+					ReportInterval: "45s",
+				},
+			}
+			updater := &recordingUpdater{}
+			controllerReconciler := &ClusterObserverReconciler{
+				Client: fake.NewClientBuilder().WithScheme(observerScheme).
+					WithObjects(observer).WithStatusSubresource(observer).Build(),
+				Scheme:         observerScheme,
+				Cache:          cache.NewIngressCache("reload-cluster"),
+				Reporter:       updater,
+				ReporterSource: source,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: source})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updater.configs).To(HaveLen(1))
+			Expect(updater.configs[0].ReportInterval).To(Equal(45 * time.Second))
+			Expect(updater.configs[0].ReportEndpoint).To(Equal("http://collector:8080/report"))
+		})
+
+		It("should manage a reporter per ClusterObserver", func() {
+			observerScheme := runtime.NewScheme()
+			Expect(observerv1alpha1.AddToScheme(observerScheme)).To(Succeed())
+
+			newObserver := func(name, clusterName string, created time.Time) *observerv1alpha1.ClusterObserver {
+				return &observerv1alpha1.ClusterObserver{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              name,
+						Namespace:         "default",
+						CreationTimestamp: metav1.NewTime(created),
+					},
+					Spec: observerv1alpha1.ClusterObserverSpec{
+						ClusterName:    clusterName,
+						ReportEndpoint: "http://" + name + ":8080/report",
+						ReportInterval: "30s",
+					},
+				}
+			}
+			now := time.Now()
+			teamA := newObserver("team-a", "shared", now.Add(-time.Hour))
+			teamB := newObserver("team-b", "team-b", now)
+			blocked := newObserver("blocked", "shared", now)
+			blocked.Spec.ConflictPolicy = observerv1alpha1.ConflictPolicyBlock
+
+			k8sClient := fake.NewClientBuilder().WithScheme(observerScheme).
+				WithObjects(teamA, teamB, blocked).WithStatusSubresource(teamA, teamB, blocked).Build()
+			reporters := &recordingReporters{applied: map[types.NamespacedName]*config.Config{}}
+			controllerReconciler := &ClusterObserverReconciler{
+				Client:    k8sClient,
+				Scheme:    observerScheme,
+				Cache:     cache.NewIngressCache(""),
+				Reporters: reporters,
+			}
+
+			for _, observer := range []*observerv1alpha1.ClusterObserver{teamA, teamB, blocked} {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: client.ObjectKeyFromObject(observer),
+				})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(reporters.applied).To(HaveLen(2))
+			Expect(reporters.applied[client.ObjectKeyFromObject(teamA)].ReportEndpoint).
+				To(Equal("http://team-a:8080/report"))
+			Expect(reporters.applied[client.ObjectKeyFromObject(teamB)].ClusterName).To(Equal("team-b"))
+			Expect(reporters.removed).To(ConsistOf(client.ObjectKeyFromObject(blocked)))
+
+			By("Deleting an observer")
+			Expect(k8sClient.Delete(ctx, teamB)).To(Succeed())
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: client.ObjectKeyFromObject(teamB),
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(reporters.applied).To(HaveLen(1))
+			Expect(reporters.removed).To(ContainElement(client.ObjectKeyFromObject(teamB)))
+		})
+
+		It("should flag an invalid spec through the ConfigValid and Ready conditions", func() {
+			observerScheme := runtime.NewScheme()
+			Expect(observerv1alpha1.AddToScheme(observerScheme)).To(Succeed())
+
+			key := types.NamespacedName{Name: "invalid", Namespace: "default"}
+			observer := &observerv1alpha1.ClusterObserver{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace, Generation: 1},
+				Spec: observerv1alpha1.ClusterObserverSpec{
+					ClusterName:    "invalid-cluster",
+					ReportEndpoint: "http://collector:8080/report",
+					ReportInterval: "soon",
+				},
+			}
+			k8sClient := fake.NewClientBuilder().WithScheme(observerScheme).
+				WithObjects(observer).WithStatusSubresource(observer).Build()
+			updater := &recordingUpdater{delivery: reporter.DeliveryStats{Sent: 1, LastSuccess: time.Now()}}
+			controllerReconciler := &ClusterObserverReconciler{
+				Client:         k8sClient,
+				Scheme:         observerScheme,
+				Cache:          cache.NewIngressCache("invalid-cluster"),
+				Reporter:       updater,
+				ReporterSource: key,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(updater.configs).To(BeEmpty(), "an invalid spec must not reach the reporter")
+
+			Expect(k8sClient.Get(ctx, key, observer)).To(Succeed())
+			configValid := meta.FindStatusCondition(observer.Status.Conditions, observerv1alpha1.ConditionTypeConfigValid)
+			Expect(configValid).NotTo(BeNil())
+			Expect(configValid.Status).To(Equal(metav1.ConditionFalse))
+			Expect(configValid.Reason).To(Equal("InvalidConfig"))
+			Expect(configValid.Message).To(ContainSubstring("reportInterval"))
+			Expect(configValid.ObservedGeneration).To(Equal(observer.Generation))
+			ready := meta.FindStatusCondition(observer.Status.Conditions, observerv1alpha1.ConditionTypeReady)
+			Expect(ready).NotTo(BeNil())
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("InvalidConfig"))
+
+			By("Fixing the interval")
+			observer.Spec.ReportInterval = "30s"
+			Expect(k8sClient.Update(ctx, observer)).To(Succeed())
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(k8sClient.Get(ctx, key, observer)).To(Succeed())
+			Expect(meta.IsStatusConditionTrue(observer.Status.Conditions,
+				observerv1alpha1.ConditionTypeConfigValid)).To(BeTrue())
+			Expect(meta.IsStatusConditionTrue(observer.Status.Conditions,
+				observerv1alpha1.ConditionTypeReady)).To(BeTrue())
+		})
+
+		It("should flag failing reports through the Reporting and Ready conditions", func() {
+			observerScheme := runtime.NewScheme()
+			Expect(observerv1alpha1.AddToScheme(observerScheme)).To(Succeed())
+
+			key := types.NamespacedName{Name: "reporting", Namespace: "default"}
+			observer := &observerv1alpha1.ClusterObserver{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: observerv1alpha1.ClusterObserverSpec{
+					ClusterName:    "reporting-cluster",
+					ReportEndpoint: "http://collector:8080/report",
+					ReportInterval: "30s",
+				},
+			}
+			k8sClient := fake.NewClientBuilder().WithScheme(observerScheme).
+				WithObjects(observer).WithStatusSubresource(observer).Build()
+			updater := &recordingUpdater{}
+			controllerReconciler := &ClusterObserverReconciler{
+				Client:                 k8sClient,
+				Scheme:                 observerScheme,
+				Cache:                  cache.NewIngressCache("reporting-cluster"),
+				Reporter:               updater,
+				ReporterSource:         key,
+				ReportFailureThreshold: 2,
+			}
+			reportingCondition := func() *metav1.Condition {
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(k8sClient.Get(ctx, key, observer)).To(Succeed())
+				return meta.FindStatusCondition(observer.Status.Conditions, observerv1alpha1.ConditionTypeReporting)
+			}
+
+			Expect(reportingCondition().Status).To(Equal(metav1.ConditionUnknown))
+			Expect(meta.IsStatusConditionFalse(observer.Status.Conditions,
+				observerv1alpha1.ConditionTypeReady)).To(BeTrue())
+
+			Expect(observer.Status.LastReportTime).To(BeNil())
+
+			By("Delivering a report")
+			delivered := time.Now()
+			updater.delivery = reporter.DeliveryStats{Sent: 1, LastSuccess: delivered}
+			Expect(reportingCondition().Status).To(Equal(metav1.ConditionTrue))
+			Expect(observer.Status.LastReportTime).NotTo(BeNil())
+			Expect(observer.Status.LastReportTime.Time).To(BeTemporally("~", delivered, time.Second))
+			Expect(meta.IsStatusConditionTrue(observer.Status.Conditions,
+				observerv1alpha1.ConditionTypeReady)).To(BeTrue())
+
+			By("Failing fewer reports than the threshold")
+			updater.delivery.Failed, updater.delivery.ConsecutiveFailures = 1, 1
+			Expect(reportingCondition().Status).To(Equal(metav1.ConditionTrue))
+
+			By("Failing as many reports as the threshold")
+			updater.delivery.Failed, updater.delivery.ConsecutiveFailures = 2, 2
+			condition := reportingCondition()
+			Expect(condition.Status).To(Equal(metav1.ConditionFalse))
+			Expect(condition.Reason).To(Equal("ReportsFailing"))
+			ready := meta.FindStatusCondition(observer.Status.Conditions, observerv1alpha1.ConditionTypeReady)
+			Expect(ready.Status).To(Equal(metav1.ConditionFalse))
+			Expect(ready.Reason).To(Equal("ReportsFailing"))
+
+			By("Recovering")
+			updater.delivery.ConsecutiveFailures = 0
+			Expect(reportingCondition().Status).To(Equal(metav1.ConditionTrue))
+		})
+
+		It("should report no reporter for observers without one", func() {
+			observerScheme := runtime.NewScheme()
+			Expect(observerv1alpha1.AddToScheme(observerScheme)).To(Succeed())
+
+			key := types.NamespacedName{Name: "idle", Namespace: "default"}
+			observer := &observerv1alpha1.ClusterObserver{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: observerv1alpha1.ClusterObserverSpec{
+					ClusterName:    "idle-cluster",
+					ReportEndpoint: "http://collector:8080/report",
+					ReportInterval: "30s",
+				},
+			}
+			k8sClient := fake.NewClientBuilder().WithScheme(observerScheme).
+				WithObjects(observer).WithStatusSubresource(observer).Build()
+			controllerReconciler := &ClusterObserverReconciler{
+				Client: k8sClient,
+				Scheme: observerScheme,
+				Cache:  cache.NewIngressCache("idle-cluster"),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, observer)).To(Succeed())
+			reporting := meta.FindStatusCondition(observer.Status.Conditions, observerv1alpha1.ConditionTypeReporting)
+			Expect(reporting.Status).To(Equal(metav1.ConditionFalse))
+			Expect(reporting.Reason).To(Equal("NoReporter"))
+			Expect(meta.IsStatusConditionTrue(observer.Status.Conditions,
+				observerv1alpha1.ConditionTypeConfigValid)).To(BeTrue())
+		})
+
+		It("should break ingresses down by namespace, most ingresses first", func() {
+			ingresses := []*cache.IngressInfo{
+				{Namespace: "team-b", Name: "api"},
+				{Namespace: "team-a", Name: "web"},
+				{Namespace: "team-c", Name: "shop"},
+				{Namespace: "team-c", Name: "cart"},
+				{Namespace: "team-a", Name: "docs"},
+				{Namespace: "team-c", Name: "search"},
+			}
+
+			Expect(namespaceBreakdown(ingresses)).To(Equal([]observerv1alpha1.NamespaceIngressCount{
+				{Namespace: "team-c", IngressCount: 3},
+				{Namespace: "team-a", IngressCount: 2},
+				{Namespace: "team-b", IngressCount: 1},
+			}))
+			Expect(namespaceBreakdown(nil)).To(BeEmpty())
+		})
+
+		It("should report the namespace breakdown and TLS secret count in status", func() {
+			observerScheme := runtime.NewScheme()
+			Expect(observerv1alpha1.AddToScheme(observerScheme)).To(Succeed())
+
+			key := types.NamespacedName{Name: "breakdown", Namespace: "default"}
+			observer := &observerv1alpha1.ClusterObserver{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: observerv1alpha1.ClusterObserverSpec{
+					ClusterName:    "breakdown-cluster",
+					ReportEndpoint: "http://collector:8080/report",
+					ReportInterval: "30s",
+				},
+			}
+			ingressCache := cache.NewIngressCache("breakdown-cluster")
+			certificate := func(secret string) *cache.CertificateInfo {
+				return &cache.CertificateInfo{Name: secret, ChainLength: 1}
+			}
+			ingressCache.Add(&cache.IngressInfo{Namespace: "shop", Name: "web", Hosts: []cache.HostInfo{
+				{Host: "shop.example.com", Certificate: certificate("shop-tls")},
+				{Host: "www.shop.example.com", Certificate: certificate("shop-tls")},
+			}})
+			ingressCache.Add(&cache.IngressInfo{Namespace: "shop", Name: "api", Hosts: []cache.HostInfo{
+				{Host: "api.shop.example.com", Certificate: certificate("api-tls")},
+			}})
+			ingressCache.Add(&cache.IngressInfo{Namespace: "docs", Name: "web", Hosts: []cache.HostInfo{
+				{Host: "docs.example.com", Certificate: &cache.CertificateInfo{Name: "missing-tls"}},
+			}})
+
+			k8sClient := fake.NewClientBuilder().WithScheme(observerScheme).
+				WithObjects(observer).WithStatusSubresource(observer).Build()
+			controllerReconciler := &ClusterObserverReconciler{
+				Client: k8sClient,
+				Scheme: observerScheme,
+				Cache:  ingressCache,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(k8sClient.Get(ctx, key, observer)).To(Succeed())
+			Expect(observer.Status.IngressCount).To(Equal(3))
+			Expect(observer.Status.TLSSecretCount).To(Equal(2))
+			Expect(observer.Status.NamespaceBreakdown).To(Equal([]observerv1alpha1.NamespaceIngressCount{
+				{Namespace: "shop", IngressCount: 2},
+				{Namespace: "docs", IngressCount: 1},
+			}))
+		})
+
+		It("should default to the fixed base without jitter", func() {
+			controllerReconciler := &ClusterObserverReconciler{}
+			Expect(controllerReconciler.requeueAfter()).To(Equal(DefaultObserverRequeueInterval))
+		})
+	})
+
+	Context("When configuring concurrent reconciles", func() {
+		// runObservers starts a controller with the reconciler's options,
+		// queues the given number of observers and returns the reconciler
+		// counting how many of them are reconciled at once
+		runObservers := func(ctx context.Context, r *ClusterObserverReconciler, count int) *countingReconciler {
+			counting := &countingReconciler{release: make(chan struct{})}
+			options := r.controllerOptions()
+			options.Reconciler = counting
+			options.SkipNameValidation = ptr.To(true)
+			c, err := controller.NewUnmanaged("clusterobserver-concurrency", options)
+			Expect(err).NotTo(HaveOccurred())
+
+			events := make(chan event.GenericEvent, count)
+			Expect(c.Watch(source.Channel(events, &handler.EnqueueRequestForObject{}))).To(Succeed())
+			go func() {
+				defer GinkgoRecover()
+				Expect(c.Start(ctx)).To(Succeed())
+			}()
+			for i := range count {
+				events <- event.GenericEvent{Object: &observerv1alpha1.ClusterObserver{
+					ObjectMeta: metav1.ObjectMeta{Name: fmt.Sprintf("observer-%d", i), Namespace: "default"},
+				}}
+			}
+			return counting
+		}
+
+		It("should reconcile one observer at a time by default", func(ctx SpecContext) {
+			counting := runObservers(ctx, &ClusterObserverReconciler{}, 3)
+			defer close(counting.release)
+
+			Eventually(counting.active.Load).Should(BeEquivalentTo(1))
+			Consistently(counting.active.Load, 100*time.Millisecond).Should(BeEquivalentTo(1))
+		})
+
+		It("should reconcile observers in parallel when configured", func(ctx SpecContext) {
+			counting := runObservers(ctx, &ClusterObserverReconciler{MaxConcurrentReconciles: 3}, 3)
+			defer close(counting.release)
+
+			Eventually(counting.active.Load).Should(BeEquivalentTo(3))
+		})
 	})
 })
+
+// recordingUpdater records the configurations passed to Update and reports
+// fixed delivery counters
+type recordingUpdater struct {
+	configs  []*config.Config
+	delivery reporter.DeliveryStats
+}
+
+func (u *recordingUpdater) Update(cfg *config.Config) {
+	u.configs = append(u.configs, cfg)
+}
+
+func (u *recordingUpdater) DeliveryStats() reporter.DeliveryStats {
+	return u.delivery
+}
+
+// recordingReporters records the reporters applied and removed per observer
+type recordingReporters struct {
+	applied map[types.NamespacedName]*config.Config
+	removed []types.NamespacedName
+}
+
+func (r *recordingReporters) Apply(key types.NamespacedName, cfg *config.Config) {
+	r.applied[key] = cfg
+}
+
+func (r *recordingReporters) Remove(key types.NamespacedName) {
+	delete(r.applied, key)
+	r.removed = append(r.removed, key)
+}
+
+func (r *recordingReporters) DeliveryStats(key types.NamespacedName) (reporter.DeliveryStats, bool) {
+	_, ok := r.applied[key]
+	return reporter.DeliveryStats{}, ok
+}
+
+// countingReconciler blocks every reconcile until released and counts how
+// many are running at once
+type countingReconciler struct {
+	active  atomic.Int32
+	release chan struct{}
+}
+
+func (r *countingReconciler) Reconcile(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+	r.active.Add(1)
+	defer r.active.Add(-1)
+	select {
+	case <-r.release:
+	case <-ctx.Done():
+	}
+	return reconcile.Result{}, nil
+}
@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	observerv1alpha1 "github.com/ugurcancaykara/cert-observer/api/v1alpha1"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// ConfigReloader periodically re-reads the ClusterObserver the reporter was
+// configured by and applies spec changes to it, for clusters where watch
+// events are unreliable. It reads from the API server rather than the
+// informer cache, which would miss the same events.
+type ConfigReloader struct {
+	// Client should be uncached, e.g. the manager's API reader
+	Client     client.Reader
+	Reconciler *ClusterObserverReconciler
+	Interval   time.Duration
+	Log        logr.Logger
+	// generation is the spec generation seen by the last reload
+	generation int64
+}
+
+// Start runs the reload loop until the context is cancelled
+func (c *ConfigReloader) Start(ctx context.Context) error {
+	c.Log.Info("starting config reloader", "observer", c.Reconciler.ReporterSource, "interval", c.Interval)
+
+	ticker := time.NewTicker(c.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			c.Log.Info("stopping config reloader")
+			return nil
+		case <-ticker.C:
+			if _, err := c.Reload(ctx); err != nil {
+				c.Log.Error(err, "failed to reload ClusterObserver configuration")
+			}
+		}
+	}
+}
+
+// NeedLeaderElection returns false so that replicas waiting for leadership
+// keep their reporter's configuration current
+func (c *ConfigReloader) NeedLeaderElection() bool {
+	return false
+}
+
+// Reload reads the ClusterObserver and applies its configuration to the
+// reporter if its spec changed since the last reload. It reports whether the
+// configuration was applied. An invalid spec is returned as an error, once
+// per generation, and leaves the reporter unchanged.
+func (c *ConfigReloader) Reload(ctx context.Context) (bool, error) {
+	observer := &observerv1alpha1.ClusterObserver{}
+	if err := c.Client.Get(ctx, c.Reconciler.ReporterSource, observer); err != nil {
+		return false, fmt.Errorf("failed to get ClusterObserver %s: %w", c.Reconciler.ReporterSource, err)
+	}
+	if observer.Generation == c.generation {
+		return false, nil
+	}
+
+	// Recorded before validating, so an invalid spec is reported once
+	c.generation = observer.Generation
+	cfg, err := config.FromObserver(observer)
+	if err != nil {
+		return false, err
+	}
+	if !c.Reconciler.updateReporter(c.Log, observer, cfg) {
+		return false, nil
+	}
+	c.Log.Info("reloaded ClusterObserver configuration", "observer", c.Reconciler.ReporterSource,
+		"generation", observer.Generation, "endpoint", cfg.ReportEndpoint, "interval", cfg.ReportInterval)
+	return true, nil
+}
@@ -0,0 +1,196 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	observerv1alpha1 "github.com/ugurcancaykara/cert-observer/api/v1alpha1"
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+	"github.com/ugurcancaykara/cert-observer/internal/reporter"
+)
+
+var _ = Describe("Config Reloader", func() {
+	source := types.NamespacedName{Name: "timer-reload", Namespace: "default"}
+
+	newObserver := func(generation int64, interval string) *observerv1alpha1.ClusterObserver {
+		return &observerv1alpha1.ClusterObserver{
+			ObjectMeta: metav1.ObjectMeta{Name: source.Name, Namespace: source.Namespace, Generation: generation},
+			Spec: observerv1alpha1.ClusterObserverSpec{
+				ClusterName:    "reload-cluster",
+				ReportEndpoint: "http://collector:8080/report",
+				ReportInterval: interval,
+			},
+		}
+	}
+
+	// setSpec changes the observer's interval and bumps its generation, as
+	// the API server does on spec changes
+	setSpec := func(ctx context.Context, c client.Client, interval string) {
+		observer := &observerv1alpha1.ClusterObserver{}
+		Expect(c.Get(ctx, source, observer)).To(Succeed())
+		observer.Spec.ReportInterval = interval
+		observer.Generation++
+		Expect(c.Update(ctx, observer)).To(Succeed())
+	}
+
+	var (
+		scheme     *runtime.Scheme
+		apiServer  client.Client
+		updater    *lockedUpdater
+		reconciler *ClusterObserverReconciler
+		reloader   *ConfigReloader
+	)
+
+	BeforeEach(func() {
+		scheme = runtime.NewScheme()
+		Expect(observerv1alpha1.AddToScheme(scheme)).To(Succeed())
+
+		apiServer = fake.NewClientBuilder().WithScheme(scheme).WithObjects(newObserver(1, "45s")).Build()
+		updater = &lockedUpdater{}
+		// The reconciler's client stands in for an informer cache that
+		// missed every update
+		stale := newObserver(1, "45s")
+		reconciler = &ClusterObserverReconciler{
+			Client:         fake.NewClientBuilder().WithScheme(scheme).WithObjects(stale).WithStatusSubresource(stale).Build(),
+			Scheme:         scheme,
+			Cache:          cache.NewIngressCache("reload-cluster"),
+			Reporter:       updater,
+			ReporterSource: source,
+		}
+		reloader = &ConfigReloader{
+			Client:     apiServer,
+			Reconciler: reconciler,
+			Interval:   10 * time.Millisecond,
+			Log:        logr.Discard(),
+		}
+	})
+
+	It("should apply spec changes read from the API server", func(ctx SpecContext) {
+		applied, err := reloader.Reload(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(BeTrue())
+		Expect(updater.last().ReportInterval).To(Equal(45 * time.Second))
+
+		// An unchanged spec is not applied again
+		applied, err = reloader.Reload(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(BeFalse())
+
+		setSpec(ctx, apiServer, "10s")
+		applied, err = reloader.Reload(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(BeTrue())
+		Expect(updater.last().ReportInterval).To(Equal(10 * time.Second))
+
+		// A reconcile from the stale cache doesn't revert the reloaded spec
+		_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: source})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(updater.last().ReportInterval).To(Equal(10 * time.Second))
+	})
+
+	It("should keep the reporter unchanged on an invalid spec", func(ctx SpecContext) {
+		_, err := reloader.Reload(ctx)
+		Expect(err).NotTo(HaveOccurred())
+
+		setSpec(ctx, apiServer, "soon")
+		_, err = reloader.Reload(ctx)
+		Expect(err).To(HaveOccurred())
+		Expect(updater.count()).To(Equal(1))
+
+		// The invalid generation is reported once
+		_, err = reloader.Reload(ctx)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should keep the running cluster name until a restart", func(ctx SpecContext) {
+		observer := &observerv1alpha1.ClusterObserver{}
+		Expect(apiServer.Get(ctx, source, observer)).To(Succeed())
+		observer.Spec.ClusterName = "renamed-cluster"
+		observer.Spec.ReportInterval = "10s"
+		observer.Generation++
+		Expect(apiServer.Update(ctx, observer)).To(Succeed())
+
+		applied, err := reloader.Reload(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(applied).To(BeTrue())
+		Expect(updater.last().ReportInterval).To(Equal(10 * time.Second))
+		Expect(updater.last().ClusterName).To(Equal("reload-cluster"), "the cache and metrics still use it")
+	})
+
+	It("should pick up spec changes on its timer", func(ctx SpecContext) {
+		reloadCtx, cancel := context.WithCancel(ctx)
+		defer cancel()
+		go func() { _ = reloader.Start(reloadCtx) }()
+
+		Eventually(func() time.Duration {
+			return updater.last().ReportInterval
+		}).Should(Equal(45 * time.Second))
+
+		setSpec(ctx, apiServer, "2m")
+		Eventually(func() time.Duration {
+			return updater.last().ReportInterval
+		}).Should(Equal(2 * time.Minute))
+	})
+})
+
+// lockedUpdater records the configurations passed to Update and can be read
+// while a ConfigReloader runs
+type lockedUpdater struct {
+	mu      sync.Mutex
+	configs []*config.Config
+}
+
+func (u *lockedUpdater) Update(cfg *config.Config) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.configs = append(u.configs, cfg)
+}
+
+func (u *lockedUpdater) DeliveryStats() reporter.DeliveryStats {
+	return reporter.DeliveryStats{}
+}
+
+// last returns the last applied configuration, or an empty one
+func (u *lockedUpdater) last() *config.Config {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.configs) == 0 {
+		return &config.Config{}
+	}
+	return u.configs[len(u.configs)-1]
+}
+
+// count returns the number of applied configurations
+func (u *lockedUpdater) count() int {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return len(u.configs)
+}
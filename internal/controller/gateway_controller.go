@@ -0,0 +1,256 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/certutil"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
+)
+
+// GatewayReconciler reconciles Gateway API Gateway resources
+type GatewayReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	Cache      *cache.IngressCache
+	Namespaces config.NamespaceFilter
+	// Stats records reconcile counts; nil disables recording
+	Stats *stats.Recorder
+	// Renewals tracks certificate renewals; nil disables tracking
+	Renewals *cache.RenewalHistory
+	// SecretNames flags secrets breaking the naming convention; nil disables the check
+	SecretNames *cache.SecretNameConvention
+	// ACMEIssuers flags certificates issued by ACME authorities; nil disables the check
+	ACMEIssuers *cache.ACMEIssuers
+	// ScanOpaqueSecrets also looks for PEM certificates under any key of
+	// referenced Opaque secrets that lack all of CertificateKeys
+	ScanOpaqueSecrets bool
+	// CertificateKeys are the secret keys tried in order for the
+	// certificate; tls.crt when empty
+	CertificateKeys []string
+	// PKCS12 reads the certificate from a PKCS#12 keystore when a referenced
+	// secret holds one; nil disables keystores
+	PKCS12 *certutil.PKCS12
+	// ReadCACertificates also reports the certificates of the ca.crt bundle
+	// of referenced secrets, e.g. the trust bundle of client certificate
+	// authentication
+	ReadCACertificates bool
+	// RateLimit configures the work queue; the zero value keeps
+	// controller-runtime's defaults
+	RateLimit RateLimit
+}
+
+// gatewaySecretIndex indexes Gateways by the namespace/name of the secrets
+// their listeners reference
+const gatewaySecretIndex = "spec.listeners.tls.certificateRefs"
+
+// gatewaySecretKeys returns the referenced secrets of a Gateway for gatewaySecretIndex
+func gatewaySecretKeys(obj client.Object) []string {
+	gateway, ok := obj.(*gatewayv1.Gateway)
+	if !ok {
+		return nil
+	}
+	var keys []string
+	for _, listener := range gateway.Spec.Listeners {
+		for _, ref := range secretRefs(gateway.Namespace, listener) {
+			if key := ref.String(); !slices.Contains(keys, key) {
+				keys = append(keys, key)
+			}
+		}
+	}
+	return keys
+}
+
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=gateways,verbs=get;list;watch
+
+// Reconcile handles Gateway resource changes, logging through the request's
+// logger with its namespace and name keys
+func (r *GatewayReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	r.Stats.RecordReconcile(stats.KindGateway)
+
+	if !r.Namespaces.Allows(req.Namespace) {
+		logger.V(1).Info("skipping gateway outside watched namespaces")
+		r.Cache.DeleteKind(cache.KindGateway, req.Namespace, req.Name)
+		return ctrl.Result{}, nil
+	}
+
+	logger.Info("reconciling gateway")
+
+	var gateway gatewayv1.Gateway
+	if err := r.Get(ctx, req.NamespacedName, &gateway); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			// Gateway deleted, remove from cache
+			logger.Info("gateway deleted, removing from cache")
+			r.Cache.DeleteKind(cache.KindGateway, req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "failed to get gateway")
+		return ctrl.Result{}, fmt.Errorf("failed to get gateway %s/%s: %w", req.Namespace, req.Name, err)
+	}
+
+	r.updateCache(ctx, &gateway)
+
+	logger.V(1).Info("successfully updated cache")
+	return ctrl.Result{}, nil
+}
+
+// updateCache extracts listener TLS information and updates the cache.
+// Each listener becomes a host entry; listeners with several certificate
+// references get one entry per certificate.
+func (r *GatewayReconciler) updateCache(ctx context.Context, gateway *gatewayv1.Gateway) {
+	logger := log.FromContext(ctx)
+
+	info := &cache.IngressInfo{
+		Kind:      cache.KindGateway,
+		Namespace: gateway.Namespace,
+		Name:      gateway.Name,
+		Hosts:     make([]cache.HostInfo, 0, len(gateway.Spec.Listeners)),
+		Labels:    gateway.Labels,
+	}
+
+	certs := make(map[types.NamespacedName]*cache.CertificateInfo)
+	for _, listener := range gateway.Spec.Listeners {
+		hostname := ""
+		if listener.Hostname != nil {
+			hostname = string(*listener.Hostname)
+		}
+
+		refs := secretRefs(gateway.Namespace, listener)
+		if len(refs) == 0 {
+			info.Hosts = append(info.Hosts, cache.HostInfo{
+				Host:     hostname,
+				Listener: string(listener.Name),
+			})
+			continue
+		}
+
+		for _, ref := range refs {
+			certInfo, exists := certs[ref]
+			if !exists {
+				certInfo = r.fetchCertificate(ctx, ref)
+				r.SecretNames.Check(certInfo)
+				r.ACMEIssuers.Check(certInfo)
+				certs[ref] = certInfo
+			}
+			info.Hosts = append(info.Hosts, cache.HostInfo{
+				Host:        hostname,
+				Listener:    string(listener.Name),
+				Certificate: certInfo,
+			})
+		}
+	}
+
+	logger.V(1).Info("extracted gateway listeners", "hosts", len(info.Hosts))
+	info.SecretCount = len(certs)
+	r.Cache.Add(info)
+}
+
+// fetchCertificate fetches and parses a referenced Secret. Missing or
+// unparsable secrets still produce certificate info without expiry.
+func (r *GatewayReconciler) fetchCertificate(ctx context.Context, ref types.NamespacedName) *cache.CertificateInfo {
+	logger := log.FromContext(ctx)
+
+	var secret corev1.Secret
+	if err := r.Get(ctx, ref, &secret); err != nil {
+		return unreadSecretInfo(ref.Name, ref.Namespace, err)
+	}
+
+	now := time.Now()
+	certInfo, err := parseSecret(&secret, r.CertificateKeys, r.ScanOpaqueSecrets, r.PKCS12,
+		r.ReadCACertificates, now)
+	r.Renewals.Observe(certInfo, ref.Namespace, now)
+	if err != nil {
+		r.Stats.RecordParseError()
+		logger.V(1).Info("failed to extract certificate expiry",
+			"secret", ref.Name,
+			"error", err.Error())
+	}
+	return certInfo
+}
+
+// secretRefs returns the Secrets referenced by a listener's TLS config.
+// References to other kinds are ignored.
+func secretRefs(gatewayNamespace string, listener gatewayv1.Listener) []types.NamespacedName {
+	if listener.TLS == nil {
+		return nil
+	}
+
+	var refs []types.NamespacedName
+	for _, ref := range listener.TLS.CertificateRefs {
+		if ref.Group != nil && *ref.Group != "" {
+			continue
+		}
+		if ref.Kind != nil && *ref.Kind != "Secret" {
+			continue
+		}
+		namespace := gatewayNamespace
+		if ref.Namespace != nil {
+			namespace = string(*ref.Namespace)
+		}
+		refs = append(refs, types.NamespacedName{Namespace: namespace, Name: string(ref.Name)})
+	}
+	return refs
+}
+
+// findGatewaysForSecret returns reconcile requests for all Gateways whose listeners reference the given Secret.
+// Only the referencing Gateways are listed, through gatewaySecretIndex, so
+// the cost follows the number of references rather than the number of Gateways.
+func (r *GatewayReconciler) findGatewaysForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	logger := log.FromContext(ctx)
+
+	// Listeners can reference secrets in other namespaces, so the index
+	// is queried across all of them
+	var gatewayList gatewayv1.GatewayList
+	if err := r.List(ctx, &gatewayList,
+		client.MatchingFields{gatewaySecretIndex: client.ObjectKeyFromObject(secret).String()}); err != nil {
+		logger.Error(err, "failed to list gateways")
+		return []reconcile.Request{}
+	}
+
+	var requests []reconcile.Request
+	for _, gateway := range gatewayList.Items {
+		if !r.Namespaces.Allows(gateway.Namespace) {
+			continue
+		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&gateway),
+		})
+		logger.V(1).Info("secret change triggers gateway reconciliation",
+			"namespace", gateway.Namespace,
+			"name", gateway.Name,
+			"secret", secret.GetName())
+	}
+
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *GatewayReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &gatewayv1.Gateway{},
+		gatewaySecretIndex, gatewaySecretKeys); err != nil {
+		return fmt.Errorf("failed to index gateways by certificate reference: %w", err)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&gatewayv1.Gateway{}).
+		Watches(
+			&corev1.Secret{},
+			r.RateLimit.enqueueSecretRequests(r.findGatewaysForSecret, nil),
+		).
+		Named("gateway").
+		WithOptions(cacheControllerOptions(r.RateLimit)).
+		Complete(r)
+}
@@ -0,0 +1,189 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	gatewayv1 "sigs.k8s.io/gateway-api/apis/v1"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
+)
+
+var _ = Describe("Gateway Controller", func() {
+	Context("When reconciling a gateway with TLS listeners", func() {
+		ctx := context.Background()
+
+		gatewayKey := types.NamespacedName{Name: "web-gateway", Namespace: "default"}
+
+		newReconciler := func(objs ...runtime.Object) (*GatewayReconciler, *cache.IngressCache) {
+			gatewayScheme := runtime.NewScheme()
+			Expect(clientgoscheme.AddToScheme(gatewayScheme)).To(Succeed())
+			Expect(gatewayv1.Install(gatewayScheme)).To(Succeed())
+
+			ingressCache := cache.NewIngressCache("test-cluster")
+			return &GatewayReconciler{
+				Client: fake.NewClientBuilder().WithScheme(gatewayScheme).WithRuntimeObjects(objs...).
+					WithIndex(&gatewayv1.Gateway{}, gatewaySecretIndex, gatewaySecretKeys).Build(),
+				Scheme: gatewayScheme,
+				Cache:  ingressCache,
+			}, ingressCache
+		}
+
+		newGateway := func() *gatewayv1.Gateway {
+			hostname := gatewayv1.Hostname("web.local")
+			return &gatewayv1.Gateway{
+				ObjectMeta: metav1.ObjectMeta{Name: gatewayKey.Name, Namespace: gatewayKey.Namespace},
+				Spec: gatewayv1.GatewaySpec{
+					GatewayClassName: "example",
+					Listeners: []gatewayv1.Listener{
+						{
+							Name:     "https",
+							Hostname: &hostname,
+							Port:     443,
+							Protocol: gatewayv1.HTTPSProtocolType,
+							TLS: &gatewayv1.ListenerTLSConfig{
+								CertificateRefs: []gatewayv1.SecretObjectReference{{Name: "web-tls"}},
+							},
+						},
+						{
+							Name:     "http",
+							Hostname: &hostname,
+							Port:     80,
+							Protocol: gatewayv1.HTTPProtocolType,
+						},
+					},
+				},
+			}
+		}
+
+		It("should cache certificate expiry per listener", func() {
+			certData, err := os.ReadFile("../certutil/testdata/webapp-cert.pem")
+			Expect(err).NotTo(HaveOccurred())
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "default"},
+				Data:       map[string][]byte{"tls.crt": certData},
+			}
+			reconciler, ingressCache := newReconciler(newGateway(), secret)
+
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: gatewayKey})
+			Expect(err).NotTo(HaveOccurred())
+
+			entries := ingressCache.GetAll()
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Kind).To(Equal(cache.KindGateway))
+			Expect(entries[0].Hosts).To(HaveLen(2))
+
+			https := entries[0].Hosts[0]
+			Expect(https.Listener).To(Equal("https"))
+			Expect(https.Host).To(Equal("web.local"))
+			Expect(https.Certificate).NotTo(BeNil())
+			Expect(https.Certificate.Name).To(Equal("web-tls"))
+			Expect(https.Certificate.Expires).NotTo(BeNil())
+
+			Expect(entries[0].Hosts[1].Listener).To(Equal("http"))
+			Expect(entries[0].Hosts[1].Certificate).To(BeNil())
+		})
+
+		It("should count reconciles when stats are enabled", func() {
+			reconciler, _ := newReconciler(newGateway())
+			reconciler.Stats = stats.NewRecorder()
+
+			for range 2 {
+				_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: gatewayKey})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(reconciler.Stats.ReconcileCounts()).To(HaveKeyWithValue(stats.KindGateway, 2))
+		})
+
+		It("should record the namespace of cross-namespace certificate references", func() {
+			gateway := newGateway()
+			certsNamespace := gatewayv1.Namespace("certs")
+			gateway.Spec.Listeners[0].TLS.CertificateRefs[0].Namespace = &certsNamespace
+			reconciler, ingressCache := newReconciler(gateway)
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: gatewayKey})
+			Expect(err).NotTo(HaveOccurred())
+
+			entries := ingressCache.GetAll()
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Hosts[0].Certificate.SecretNamespace).To(Equal("certs"))
+		})
+
+		It("should map secret changes to referencing gateways", func() {
+			reconciler, _ := newReconciler(newGateway())
+
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "default"}}
+			Expect(reconciler.findGatewaysForSecret(ctx, secret)).To(ConsistOf(
+				reconcile.Request{NamespacedName: gatewayKey},
+			))
+
+			other := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "other-tls", Namespace: "default"}}
+			Expect(reconciler.findGatewaysForSecret(ctx, other)).To(BeEmpty())
+		})
+
+		It("should map secret changes to gateways referencing them from another namespace", func() {
+			gateway := newGateway()
+			certsNamespace := gatewayv1.Namespace("certs")
+			gateway.Spec.Listeners[0].TLS.CertificateRefs[0].Namespace = &certsNamespace
+			reconciler, _ := newReconciler(gateway)
+
+			shared := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "certs"}}
+			Expect(reconciler.findGatewaysForSecret(ctx, shared)).To(ConsistOf(
+				reconcile.Request{NamespacedName: gatewayKey},
+			))
+
+			local := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "web-tls", Namespace: "default"}}
+			Expect(reconciler.findGatewaysForSecret(ctx, local)).To(BeEmpty())
+		})
+
+		It("should index each referenced secret of a gateway once", func() {
+			gateway := newGateway()
+			certsNamespace := gatewayv1.Namespace("certs")
+			otherKind := gatewayv1.Kind("ConfigMap")
+			listener := gateway.Spec.Listeners[0]
+			listener.TLS = listener.TLS.DeepCopy()
+			listener.TLS.CertificateRefs = append(listener.TLS.CertificateRefs,
+				gatewayv1.SecretObjectReference{Name: "web-tls", Namespace: &certsNamespace},
+				gatewayv1.SecretObjectReference{Name: "bundle", Kind: &otherKind},
+			)
+			gateway.Spec.Listeners = append(gateway.Spec.Listeners, listener)
+
+			Expect(gatewaySecretKeys(gateway)).To(Equal([]string{"default/web-tls", "certs/web-tls"}))
+		})
+
+		It("should remove deleted gateways from the cache", func() {
+			reconciler, ingressCache := newReconciler()
+			ingressCache.Add(&cache.IngressInfo{Kind: cache.KindGateway, Namespace: "default", Name: gatewayKey.Name})
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: gatewayKey})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ingressCache.GetAll()).To(BeEmpty())
+		})
+	})
+})
@@ -2,59 +2,156 @@ package controller
 
 import (
 	"context"
-	"crypto/x509"
-	"encoding/pem"
+	"errors"
 	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/certutil"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
 	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/ptr"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 )
 
 // IngressReconciler reconciles Ingress resources
 type IngressReconciler struct {
 	client.Client
-	Scheme *runtime.Scheme
-	Cache  *cache.IngressCache
+	Scheme     *runtime.Scheme
+	Cache      *cache.IngressCache
+	Namespaces config.NamespaceFilter
+	// Selector limits observation to matching ingresses; nil matches everything
+	Selector labels.Selector
+	// Stats records reconcile counts; nil disables recording
+	Stats *stats.Recorder
+	// Renewals tracks certificate renewals; nil disables tracking
+	Renewals *cache.RenewalHistory
+	// SecretNames flags secrets breaking the naming convention; nil disables the check
+	SecretNames *cache.SecretNameConvention
+	// ACMEIssuers flags certificates issued by ACME authorities; nil disables the check
+	ACMEIssuers *cache.ACMEIssuers
+	// ScanOpaqueSecrets also looks for PEM certificates under any key of
+	// referenced Opaque secrets that lack all of CertificateKeys
+	ScanOpaqueSecrets bool
+	// CertificateKeys are the secret keys tried in order for the
+	// certificate; tls.crt when empty
+	CertificateKeys []string
+	// PKCS12 reads the certificate from a PKCS#12 keystore when a referenced
+	// secret holds one; nil disables keystores
+	PKCS12 *certutil.PKCS12
+	// ReadCACertificates also reports the certificates of the ca.crt bundle
+	// of referenced secrets, e.g. the trust bundle of client certificate
+	// authentication
+	ReadCACertificates bool
+	// RateLimit configures the work queue; the zero value keeps
+	// controller-runtime's defaults
+	RateLimit RateLimit
+}
+
+// ingressSecretIndex indexes Ingresses by the names of their TLS secrets
+const ingressSecretIndex = "spec.tls.secretName"
+
+// ingressSecretNames returns the TLS secret names of an Ingress for ingressSecretIndex
+func ingressSecretNames(obj client.Object) []string {
+	ingress, ok := obj.(*networkingv1.Ingress)
+	if !ok {
+		return nil
+	}
+	var names []string
+	for _, tls := range ingress.Spec.TLS {
+		if tls.SecretName != "" && !slices.Contains(names, tls.SecretName) {
+			names = append(names, tls.SecretName)
+		}
+	}
+	return names
 }
 
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses/status,verbs=get
 // +kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+// +kubebuilder:rbac:groups="",resources=namespaces,verbs=list
 
-// Reconcile handles Ingress resource changes
+// Reconcile handles Ingress resource changes. The logger controller-runtime
+// puts in ctx already carries the namespace and name keys of the request.
 func (r *IngressReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
+	r.Stats.RecordReconcile(stats.KindIngress)
+
+	if !r.Namespaces.Allows(req.Namespace) {
+		// Out of scope, drop anything cached before the scope changed
+		logger.V(1).Info("skipping ingress outside watched namespaces")
+		r.Cache.Delete(req.Namespace, req.Name)
+		return ctrl.Result{}, nil
+	}
 
-	logger.Info("reconciling ingress", "namespace", req.Namespace, "name", req.Name)
+	logger.Info("reconciling ingress")
 
 	var ingress networkingv1.Ingress
 	if err := r.Get(ctx, req.NamespacedName, &ingress); err != nil {
 		if client.IgnoreNotFound(err) == nil {
 			// Ingress deleted, remove from cache
-			logger.Info("ingress deleted, removing from cache", "namespace", req.Namespace, "name", req.Name)
+			logger.Info("ingress deleted, removing from cache")
 			r.Cache.Delete(req.Namespace, req.Name)
 			return ctrl.Result{}, nil
 		}
-		logger.Error(err, "failed to get ingress", "namespace", req.Namespace, "name", req.Name)
+		logger.Error(err, "failed to get ingress")
 		return ctrl.Result{}, fmt.Errorf("failed to get ingress %s/%s: %w", req.Namespace, req.Name, err)
 	}
 
+	if !r.matchesSelector(&ingress) {
+		// Ingress no longer carries matching labels, evict it
+		logger.V(1).Info("skipping ingress not matching selector")
+		r.Cache.Delete(req.Namespace, req.Name)
+		return ctrl.Result{}, nil
+	}
+
 	// Extract and cache Ingress information
 	r.updateCache(ctx, &ingress)
 
-	logger.V(1).Info("successfully updated cache", "ingress", req.NamespacedName)
+	logger.V(1).Info("successfully updated cache")
 	return ctrl.Result{}, nil
 }
 
+// selectorPredicate drops update events for ingresses that match the
+// selector neither before nor after the change. Updates where either side
+// matches are kept, so an ingress that loses its matching labels is still
+// reconciled and evicted from the cache. Create and delete events always pass
+// so entries restored from a snapshot under a different selector are evicted.
+func (r *IngressReconciler) selectorPredicate() predicate.Predicate {
+	matches := func(obj client.Object) bool {
+		return r.Selector == nil || r.Selector.Matches(labels.Set(obj.GetLabels()))
+	}
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			return matches(e.ObjectOld) || matches(e.ObjectNew)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return matches(e.Object)
+		},
+	}
+}
+
+// matchesSelector reports whether the ingress labels match the configured selector
+func (r *IngressReconciler) matchesSelector(ingress *networkingv1.Ingress) bool {
+	return r.Selector == nil || r.Selector.Matches(labels.Set(ingress.Labels))
+}
+
 // updateCache extracts Ingress information and updates the cache
 func (r *IngressReconciler) updateCache(ctx context.Context, ingress *networkingv1.Ingress) {
 	logger := log.FromContext(ctx)
@@ -62,8 +159,8 @@ func (r *IngressReconciler) updateCache(ctx context.Context, ingress *networking
 	// Extract hosts from rules
 	hosts := make(map[string]bool)
 	for _, rule := range ingress.Spec.Rules {
-		if rule.Host != "" {
-			hosts[rule.Host] = true
+		if host := normalizeHost(rule.Host); host != "" {
+			hosts[host] = true
 		}
 	}
 
@@ -71,7 +168,7 @@ func (r *IngressReconciler) updateCache(ctx context.Context, ingress *networking
 	if len(hosts) == 0 {
 		for _, tls := range ingress.Spec.TLS {
 			for _, host := range tls.Hosts {
-				if host != "" {
+				if host := normalizeHost(host); host != "" {
 					hosts[host] = true
 				}
 			}
@@ -83,7 +180,15 @@ func (r *IngressReconciler) updateCache(ctx context.Context, ingress *networking
 	for _, tls := range ingress.Spec.TLS {
 		for _, host := range tls.Hosts {
 			if tls.SecretName != "" {
-				hostToCert[host] = tls.SecretName
+				hostToCert[normalizeHost(host)] = tls.SecretName
+			}
+		}
+	}
+	// Hosts without an exact TLS entry are served by a covering wildcard one
+	for host := range hosts {
+		if _, ok := hostToCert[host]; !ok {
+			if secretName := wildcardSecret(ingress.Spec.TLS, host); secretName != "" {
+				hostToCert[host] = secretName
 			}
 		}
 	}
@@ -100,39 +205,44 @@ func (r *IngressReconciler) updateCache(ctx context.Context, ingress *networking
 					Name:      tls.SecretName,
 				}, &secret); err != nil {
 					// Secret doesn't exist or can't be fetched, create cert info without expiry
-					certExpiry[tls.SecretName] = &cache.CertificateInfo{
-						Name:    tls.SecretName,
-						Expires: nil,
-					}
+					certExpiry[tls.SecretName] = unreadSecretInfo(tls.SecretName, ingress.Namespace, err)
 				} else {
 					// Extract certificate expiry
-					expiryTime, err := r.extractCertificateExpiry(&secret)
-					certExpiry[tls.SecretName] = &cache.CertificateInfo{
-						Name:    tls.SecretName,
-						Expires: expiryTime,
-					}
+					now := time.Now()
+					certInfo, err := parseSecret(&secret, r.CertificateKeys, r.ScanOpaqueSecrets, r.PKCS12,
+						r.ReadCACertificates, now)
+					r.Renewals.Observe(certInfo, ingress.Namespace, now)
+					certExpiry[tls.SecretName] = certInfo
 					if err != nil {
 						// Log but don't fail - we still want to track the ingress
+						r.Stats.RecordParseError()
 						logger.V(1).Info("failed to extract certificate expiry",
 							"secret", tls.SecretName,
 							"error", err.Error())
 					}
 				}
+				r.SecretNames.Check(certExpiry[tls.SecretName])
+				r.ACMEIssuers.Check(certExpiry[tls.SecretName])
 			}
 		}
 	}
 
 	// Build single IngressInfo with all hosts
 	info := &cache.IngressInfo{
-		Namespace: ingress.Namespace,
-		Name:      ingress.Name,
-		Hosts:     make([]cache.HostInfo, 0, len(hosts)),
+		Namespace:   ingress.Namespace,
+		Name:        ingress.Name,
+		Hosts:       make([]cache.HostInfo, 0, len(hosts)),
+		Labels:      ingress.Labels,
+		SecretCount: len(certExpiry),
 	}
 
 	// Add each host with its certificate info
+	redirect := sslRedirect(ingress.Annotations)
 	for host := range hosts {
 		hostInfo := cache.HostInfo{
-			Host: host,
+			Host:        host,
+			TLSEnabled:  hasTLSEntry(ingress.Spec.TLS, host),
+			SSLRedirect: redirect,
 		}
 
 		// Add certificate info if available
@@ -145,75 +255,231 @@ func (r *IngressReconciler) updateCache(ctx context.Context, ingress *networking
 		info.Hosts = append(info.Hosts, hostInfo)
 	}
 
-	// If no hosts found at all, create an entry with empty host
+	// If no hosts found at all, create a placeholder entry, served by the
+	// certificate of a TLS entry without hosts, e.g. for IP-based access
 	if len(hosts) == 0 {
-		info.Hosts = append(info.Hosts, cache.HostInfo{
-			Host: "",
-		})
+		hostInfo := cache.HostInfo{NoHost: true, SSLRedirect: redirect}
+		for _, tls := range ingress.Spec.TLS {
+			if len(tls.Hosts) == 0 {
+				hostInfo.TLSEnabled = true
+				if tls.SecretName != "" {
+					hostInfo.Certificate = certExpiry[tls.SecretName]
+					break
+				}
+			}
+		}
+		info.Hosts = append(info.Hosts, hostInfo)
 	}
 
 	r.Cache.Add(info)
 }
 
-// extractCertificateExpiry parses the certificate and extracts the NotAfter time
-func (r *IngressReconciler) extractCertificateExpiry(secret *corev1.Secret) (*time.Time, error) {
-	// Get certificate data
-	certData, ok := secret.Data["tls.crt"]
-	if !ok {
-		return nil, fmt.Errorf("secret does not contain tls.crt")
+// unreadSecretInfo is the certificate info, without expiry, of a referenced
+// secret that couldn't be fetched. Its state tells a missing secret apart
+// from one that couldn't be read.
+func unreadSecretInfo(name, namespace string, err error) *cache.CertificateInfo {
+	state := cache.CertificateStateUnreadableSecret
+	if apierrors.IsNotFound(err) {
+		state = cache.CertificateStateMissingSecret
 	}
+	return &cache.CertificateInfo{Name: name, SecretNamespace: namespace, State: state}
+}
 
-	// Try to decode PEM block
-	block, _ := pem.Decode(certData)
-	if block == nil {
-		return nil, fmt.Errorf("failed to decode PEM block")
+// normalizeHost returns host with surrounding whitespace removed. IP
+// literals, including bracketed IPv6 ones like "[2001:db8::1]", are returned
+// in their canonical unbracketed form so the same address is always recorded
+// the same way. Other hosts are returned unchanged.
+func normalizeHost(host string) string {
+	host = strings.TrimSpace(host)
+	literal := host
+	if unbracketed, ok := strings.CutPrefix(host, "["); ok {
+		if unbracketed, ok = strings.CutSuffix(unbracketed, "]"); ok {
+			literal = unbracketed
+		}
 	}
+	if addr, err := netip.ParseAddr(literal); err == nil {
+		return addr.String()
+	}
+	return host
+}
 
-	// Parse certificate
-	cert, err := x509.ParseCertificate(block.Bytes)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse certificate: %w", err)
+// hasTLSEntry reports whether a TLS entry lists the host, directly or through
+// a wildcard covering it. Entries without a secret count too, since ingress
+// controllers serve them with their default certificate.
+func hasTLSEntry(tlsEntries []networkingv1.IngressTLS, host string) bool {
+	for _, tls := range tlsEntries {
+		for _, pattern := range tls.Hosts {
+			pattern = normalizeHost(pattern)
+			if pattern == host || (strings.HasPrefix(pattern, "*.") && cache.HostMatches(pattern, host)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sslRedirectAnnotations are the annotations with which common ingress
+// controllers redirect HTTP to HTTPS, and the value turning the redirect
+// on. An empty value accepts any, e.g. the HTTPS port the AWS Load Balancer
+// Controller redirects to.
+var sslRedirectAnnotations = map[string]string{
+	"nginx.ingress.kubernetes.io/ssl-redirect":           "true",
+	"nginx.ingress.kubernetes.io/force-ssl-redirect":     "true",
+	"ingress.kubernetes.io/ssl-redirect":                 "true",
+	"haproxy.org/ssl-redirect":                           "true",
+	"traefik.ingress.kubernetes.io/redirect-entry-point": "https",
+	"alb.ingress.kubernetes.io/ssl-redirect":             "",
+}
+
+// sslRedirect reports whether one of the sslRedirectAnnotations turns on the
+// redirect to HTTPS
+func sslRedirect(annotations map[string]string) bool {
+	for key, on := range sslRedirectAnnotations {
+		value := strings.TrimSpace(annotations[key])
+		if value != "" && (on == "" || strings.EqualFold(value, on)) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardSecret returns the secret of the first TLS entry with a wildcard
+// host, such as *.apps.example.com, covering the host, or "" if none does
+func wildcardSecret(tlsEntries []networkingv1.IngressTLS, host string) string {
+	for _, tls := range tlsEntries {
+		if tls.SecretName == "" {
+			continue
+		}
+		for _, pattern := range tls.Hosts {
+			if strings.HasPrefix(pattern, "*.") && cache.HostMatches(pattern, host) {
+				return tls.SecretName
+			}
+		}
+	}
+	return ""
+}
+
+// parseSecret extracts certificate information from a referenced secret,
+// reading the certificate from the first of keys present. Opaque secrets
+// without any of them are only scanned for other keys when scanOpaque is set.
+// A PKCS#12 keystore takes precedence over the keys when p12 is set.
+// The ca.crt bundle is added as CACertificates when readCA is set.
+// The info records the secret's resourceVersion as read at now, its state,
+// and a parse failure in its ParseError.
+func parseSecret(secret *corev1.Secret, keys []string, scanOpaque bool, p12 *certutil.PKCS12,
+	readCA bool, now time.Time) (*cache.CertificateInfo, error) {
+	var certInfo *cache.CertificateInfo
+	var err error
+	switch {
+	case p12 != nil && p12.HasKeystore(secret):
+		certInfo, err = certutil.ParsePKCS12Secret(secret, keys, *p12)
+	case scanOpaque && secret.Type == corev1.SecretTypeOpaque:
+		certInfo, err = certutil.ParseOpaqueSecret(secret, keys)
+	default:
+		certInfo, err = certutil.ParseSecret(secret, keys)
+	}
+	certInfo.SecretResourceVersion = secret.ResourceVersion
+	certInfo.SecretObservedAt = now
+	setCertificateState(certInfo, err)
+	if readCA {
+		certInfo.CACertificates = caCertificates(secret)
+	}
+	return certInfo, err
+}
+
+// setCertificateState sets the certificate's State, and its ParseError
+// when parsing failed with err
+func setCertificateState(certInfo *cache.CertificateInfo, err error) {
+	switch {
+	case err == nil:
+		certInfo.State = cache.CertificateStateOK
+	case errors.Is(err, certutil.ErrNoCertificate):
+		certInfo.State = cache.CertificateStateNoCertificate
+		certInfo.ParseError = err.Error()
+	default:
+		certInfo.State = cache.CertificateStateParseError
+		certInfo.ParseError = err.Error()
 	}
+}
 
-	return &cert.NotAfter, nil
+// caCertificates returns the certificates of the secret's ca.crt bundle. A
+// bundle that can't be parsed is reported as a single entry named ca.crt
+// carrying the error, so a broken trust bundle doesn't go unnoticed.
+func caCertificates(secret *corev1.Secret) []cache.CertificateInfo {
+	certs, err := certutil.ParseCACertificates(secret)
+	if err != nil {
+		return []cache.CertificateInfo{{
+			Name:       corev1.ServiceAccountRootCAKey,
+			State:      cache.CertificateStateParseError,
+			ParseError: err.Error(),
+		}}
+	}
+	return certs
 }
 
-// findIngressesForSecret returns reconcile requests for all Ingresses that use the given Secret
+// findIngressesForSecret returns reconcile requests for all Ingresses that use the given Secret.
+// Only the referencing Ingresses are listed, through ingressSecretIndex, so
+// the cost follows the number of references rather than the namespace size.
 func (r *IngressReconciler) findIngressesForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
 	logger := log.FromContext(ctx)
 
+	if !r.Namespaces.Allows(secret.GetNamespace()) {
+		return []reconcile.Request{}
+	}
+
 	var ingressList networkingv1.IngressList
-	if err := r.List(ctx, &ingressList, client.InNamespace(secret.GetNamespace())); err != nil {
+	if err := r.List(ctx, &ingressList, client.InNamespace(secret.GetNamespace()),
+		client.MatchingFields{ingressSecretIndex: secret.GetName()}); err != nil {
 		logger.Error(err, "failed to list ingresses", "namespace", secret.GetNamespace())
 		return []reconcile.Request{}
 	}
 
-	var requests []reconcile.Request
+	requests := make([]reconcile.Request, 0, len(ingressList.Items))
 	for _, ingress := range ingressList.Items {
-		for _, tls := range ingress.Spec.TLS {
-			if tls.SecretName == secret.GetName() {
-				requests = append(requests, reconcile.Request{
-					NamespacedName: client.ObjectKeyFromObject(&ingress),
-				})
-				logger.V(1).Info("secret change triggers ingress reconciliation",
-					"secret", secret.GetName(),
-					"ingress", ingress.Name,
-					"namespace", ingress.Namespace)
-				break
-			}
+		if !r.matchesSelector(&ingress) {
+			continue
 		}
+		requests = append(requests, reconcile.Request{
+			NamespacedName: client.ObjectKeyFromObject(&ingress),
+		})
+	}
+	if len(requests) > 0 {
+		logger.V(1).Info("secret change triggers ingress reconciliation",
+			"namespace", secret.GetNamespace(),
+			"secret", secret.GetName(),
+			"ingresses", len(requests))
 	}
 
 	return requests
 }
 
+// recordSecretEvent counts a secret event that enqueued ingress reconciles
+func (r *IngressReconciler) recordSecretEvent() {
+	r.Stats.RecordReconcile(stats.KindSecret)
+}
+
 // SetupWithManager sets up the controller with the Manager
 func (r *IngressReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.Background(), &networkingv1.Ingress{},
+		ingressSecretIndex, ingressSecretNames); err != nil {
+		return fmt.Errorf("failed to index ingresses by TLS secret: %w", err)
+	}
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&networkingv1.Ingress{}).
+		For(&networkingv1.Ingress{}, builder.WithPredicates(r.selectorPredicate())).
 		Watches(
 			&corev1.Secret{},
-			handler.EnqueueRequestsFromMapFunc(r.findIngressesForSecret),
+			r.RateLimit.enqueueSecretRequests(r.findIngressesForSecret, r.recordSecretEvent),
 		).
+		WithOptions(cacheControllerOptions(r.RateLimit)).
 		Complete(r)
 }
+
+// cacheControllerOptions are the options of controllers that only fill the
+// local cache. They run on every replica, not just the leader, so that a
+// replica taking over leadership reports from a warm cache.
+func cacheControllerOptions(rateLimit RateLimit) controller.Options {
+	return controller.Options{
+		NeedLeaderElection: ptr.To(false),
+		RateLimiter:        rateLimit.rateLimiter(),
+	}
+}
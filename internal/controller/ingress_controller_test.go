@@ -0,0 +1,756 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"os"
+	"regexp"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
+)
+
+var _ = Describe("Ingress Controller", func() {
+	Context("When a label selector is configured", func() {
+		const ingressName = "selector-ingress"
+
+		ctx := context.Background()
+
+		typeNamespacedName := types.NamespacedName{
+			Name:      ingressName,
+			Namespace: "default",
+		}
+
+		BeforeEach(func() {
+			By("creating an ingress carrying the observe label")
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      ingressName,
+					Namespace: "default",
+					Labels:    map[string]string{"observe": "true"},
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{{Host: "selector.local"}},
+				},
+			}
+			Expect(k8sClient.Create(ctx, ingress)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ingress)).To(Succeed())
+			Expect(k8sClient.Delete(ctx, ingress)).To(Succeed())
+		})
+
+		It("should evict an ingress that is relabeled to no longer match", func() {
+			ingressCache := cache.NewIngressCache("test-cluster")
+			controllerReconciler := &IngressReconciler{
+				Client:   k8sClient,
+				Scheme:   k8sClient.Scheme(),
+				Cache:    ingressCache,
+				Selector: labels.SelectorFromSet(labels.Set{"observe": "true"}),
+			}
+
+			By("Reconciling the matching ingress")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ingressCache.GetAll()).To(HaveLen(1))
+
+			By("Removing the observe label")
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sClient.Get(ctx, typeNamespacedName, ingress)).To(Succeed())
+			ingress.Labels = map[string]string{"observe": "false"}
+			Expect(k8sClient.Update(ctx, ingress)).To(Succeed())
+
+			By("Reconciling the relabeled ingress")
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: typeNamespacedName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ingressCache.GetAll()).To(BeEmpty())
+		})
+	})
+
+	Context("When counting backing secrets", func() {
+		ctx := context.Background()
+
+		DescribeTable("should record the number of distinct TLS secrets",
+			func(tls []networkingv1.IngressTLS, want int) {
+				ingress := &networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{Name: "secrets-ingress", Namespace: "default"},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{Host: "a.local"}, {Host: "b.local"}, {Host: "c.local"}},
+						TLS:   tls,
+					},
+				}
+				ingressCache := cache.NewIngressCache("test-cluster")
+				controllerReconciler := &IngressReconciler{
+					Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ingress).Build(),
+					Scheme: clientgoscheme.Scheme,
+					Cache:  ingressCache,
+				}
+
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+					NamespacedName: types.NamespacedName{Name: "secrets-ingress", Namespace: "default"},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				entries := ingressCache.GetAll()
+				Expect(entries).To(HaveLen(1))
+				Expect(entries[0].SecretCount).To(Equal(want))
+				for _, host := range entries[0].Hosts {
+					if host.Certificate != nil {
+						Expect(host.Certificate.SecretNamespace).To(Equal("default"))
+					}
+				}
+			},
+			Entry("no TLS", nil, 0),
+			Entry("one secret", []networkingv1.IngressTLS{
+				{Hosts: []string{"a.local", "b.local"}, SecretName: "shared-tls"},
+			}, 1),
+			Entry("several secrets", []networkingv1.IngressTLS{
+				{Hosts: []string{"a.local"}, SecretName: "a-tls"},
+				{Hosts: []string{"b.local"}, SecretName: "b-tls"},
+				{Hosts: []string{"c.local"}, SecretName: "a-tls"},
+			}, 2),
+		)
+	})
+
+	Context("When a secret naming convention is configured", func() {
+		It("should flag secrets whose name doesn't match", func() {
+			ctx := context.Background()
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "naming-ingress", Namespace: "default"},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{{Host: "shop.local"}, {Host: "legacy.local"}},
+					TLS: []networkingv1.IngressTLS{
+						{Hosts: []string{"shop.local"}, SecretName: "shop-tls"},
+						{Hosts: []string{"legacy.local"}, SecretName: "legacy-cert"},
+					},
+				},
+			}
+			ingressCache := cache.NewIngressCache("test-cluster")
+			controllerReconciler := &IngressReconciler{
+				Client:      fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ingress).Build(),
+				Scheme:      clientgoscheme.Scheme,
+				Cache:       ingressCache,
+				SecretNames: cache.NewSecretNameConvention(regexp.MustCompile(`^[a-z0-9-]+-tls$`)),
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "naming-ingress", Namespace: "default"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			nonconforming := make(map[string]bool)
+			for _, host := range ingressCache.GetAll()[0].Hosts {
+				nonconforming[host.Certificate.Name] = host.Certificate.NonconformingName
+			}
+			Expect(nonconforming).To(Equal(map[string]bool{"shop-tls": false, "legacy-cert": true}))
+			Expect(ingressCache.NonconformingCertificateCount()).To(Equal(1))
+		})
+	})
+
+	Context("When certificates are stored in Opaque secrets", func() {
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "opaque-ingress", Namespace: "default"}
+
+		DescribeTable("should only scan them when enabled",
+			func(scan bool, wantParsed map[string]bool) {
+				certData, err := os.ReadFile("../certutil/testdata/webapp-cert.pem")
+				Expect(err).NotTo(HaveOccurred())
+
+				ingress := &networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{Host: "shop.local"}, {Host: "creds.local"}},
+						TLS: []networkingv1.IngressTLS{
+							{Hosts: []string{"shop.local"}, SecretName: "shop-pem"},
+							{Hosts: []string{"creds.local"}, SecretName: "credentials"},
+						},
+					},
+				}
+				withCert := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "shop-pem", Namespace: "default"},
+					Type:       corev1.SecretTypeOpaque,
+					Data:       map[string][]byte{"cert.pem": certData},
+				}
+				withoutCert := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "credentials", Namespace: "default"},
+					Type:       corev1.SecretTypeOpaque,
+					Data:       map[string][]byte{"password": []byte("secret")},
+				}
+				ingressCache := cache.NewIngressCache("test-cluster")
+				controllerReconciler := &IngressReconciler{
+					Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+						WithObjects(ingress, withCert, withoutCert).Build(),
+					Scheme:            clientgoscheme.Scheme,
+					Cache:             ingressCache,
+					ScanOpaqueSecrets: scan,
+				}
+
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+
+				parsed := make(map[string]bool)
+				for _, host := range ingressCache.GetAll()[0].Hosts {
+					parsed[host.Certificate.Name] = host.Certificate.Expires != nil
+				}
+				Expect(parsed).To(Equal(wantParsed))
+			},
+			Entry("disabled", false, map[string]bool{"shop-pem": false, "credentials": false}),
+			Entry("enabled", true, map[string]bool{"shop-pem": true, "credentials": false}),
+		)
+	})
+
+	Context("When secrets carry a CA bundle in ca.crt", func() {
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "mtls-ingress", Namespace: "default"}
+
+		DescribeTable("should only report the CA certificates when enabled",
+			func(readCA bool, wantCAs []string) {
+				certData, err := os.ReadFile("../certutil/testdata/webapp-cert.pem")
+				Expect(err).NotTo(HaveOccurred())
+				caData, err := os.ReadFile("../certutil/testdata/intermediates-only.pem")
+				Expect(err).NotTo(HaveOccurred())
+
+				ingress := &networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{Host: "mtls.local"}},
+						TLS:   []networkingv1.IngressTLS{{Hosts: []string{"mtls.local"}, SecretName: "mtls-tls"}},
+					},
+				}
+				secret := &corev1.Secret{
+					ObjectMeta: metav1.ObjectMeta{Name: "mtls-tls", Namespace: "default"},
+					Type:       corev1.SecretTypeTLS,
+					Data:       map[string][]byte{corev1.TLSCertKey: certData, "ca.crt": caData},
+				}
+				ingressCache := cache.NewIngressCache("test-cluster")
+				controllerReconciler := &IngressReconciler{
+					Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+						WithObjects(ingress, secret).Build(),
+					Scheme:             clientgoscheme.Scheme,
+					Cache:              ingressCache,
+					ReadCACertificates: readCA,
+				}
+
+				_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+
+				cert := ingressCache.GetAll()[0].Hosts[0].Certificate
+				Expect(cert.Expires).NotTo(BeNil())
+				var cas []string
+				for _, ca := range cert.CACertificates {
+					Expect(ca.Expires).NotTo(BeNil())
+					cas = append(cas, ca.Name)
+				}
+				Expect(cas).To(Equal(wantCAs))
+			},
+			Entry("disabled", false, nil),
+			Entry("enabled", true, []string{"CN=Example Intermediate CA", "CN=Example Root CA"}),
+		)
+
+		It("should report a broken bundle as a parse error", func() {
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: networkingv1.IngressSpec{
+					TLS: []networkingv1.IngressTLS{{Hosts: []string{"mtls.local"}, SecretName: "mtls-tls"}},
+				},
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "mtls-tls", Namespace: "default"},
+				Type:       corev1.SecretTypeOpaque,
+				Data:       map[string][]byte{"ca.crt": []byte("not a certificate")},
+			}
+			ingressCache := cache.NewIngressCache("test-cluster")
+			controllerReconciler := &IngressReconciler{
+				Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+					WithObjects(ingress, secret).Build(),
+				Scheme:             clientgoscheme.Scheme,
+				Cache:              ingressCache,
+				ReadCACertificates: true,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			cas := ingressCache.GetAll()[0].Hosts[0].Certificate.CACertificates
+			Expect(cas).To(HaveLen(1))
+			Expect(cas[0].Name).To(Equal("ca.crt"))
+			Expect(cas[0].State).To(Equal(cache.CertificateStateParseError))
+			Expect(cas[0].ParseError).NotTo(BeEmpty())
+		})
+	})
+
+	Context("When TLS entries list wildcard hosts", func() {
+		It("should link rule hosts to the covering wildcard certificate", func() {
+			ctx := context.Background()
+			key := types.NamespacedName{Name: "wildcard-ingress", Namespace: "default"}
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{Host: "foo.apps.example.com"},
+						{Host: "BAR.Apps.Example.com"},
+						{Host: "exact.apps.example.com"},
+						{Host: "a.b.apps.example.com"},
+						{Host: "apps.example.com"},
+					},
+					TLS: []networkingv1.IngressTLS{
+						{Hosts: []string{"*.apps.example.com"}, SecretName: "wildcard-tls"},
+						{Hosts: []string{"exact.apps.example.com"}, SecretName: "exact-tls"},
+					},
+				},
+			}
+			ingressCache := cache.NewIngressCache("test-cluster")
+			controllerReconciler := &IngressReconciler{
+				Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ingress).Build(),
+				Scheme: clientgoscheme.Scheme,
+				Cache:  ingressCache,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			secrets := make(map[string]string)
+			for _, host := range ingressCache.GetAll()[0].Hosts {
+				secrets[host.Host] = ""
+				if host.Certificate != nil {
+					secrets[host.Host] = host.Certificate.Name
+				}
+			}
+			Expect(secrets).To(Equal(map[string]string{
+				// A wildcard covers a single label
+				"foo.apps.example.com": "wildcard-tls",
+				"BAR.Apps.Example.com": "wildcard-tls",
+				// An exact entry takes precedence over the wildcard
+				"exact.apps.example.com": "exact-tls",
+				// Neither nested labels nor the apex are covered
+				"a.b.apps.example.com": "",
+				"apps.example.com":     "",
+			}))
+			Expect(ingressCache.PlaintextHostCount()).To(Equal(2))
+		})
+	})
+
+	Context("When auditing TLS termination and redirects", func() {
+		reconcileHosts := func(ingress *networkingv1.Ingress) map[string]cache.HostInfo {
+			ingressCache := cache.NewIngressCache("test-cluster")
+			controllerReconciler := &IngressReconciler{
+				Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ingress).Build(),
+				Scheme: clientgoscheme.Scheme,
+				Cache:  ingressCache,
+			}
+			_, err := controllerReconciler.Reconcile(context.Background(), reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: ingress.Namespace, Name: ingress.Name},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			hosts := make(map[string]cache.HostInfo)
+			for _, host := range ingressCache.GetAll()[0].Hosts {
+				hosts[host.Host] = host
+			}
+			return hosts
+		}
+
+		newAuditIngress := func(annotations map[string]string) *networkingv1.Ingress {
+			return &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: "audit-ingress", Namespace: "default", Annotations: annotations},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{
+						{Host: "secure.example.com"},
+						{Host: "default-cert.example.com"},
+						{Host: "wild.apps.example.com"},
+						{Host: "plain.example.com"},
+					},
+					TLS: []networkingv1.IngressTLS{
+						{Hosts: []string{"secure.example.com"}, SecretName: "secure-tls"},
+						// Served with the ingress controller's default certificate
+						{Hosts: []string{"default-cert.example.com", "*.apps.example.com"}},
+					},
+				},
+			}
+		}
+
+		It("should mark hosts with a TLS entry as TLS enabled", func() {
+			hosts := reconcileHosts(newAuditIngress(nil))
+
+			Expect(hosts["secure.example.com"].TLSEnabled).To(BeTrue())
+			Expect(hosts["default-cert.example.com"].TLSEnabled).To(BeTrue())
+			Expect(hosts["default-cert.example.com"].Certificate).To(BeNil())
+			Expect(hosts["wild.apps.example.com"].TLSEnabled).To(BeTrue())
+		})
+
+		It("should leave hosts without a TLS entry unmarked", func() {
+			hosts := reconcileHosts(newAuditIngress(nil))
+
+			Expect(hosts["plain.example.com"].TLSEnabled).To(BeFalse())
+			Expect(hosts["plain.example.com"].Certificate).To(BeNil())
+			for _, host := range hosts {
+				Expect(host.SSLRedirect).To(BeFalse())
+			}
+		})
+
+		It("should flag hosts of ingresses with a redirect annotation", func() {
+			for _, annotations := range []map[string]string{
+				{"nginx.ingress.kubernetes.io/ssl-redirect": "true"},
+				{"nginx.ingress.kubernetes.io/force-ssl-redirect": "True"},
+				{"alb.ingress.kubernetes.io/ssl-redirect": "443"},
+			} {
+				hosts := reconcileHosts(newAuditIngress(annotations))
+				Expect(hosts["secure.example.com"].SSLRedirect).To(BeTrue(), "annotations %v", annotations)
+				Expect(hosts["plain.example.com"].SSLRedirect).To(BeTrue(), "annotations %v", annotations)
+			}
+
+			hosts := reconcileHosts(newAuditIngress(map[string]string{"nginx.ingress.kubernetes.io/ssl-redirect": "false"}))
+			Expect(hosts["secure.example.com"].SSLRedirect).To(BeFalse())
+		})
+	})
+
+	Context("When a certificate can't be parsed", func() {
+		It("should record the parse error on the certificate", func() {
+			ctx := context.Background()
+			key := types.NamespacedName{Name: "parse-error-ingress", Namespace: "default"}
+			certData, err := os.ReadFile("../certutil/testdata/webapp-cert.pem")
+			Expect(err).NotTo(HaveOccurred())
+
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{{Host: "shop.local"}, {Host: "broken.local"}, {Host: "missing.local"}},
+					TLS: []networkingv1.IngressTLS{
+						{Hosts: []string{"shop.local"}, SecretName: "shop-tls"},
+						{Hosts: []string{"broken.local"}, SecretName: "broken-tls"},
+						{Hosts: []string{"missing.local"}, SecretName: "missing-tls"},
+					},
+				},
+			}
+			valid := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "shop-tls", Namespace: "default"},
+				Type:       corev1.SecretTypeTLS,
+				Data:       map[string][]byte{corev1.TLSCertKey: certData},
+			}
+			malformed := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "broken-tls", Namespace: "default"},
+				Type:       corev1.SecretTypeTLS,
+				Data: map[string][]byte{corev1.TLSCertKey: []byte(
+					"-----BEGIN CERTIFICATE-----\nbm90IGEgY2VydGlmaWNhdGU=\n-----END CERTIFICATE-----\n")},
+			}
+			recorder := stats.NewRecorder()
+			ingressCache := cache.NewIngressCache("test-cluster")
+			controllerReconciler := &IngressReconciler{
+				Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+					WithObjects(ingress, valid, malformed).Build(),
+				Scheme: clientgoscheme.Scheme,
+				Cache:  ingressCache,
+				Stats:  recorder,
+			}
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+
+			certs := make(map[string]*cache.CertificateInfo)
+			for _, host := range ingressCache.GetAll()[0].Hosts {
+				certs[host.Certificate.Name] = host.Certificate
+			}
+			Expect(certs["shop-tls"].Expires).NotTo(BeNil())
+			Expect(certs["shop-tls"].ParseError).To(BeEmpty())
+			Expect(certs["broken-tls"].Expires).To(BeNil())
+			Expect(certs["broken-tls"].ParseError).To(ContainSubstring("failed to parse certificate"))
+			// A missing secret is not a parse error
+			Expect(certs["missing-tls"].Expires).To(BeNil())
+			Expect(certs["missing-tls"].ParseError).To(BeEmpty())
+			Expect(recorder.ParseErrors()).To(Equal(1))
+		})
+	})
+
+	Context("When reporting the state of referenced secrets", func() {
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "state-ingress", Namespace: "default"}
+
+		DescribeTable("should tell why a certificate has no expiry",
+			func(secret *corev1.Secret, getErr error, wantState string) {
+				ingress := &networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+					Spec: networkingv1.IngressSpec{
+						Rules: []networkingv1.IngressRule{{Host: "shop.local"}},
+						TLS:   []networkingv1.IngressTLS{{Hosts: []string{"shop.local"}, SecretName: "shop-tls"}},
+					},
+				}
+				builder := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ingress)
+				if secret != nil {
+					builder = builder.WithObjects(secret)
+				}
+				if getErr != nil {
+					builder = builder.WithInterceptorFuncs(interceptor.Funcs{
+						Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object,
+							opts ...client.GetOption) error {
+							if _, ok := obj.(*corev1.Secret); ok {
+								return getErr
+							}
+							return c.Get(ctx, key, obj, opts...)
+						},
+					})
+				}
+				ingressCache := cache.NewIngressCache("test-cluster")
+				controllerReconciler := &IngressReconciler{
+					Client: builder.Build(),
+					Scheme: clientgoscheme.Scheme,
+					Cache:  ingressCache,
+				}
+
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+				cert := ingressCache.GetAll()[0].Hosts[0].Certificate
+				Expect(cert.Name).To(Equal("shop-tls"))
+				Expect(cert.State).To(Equal(wantState))
+				Expect(cert.Expires != nil).To(Equal(wantState == cache.CertificateStateOK))
+			},
+			Entry("parsed certificate", tlsSecret("shop-tls", readFixture("webapp-cert.pem")), nil,
+				cache.CertificateStateOK),
+			Entry("missing secret", nil, nil, cache.CertificateStateMissingSecret),
+			Entry("secret without tls.crt", &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "shop-tls", Namespace: "default"},
+				Type:       corev1.SecretTypeOpaque,
+				Data:       map[string][]byte{"password": []byte("secret")},
+			}, nil, cache.CertificateStateNoCertificate),
+			Entry("malformed certificate", tlsSecret("shop-tls", []byte("not a certificate")), nil,
+				cache.CertificateStateParseError),
+			Entry("forbidden secret", nil,
+				apierrors.NewForbidden(corev1.Resource("secrets"), "shop-tls", errors.New("denied")),
+				cache.CertificateStateUnreadableSecret),
+		)
+	})
+
+	Context("When ingresses use IP literals or no hosts", func() {
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "ip-ingress", Namespace: "default"}
+
+		DescribeTable("should record hosts as served",
+			func(spec networkingv1.IngressSpec, want []cache.HostInfo) {
+				ingress := &networkingv1.Ingress{
+					ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+					Spec:       spec,
+				}
+				ingressCache := cache.NewIngressCache("test-cluster")
+				controllerReconciler := &IngressReconciler{
+					Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+						WithObjects(ingress, tlsSecret("ip-tls", readFixture("webapp-cert.pem"))).Build(),
+					Scheme: clientgoscheme.Scheme,
+					Cache:  ingressCache,
+				}
+
+				_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+				Expect(err).NotTo(HaveOccurred())
+				hosts := ingressCache.GetAll()[0].Hosts
+				Expect(hosts).To(HaveLen(len(want)))
+				for i, host := range hosts {
+					Expect(host.Host).To(Equal(want[i].Host))
+					Expect(host.NoHost).To(Equal(want[i].NoHost))
+					Expect(host.Certificate != nil).To(Equal(want[i].Certificate != nil))
+				}
+			},
+			Entry("IPv4 literal", networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{Host: "192.0.2.10"}},
+				TLS:   []networkingv1.IngressTLS{{Hosts: []string{"192.0.2.10"}, SecretName: "ip-tls"}},
+			}, []cache.HostInfo{{Host: "192.0.2.10", Certificate: &cache.CertificateInfo{}}}),
+			Entry("IPv6 literal", networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{Host: "2001:DB8:0:0::1"}},
+				TLS:   []networkingv1.IngressTLS{{Hosts: []string{"2001:db8::1"}, SecretName: "ip-tls"}},
+			}, []cache.HostInfo{{Host: "2001:db8::1", Certificate: &cache.CertificateInfo{}}}),
+			Entry("bracketed IPv6 literal", networkingv1.IngressSpec{
+				Rules: []networkingv1.IngressRule{{Host: "[2001:db8::1]"}},
+				TLS:   []networkingv1.IngressTLS{{Hosts: []string{"2001:db8::1"}, SecretName: "ip-tls"}},
+			}, []cache.HostInfo{{Host: "2001:db8::1", Certificate: &cache.CertificateInfo{}}}),
+			Entry("bracketed IPv6 TLS host", networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{"[2001:db8::1]"}, SecretName: "ip-tls"}},
+			}, []cache.HostInfo{{Host: "2001:db8::1", Certificate: &cache.CertificateInfo{}}}),
+			Entry("no hosts", networkingv1.IngressSpec{
+				DefaultBackend: &networkingv1.IngressBackend{
+					Service: &networkingv1.IngressServiceBackend{Name: "web"},
+				},
+			}, []cache.HostInfo{{NoHost: true}}),
+			Entry("no hosts with IP-based TLS", networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{SecretName: "ip-tls"}},
+			}, []cache.HostInfo{{NoHost: true, Certificate: &cache.CertificateInfo{}}}),
+		)
+
+		It("should normalize IP literals and leave other hosts alone", func() {
+			Expect(normalizeHost("192.0.2.10")).To(Equal("192.0.2.10"))
+			Expect(normalizeHost(" [2001:DB8::0:1] ")).To(Equal("2001:db8::1"))
+			Expect(normalizeHost("::ffff:192.0.2.10")).To(Equal("::ffff:192.0.2.10"))
+			Expect(normalizeHost("Shop.Example.com")).To(Equal("Shop.Example.com"))
+			Expect(normalizeHost("[not-an-ip]")).To(Equal("[not-an-ip]"))
+			Expect(normalizeHost("")).To(BeEmpty())
+		})
+	})
+
+	Context("When the secret is created after the ingress", func() {
+		It("should fully enrich the placeholder certificate", func() {
+			ctx := context.Background()
+			key := types.NamespacedName{Name: "late-secret-ingress", Namespace: "default"}
+			ingress := &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{{Host: "shop.example.com"}},
+					TLS:   []networkingv1.IngressTLS{{Hosts: []string{"shop.example.com"}, SecretName: "shop-tls"}},
+				},
+			}
+			ingressCache := cache.NewIngressCache("test-cluster")
+			controllerReconciler := &IngressReconciler{
+				Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(ingress).
+					WithIndex(&networkingv1.Ingress{}, ingressSecretIndex, ingressSecretNames).Build(),
+				Scheme:      clientgoscheme.Scheme,
+				Cache:       ingressCache,
+				ACMEIssuers: cache.NewACMEIssuers(cache.DefaultACMEIssuers),
+			}
+
+			By("caching the ingress with a placeholder before the secret exists")
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			placeholder := ingressCache.GetAll()[0].Hosts[0].Certificate
+			Expect(placeholder.Name).To(Equal("shop-tls"))
+			Expect(placeholder.Expires).To(BeNil())
+			Expect(placeholder.SecretResourceVersion).To(BeEmpty())
+			Expect(placeholder.SecretObservedAt).To(BeZero())
+
+			By("creating the secret")
+			certData, err := os.ReadFile("../certutil/testdata/letsencrypt.pem")
+			Expect(err).NotTo(HaveOccurred())
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "shop-tls", Namespace: "default"},
+				Type:       corev1.SecretTypeTLS,
+				Data:       map[string][]byte{corev1.TLSCertKey: certData},
+			}
+			Expect(controllerReconciler.Create(ctx, secret)).To(Succeed())
+
+			By("reconciling the ingresses the secret's create event maps to")
+			requests := controllerReconciler.findIngressesForSecret(ctx, secret)
+			Expect(requests).To(Equal([]reconcile.Request{{NamespacedName: key}}))
+			for _, req := range requests {
+				_, err := controllerReconciler.Reconcile(ctx, req)
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			cert := ingressCache.GetAll()[0].Hosts[0].Certificate
+			Expect(cert.Expires).NotTo(BeNil())
+			Expect(cert.Issuer).To(Equal("CN=R3,O=Let's Encrypt,C=US"))
+			Expect(cert.DNSNames).To(Equal([]string{"shop.example.com"}))
+			Expect(cert.Fingerprint).NotTo(BeEmpty())
+			Expect(cert.SerialNumber).NotTo(BeEmpty())
+			Expect(cert.ChainLength).To(Equal(1))
+			Expect(cert.FirstObservedAt).NotTo(BeZero())
+			Expect(cert.IsACME).To(BeTrue())
+			Expect(cert.ACMEProvider).To(Equal("letsencrypt"))
+			Expect(cert.SecretResourceVersion).To(Equal(secret.ResourceVersion))
+			Expect(cert.SecretResourceVersion).NotTo(BeEmpty())
+			Expect(cert.SecretObservedAt).NotTo(BeZero())
+		})
+	})
+
+	Context("When an ingress loses its matching label", func() {
+		ctx := context.Background()
+		key := types.NamespacedName{Name: "relabeled", Namespace: "default"}
+		selector := labels.SelectorFromSet(labels.Set{"observe": "true"})
+
+		newIngress := func(observe string) *networkingv1.Ingress {
+			return &networkingv1.Ingress{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      key.Name,
+					Namespace: key.Namespace,
+					Labels:    map[string]string{"observe": observe},
+				},
+				Spec: networkingv1.IngressSpec{
+					Rules: []networkingv1.IngressRule{{Host: "relabeled.local"}},
+				},
+			}
+		}
+
+		It("should evict the cached entry on reconcile", func() {
+			k8sFake := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(newIngress("true")).Build()
+			ingressCache := cache.NewIngressCache("test-cluster")
+			controllerReconciler := &IngressReconciler{
+				Client:   k8sFake,
+				Scheme:   clientgoscheme.Scheme,
+				Cache:    ingressCache,
+				Selector: selector,
+			}
+
+			_, err := controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ingressCache.GetAll()).To(HaveLen(1))
+
+			By("Toggling the observe label off")
+			ingress := &networkingv1.Ingress{}
+			Expect(k8sFake.Get(ctx, key, ingress)).To(Succeed())
+			ingress.Labels["observe"] = "false"
+			Expect(k8sFake.Update(ctx, ingress)).To(Succeed())
+
+			_, err = controllerReconciler.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ingressCache.GetAll()).To(BeEmpty())
+		})
+
+		It("should pass update events where the label is removed", func() {
+			pred := (&IngressReconciler{Selector: selector}).selectorPredicate()
+
+			Expect(pred.Update(event.UpdateEvent{
+				ObjectOld: newIngress("true"), ObjectNew: newIngress("false"),
+			})).To(BeTrue(), "label removed")
+			Expect(pred.Update(event.UpdateEvent{
+				ObjectOld: newIngress("false"), ObjectNew: newIngress("true"),
+			})).To(BeTrue(), "label added")
+			Expect(pred.Update(event.UpdateEvent{
+				ObjectOld: newIngress("false"), ObjectNew: newIngress("no"),
+			})).To(BeFalse(), "never matching")
+			Expect(pred.Create(event.CreateEvent{Object: newIngress("false")})).To(BeTrue())
+		})
+	})
+})
+
+// tlsSecret returns a TLS secret in the default namespace holding the
+// certificate data under tls.crt
+func tlsSecret(name string, certData []byte) *corev1.Secret {
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{corev1.TLSCertKey: certData},
+	}
+}
+
+// readFixture reads a certificate fixture of the certutil package
+func readFixture(name string) []byte {
+	data, err := os.ReadFile("../certutil/testdata/" + name)
+	Expect(err).NotTo(HaveOccurred())
+	return data
+}
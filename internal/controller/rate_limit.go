@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// DefaultRateLimit is the work queue rate limit of the ingress and gateway
+// controllers. A secret shared by hundreds of ingresses fans out to a burst
+// of reconciles, which the bucket lets through at first and then spreads
+// out. The backoff is capped well below controller-runtime's 1000s so a
+// failing entry doesn't stay stale for long.
+var DefaultRateLimit = RateLimit{
+	QPS:            20,
+	Burst:          200,
+	BaseDelay:      5 * time.Millisecond,
+	MaxDelay:       5 * time.Minute,
+	SecretDebounce: 2 * time.Second,
+}
+
+// RateLimit configures the work queue of a controller observing secrets.
+// The zero value keeps controller-runtime's defaults and doesn't debounce.
+type RateLimit struct {
+	// QPS and Burst bound the overall rate of reconciles
+	QPS   float64
+	Burst int
+	// BaseDelay and MaxDelay bound the per-item exponential backoff of
+	// failed reconciles
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+	// SecretDebounce delays the reconciles triggered by a secret event, so
+	// events arriving in quick succession, e.g. a cert-manager renewal
+	// updating a secret several times, collapse into one reconcile per
+	// resource. 0 enqueues them right away.
+	SecretDebounce time.Duration
+}
+
+// Validate checks that a non-zero rate limit can be enforced
+func (l RateLimit) Validate() error {
+	if l == (RateLimit{}) {
+		return nil
+	}
+	switch {
+	case l.QPS <= 0:
+		return fmt.Errorf("reconcile QPS must be positive, got %v", l.QPS)
+	case l.Burst < 1:
+		return fmt.Errorf("reconcile burst must be at least 1, got %d", l.Burst)
+	case l.BaseDelay <= 0 || l.MaxDelay < l.BaseDelay:
+		return fmt.Errorf("reconcile backoff must be positive with a maximum of at least %s, got %s",
+			l.BaseDelay, l.MaxDelay)
+	case l.SecretDebounce < 0:
+		return fmt.Errorf("secret event debounce must not be negative, got %s", l.SecretDebounce)
+	}
+	return nil
+}
+
+// rateLimiter returns the work queue rate limiter, nil for the zero value
+// so that controller-runtime's default is used
+func (l RateLimit) rateLimiter() workqueue.TypedRateLimiter[reconcile.Request] {
+	if l == (RateLimit{}) {
+		return nil
+	}
+	return workqueue.NewTypedMaxOfRateLimiter(
+		workqueue.NewTypedItemExponentialFailureRateLimiter[reconcile.Request](l.BaseDelay, l.MaxDelay),
+		&workqueue.TypedBucketRateLimiter[reconcile.Request]{Limiter: rate.NewLimiter(rate.Limit(l.QPS), l.Burst)},
+	)
+}
+
+// enqueueSecretRequests returns the handler for secret events, enqueueing
+// the requests mapFn returns after the debounce. The work queue merges
+// requests for the same resource while they wait, so a burst of events
+// results in a single reconcile. onEnqueue, when non-nil, is called once
+// for every event that enqueues any request.
+func (l RateLimit) enqueueSecretRequests(mapFn handler.MapFunc, onEnqueue func()) handler.EventHandler {
+	enqueue := func(ctx context.Context, q workqueue.TypedRateLimitingInterface[reconcile.Request],
+		objects ...client.Object) {
+		requests := make(map[reconcile.Request]struct{})
+		for _, obj := range objects {
+			for _, req := range mapFn(ctx, obj) {
+				requests[req] = struct{}{}
+			}
+		}
+		if len(requests) == 0 {
+			return
+		}
+		for req := range requests {
+			if l.SecretDebounce > 0 {
+				q.AddAfter(req, l.SecretDebounce)
+			} else {
+				q.Add(req)
+			}
+		}
+		if onEnqueue != nil {
+			onEnqueue()
+		}
+	}
+	return handler.Funcs{
+		CreateFunc: func(ctx context.Context, e event.CreateEvent,
+			q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		UpdateFunc: func(ctx context.Context, e event.UpdateEvent,
+			q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.ObjectOld, e.ObjectNew)
+		},
+		DeleteFunc: func(ctx context.Context, e event.DeleteEvent,
+			q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+		GenericFunc: func(ctx context.Context, e event.GenericEvent,
+			q workqueue.TypedRateLimitingInterface[reconcile.Request]) {
+			enqueue(ctx, q, e.Object)
+		},
+	}
+}
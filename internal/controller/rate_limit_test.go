@@ -0,0 +1,175 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
+)
+
+var _ = Describe("Secret event fan-out", func() {
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "shared-tls", Namespace: "fan-out"}}
+
+	newIngress := func(name, secretName string) client.Object {
+		return &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "fan-out"},
+			Spec: networkingv1.IngressSpec{
+				TLS: []networkingv1.IngressTLS{{Hosts: []string{name + ".example.com"}, SecretName: secretName}},
+			},
+		}
+	}
+
+	It("should map a secret only to the ingresses referencing it", func() {
+		var objects []client.Object
+		for i := range 200 {
+			objects = append(objects, newIngress(fmt.Sprintf("app-%d", i), fmt.Sprintf("app-%d-tls", i)))
+		}
+		for i := range 3 {
+			objects = append(objects, newIngress(fmt.Sprintf("shared-%d", i), "shared-tls"))
+		}
+		reconciler := &IngressReconciler{
+			Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(objects...).
+				WithIndex(&networkingv1.Ingress{}, ingressSecretIndex, ingressSecretNames).Build(),
+		}
+
+		Expect(reconciler.findIngressesForSecret(ctx, secret)).To(ConsistOf(
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "fan-out", Name: "shared-0"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "fan-out", Name: "shared-1"}},
+			reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "fan-out", Name: "shared-2"}},
+		))
+	})
+
+	It("should look up only the ingresses referencing a secret through the index", func() {
+		multi := &networkingv1.Ingress{
+			ObjectMeta: metav1.ObjectMeta{Name: "multi", Namespace: "fan-out"},
+			Spec: networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{
+				{Hosts: []string{"a.example.com"}, SecretName: "other-tls"},
+				{Hosts: []string{"b.example.com"}, SecretName: "shared-tls"},
+			}},
+		}
+		elsewhere := newIngress("elsewhere", "shared-tls")
+		elsewhere.SetNamespace("other")
+		indexed := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+			WithObjects(multi, elsewhere, newIngress("plain", ""), newIngress("unrelated", "unrelated-tls")).
+			WithIndex(&networkingv1.Ingress{}, ingressSecretIndex, ingressSecretNames).Build()
+
+		var ingresses networkingv1.IngressList
+		Expect(indexed.List(ctx, &ingresses, client.InNamespace("fan-out"),
+			client.MatchingFields{ingressSecretIndex: "shared-tls"})).To(Succeed())
+		Expect(ingresses.Items).To(HaveLen(1))
+		Expect(ingresses.Items[0].Name).To(Equal("multi"))
+	})
+
+	It("should index each TLS secret of an ingress once", func() {
+		ingress := &networkingv1.Ingress{Spec: networkingv1.IngressSpec{TLS: []networkingv1.IngressTLS{
+			{SecretName: "a-tls"}, {SecretName: "b-tls"}, {SecretName: "a-tls"}, {},
+		}}}
+		Expect(ingressSecretNames(ingress)).To(Equal([]string{"a-tls", "b-tls"}))
+	})
+
+	It("should collapse a burst of secret events into one reconcile per ingress", func() {
+		mapped := []reconcile.Request{
+			{NamespacedName: types.NamespacedName{Namespace: "fan-out", Name: "shared-0"}},
+			{NamespacedName: types.NamespacedName{Namespace: "fan-out", Name: "shared-1"}},
+		}
+		rateLimit := RateLimit{SecretDebounce: 50 * time.Millisecond}
+		handler := rateLimit.enqueueSecretRequests(func(_ context.Context, _ client.Object) []reconcile.Request {
+			return mapped
+		}, nil)
+		queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+		defer queue.ShutDown()
+
+		handler.Create(ctx, event.CreateEvent{Object: secret}, queue)
+		for range 5 {
+			handler.Update(ctx, event.UpdateEvent{ObjectOld: secret, ObjectNew: secret}, queue)
+		}
+		Expect(queue.Len()).To(BeZero(), "requests should wait for the debounce")
+
+		Eventually(queue.Len).WithTimeout(time.Second).Should(Equal(2))
+		Consistently(queue.Len).WithTimeout(100 * time.Millisecond).Should(Equal(2))
+	})
+
+	It("should enqueue secret events right away without a debounce", func() {
+		handler := RateLimit{}.enqueueSecretRequests(func(_ context.Context, _ client.Object) []reconcile.Request {
+			return []reconcile.Request{{NamespacedName: types.NamespacedName{Namespace: "fan-out", Name: "shared-0"}}}
+		}, nil)
+		queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+		defer queue.ShutDown()
+
+		handler.Create(ctx, event.CreateEvent{Object: secret}, queue)
+		Expect(queue.Len()).To(Equal(1))
+	})
+
+	It("should count each secret event that enqueues ingress reconciles once", func() {
+		reconciler := &IngressReconciler{
+			Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+				WithObjects(newIngress("shared-0", "shared-tls"), newIngress("shared-1", "shared-tls")).
+				WithIndex(&networkingv1.Ingress{}, ingressSecretIndex, ingressSecretNames).Build(),
+			Stats: stats.NewRecorder(),
+		}
+		handler := reconciler.RateLimit.enqueueSecretRequests(reconciler.findIngressesForSecret,
+			reconciler.recordSecretEvent)
+		queue := workqueue.NewTypedRateLimitingQueue(workqueue.DefaultTypedControllerRateLimiter[reconcile.Request]())
+		defer queue.ShutDown()
+
+		handler.Create(ctx, event.CreateEvent{Object: secret}, queue)
+		handler.Update(ctx, event.UpdateEvent{ObjectOld: secret, ObjectNew: secret}, queue)
+		unreferenced := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "unreferenced-tls", Namespace: "fan-out"}}
+		handler.Create(ctx, event.CreateEvent{Object: unreferenced}, queue)
+
+		Expect(queue.Len()).To(Equal(2))
+		Expect(reconciler.Stats.ReconcileCounts()).To(HaveKeyWithValue(stats.KindSecret, 2))
+	})
+
+	DescribeTable("should validate the rate limit",
+		func(rateLimit RateLimit, wantErr bool) {
+			err := rateLimit.Validate()
+			Expect(err != nil).To(Equal(wantErr), "Validate() error = %v", err)
+		},
+		Entry("zero value", RateLimit{}, false),
+		Entry("default", DefaultRateLimit, false),
+		Entry("zero QPS", RateLimit{Burst: 1, BaseDelay: time.Millisecond, MaxDelay: time.Second}, true),
+		Entry("zero burst", RateLimit{QPS: 1, BaseDelay: time.Millisecond, MaxDelay: time.Second}, true),
+		Entry("maximum backoff below base", RateLimit{QPS: 1, Burst: 1, BaseDelay: time.Second, MaxDelay: time.Millisecond}, true),
+		Entry("negative debounce", RateLimit{QPS: 1, Burst: 1, BaseDelay: time.Millisecond, MaxDelay: time.Second,
+			SecretDebounce: -time.Second}, true),
+	)
+
+	It("should keep controller-runtime's rate limiter for the zero value", func() {
+		Expect(RateLimit{}.rateLimiter()).To(BeNil())
+		limiter := DefaultRateLimit.rateLimiter()
+		request := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "fan-out", Name: "shared-0"}}
+		Expect(limiter.When(request)).To(Equal(DefaultRateLimit.BaseDelay))
+		Expect(limiter.When(request)).To(Equal(2 * DefaultRateLimit.BaseDelay))
+	})
+})
@@ -0,0 +1,133 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/certutil"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
+)
+
+// RouteGroupVersionKind is the OpenShift Route, read as unstructured so the
+// operator doesn't depend on the OpenShift API
+var RouteGroupVersionKind = schema.GroupVersionKind{Group: "route.openshift.io", Version: "v1", Kind: "Route"}
+
+// routeCertificatePrefix prefixes the names of inline route certificates.
+// Kubernetes names can't contain a colon, so they never collide with the
+// secret of the same name in the route's namespace.
+const routeCertificatePrefix = "route:"
+
+// RouteReconciler tracks the inline certificates of OpenShift Routes. Each
+// route becomes an entry with its host, carrying the certificate when the
+// route terminates TLS with one of its own.
+type RouteReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	Cache      *cache.IngressCache
+	Namespaces config.NamespaceFilter
+	// Stats records reconcile counts; nil disables recording
+	Stats *stats.Recorder
+	// Renewals tracks certificate renewals; nil disables tracking
+	Renewals *cache.RenewalHistory
+	// ACMEIssuers flags certificates issued by ACME authorities; nil disables the check
+	ACMEIssuers *cache.ACMEIssuers
+	// RateLimit configures the work queue; the zero value keeps
+	// controller-runtime's defaults
+	RateLimit RateLimit
+}
+
+// +kubebuilder:rbac:groups=route.openshift.io,resources=routes,verbs=get;list;watch
+
+// Reconcile caches a route's host and inline certificate, dropping the
+// route once it is deleted
+func (r *RouteReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	r.Stats.RecordReconcile(stats.KindRoute)
+
+	if !r.Namespaces.Allows(req.Namespace) {
+		r.Cache.DeleteKind(cache.KindRoute, req.Namespace, req.Name)
+		return ctrl.Result{}, nil
+	}
+
+	route := newRoute()
+	if err := r.Get(ctx, req.NamespacedName, route); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			r.Cache.DeleteKind(cache.KindRoute, req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "failed to get route")
+		return ctrl.Result{}, fmt.Errorf("failed to get route %s/%s: %w", req.Namespace, req.Name, err)
+	}
+
+	info := &cache.IngressInfo{
+		Kind:      cache.KindRoute,
+		Namespace: route.GetNamespace(),
+		Name:      route.GetName(),
+		Labels:    route.GetLabels(),
+	}
+	// Routes without a host get the placeholder entry, like ingresses
+	name, _, _ := unstructured.NestedString(route.Object, "spec", "host")
+	host := cache.HostInfo{Host: normalizeHost(name)}
+	host.NoHost = host.Host == ""
+	host.Certificate = r.inlineCertificate(ctx, route)
+	if host.Certificate != nil {
+		info.SecretCount = 1
+	}
+	info.Hosts = []cache.HostInfo{host}
+	r.Cache.Add(info)
+
+	logger.V(1).Info("successfully updated cache", "tls", host.Certificate != nil)
+	return ctrl.Result{}, nil
+}
+
+// inlineCertificate parses the route's spec.tls.certificate, named
+// route:<route name>. It returns nil for routes without TLS. TLS routes without an inline
+// certificate, e.g. served with the router's default certificate or passed
+// through to the backend, get certificate info without expiry.
+func (r *RouteReconciler) inlineCertificate(ctx context.Context, route *unstructured.Unstructured) *cache.CertificateInfo {
+	tls, ok, _ := unstructured.NestedMap(route.Object, "spec", "tls")
+	if !ok || tls == nil {
+		return nil
+	}
+	pem, _, _ := unstructured.NestedString(tls, "certificate")
+
+	now := time.Now()
+	certInfo, err := certutil.ParsePEM(routeCertificatePrefix+route.GetName(), []byte(pem))
+	certInfo.SecretResourceVersion = route.GetResourceVersion()
+	certInfo.SecretObservedAt = now
+	setCertificateState(certInfo, err)
+	r.Renewals.Observe(certInfo, route.GetNamespace(), now)
+	r.ACMEIssuers.Check(certInfo)
+	if err != nil && pem != "" {
+		r.Stats.RecordParseError()
+		log.FromContext(ctx).V(1).Info("failed to extract certificate expiry", "error", err.Error())
+	}
+	return certInfo
+}
+
+// newRoute returns an empty unstructured Route
+func newRoute() *unstructured.Unstructured {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(RouteGroupVersionKind)
+	return route
+}
+
+// SetupWithManager sets up the controller with the Manager. The Route API
+// must be installed, see RouteGroupVersionKind.
+func (r *RouteReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(newRoute()).
+		Named("route").
+		WithOptions(cacheControllerOptions(r.RateLimit)).
+		Complete(r)
+}
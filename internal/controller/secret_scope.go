@@ -0,0 +1,19 @@
+package controller
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	crcache "sigs.k8s.io/controller-runtime/pkg/cache"
+)
+
+// SecretWatchLabel is the label suggested for marking the secrets the
+// observer should watch when the secret informer is restricted by label
+const SecretWatchLabel = "cert-observer.io/watch"
+
+// SecretCacheConfig returns the informer settings for Secrets. A non-nil
+// selector restricts the informer, and with it every secret read through the
+// manager client, to matching secrets, so unrelated secrets aren't kept in
+// memory. Referenced secrets without the label then look like missing
+// secrets. A non-empty namespaces further limits the informer.
+func SecretCacheConfig(selector labels.Selector, namespaces map[string]crcache.Config) crcache.ByObject {
+	return crcache.ByObject{Namespaces: namespaces, Label: selector}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	crcache "sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/ugurcancaykara/cert-observer/internal/query"
+)
+
+var _ = Describe("Secret watch scope", func() {
+	const namespace = "secret-scope"
+
+	BeforeEach(func() {
+		ns := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}}
+		Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, ns))).To(Succeed())
+
+		for _, secret := range []*corev1.Secret{
+			{ObjectMeta: metav1.ObjectMeta{
+				Name:      "referenced-tls",
+				Namespace: namespace,
+				Labels:    map[string]string{SecretWatchLabel: "true"},
+			}},
+			{
+				ObjectMeta: metav1.ObjectMeta{Name: "unrelated-tls", Namespace: namespace},
+				Data:       map[string][]byte{corev1.TLSCertKey: readFixture("webapp-cert.pem")},
+			},
+		} {
+			secret.Type = corev1.SecretTypeOpaque
+			Expect(client.IgnoreAlreadyExists(k8sClient.Create(ctx, secret))).To(Succeed())
+		}
+	})
+
+	It("should only cache labeled secrets", func() {
+		selector := labels.SelectorFromSet(labels.Set{SecretWatchLabel: "true"})
+		informers, err := crcache.New(cfg, crcache.Options{
+			Scheme: scheme.Scheme,
+			ByObject: map[client.Object]crcache.ByObject{
+				&corev1.Secret{}: SecretCacheConfig(selector, nil),
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		cacheCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		go func() {
+			defer GinkgoRecover()
+			Expect(informers.Start(cacheCtx)).To(Succeed())
+		}()
+		Expect(informers.WaitForCacheSync(cacheCtx)).To(BeTrue())
+
+		var secrets corev1.SecretList
+		Expect(informers.List(ctx, &secrets, client.InNamespace(namespace))).To(Succeed())
+		Expect(secrets.Items).To(HaveLen(1))
+		Expect(secrets.Items[0].Name).To(Equal("referenced-tls"))
+
+		var unrelated corev1.Secret
+		err = informers.Get(ctx, types.NamespacedName{Namespace: namespace, Name: "unrelated-tls"}, &unrelated)
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should serve unlabeled secrets on the cert endpoint", func() {
+		selector := labels.SelectorFromSet(labels.Set{SecretWatchLabel: "true"})
+		informers, err := crcache.New(cfg, crcache.Options{
+			Scheme: scheme.Scheme,
+			ByObject: map[client.Object]crcache.ByObject{
+				&corev1.Secret{}: SecretCacheConfig(selector, nil),
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		cacheCtx, stop := context.WithCancel(ctx)
+		defer stop()
+		go func() {
+			defer GinkgoRecover()
+			Expect(informers.Start(cacheCtx)).To(Succeed())
+		}()
+		Expect(informers.WaitForCacheSync(cacheCtx)).To(BeTrue())
+
+		key := types.NamespacedName{Namespace: namespace, Name: "unrelated-tls"}
+		err = informers.Get(ctx, key, &corev1.Secret{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+
+		// The endpoint reads through the uncached client, like the manager's API reader
+		mux := http.NewServeMux()
+		mux.Handle(query.SecretCertPattern, query.NewSecretHandler(k8sClient, logr.Discard()))
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/secrets/"+namespace+"/unrelated-tls/cert", nil))
+		Expect(rec.Code).To(Equal(http.StatusOK), rec.Body.String())
+	})
+})
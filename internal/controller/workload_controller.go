@@ -0,0 +1,208 @@
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/certutil"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
+)
+
+// WatchSecretAnnotation names the secrets, comma-separated and in the
+// workload's namespace, whose certificates a Deployment or DaemonSet mounts
+// and the observer should track
+const WatchSecretAnnotation = "cert-observer.io/watch-secret"
+
+// WorkloadKinds are the workload kinds observed through WatchSecretAnnotation
+var WorkloadKinds = []string{cache.KindDeployment, cache.KindDaemonSet}
+
+// WorkloadReconciler tracks the certificates of the secrets named by the
+// WatchSecretAnnotation of one kind of workload. Only the workloads'
+// metadata is watched.
+type WorkloadReconciler struct {
+	client.Client
+	Scheme     *runtime.Scheme
+	Cache      *cache.IngressCache
+	Namespaces config.NamespaceFilter
+	// Kind is the workload kind, one of WorkloadKinds
+	Kind string
+	// Stats records reconcile counts; nil disables recording
+	Stats *stats.Recorder
+	// Renewals tracks certificate renewals; nil disables tracking
+	Renewals *cache.RenewalHistory
+	// SecretNames flags secrets breaking the naming convention; nil disables the check
+	SecretNames *cache.SecretNameConvention
+	// ACMEIssuers flags certificates issued by ACME authorities; nil disables the check
+	ACMEIssuers *cache.ACMEIssuers
+	// ScanOpaqueSecrets also looks for PEM certificates under any key of
+	// annotated Opaque secrets that lack all of CertificateKeys
+	ScanOpaqueSecrets bool
+	// CertificateKeys are the secret keys tried in order for the
+	// certificate; tls.crt when empty
+	CertificateKeys []string
+	// PKCS12 reads the certificate from a PKCS#12 keystore when an annotated
+	// secret holds one; nil disables keystores
+	PKCS12 *certutil.PKCS12
+	// ReadCACertificates also reports the certificates of the ca.crt bundle
+	// of annotated secrets
+	ReadCACertificates bool
+	// RateLimit configures the work queue; the zero value keeps
+	// controller-runtime's defaults
+	RateLimit RateLimit
+}
+
+// +kubebuilder:rbac:groups=apps,resources=deployments;daemonsets,verbs=get;list;watch
+
+// Reconcile caches the certificates of a workload's annotated secrets,
+// dropping the workload once it is deleted or loses the annotation
+func (r *WorkloadReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	r.Stats.RecordReconcile(stats.KindWorkload)
+
+	if !r.Namespaces.Allows(req.Namespace) {
+		r.Cache.DeleteKind(r.Kind, req.Namespace, req.Name)
+		return ctrl.Result{}, nil
+	}
+
+	workload := r.newObject()
+	if err := r.Get(ctx, req.NamespacedName, workload); err != nil {
+		if client.IgnoreNotFound(err) == nil {
+			r.Cache.DeleteKind(r.Kind, req.Namespace, req.Name)
+			return ctrl.Result{}, nil
+		}
+		logger.Error(err, "failed to get workload", "kind", r.Kind)
+		return ctrl.Result{}, fmt.Errorf("failed to get %s %s/%s: %w", r.Kind, req.Namespace, req.Name, err)
+	}
+
+	secrets := watchedSecrets(workload)
+	if len(secrets) == 0 {
+		r.Cache.DeleteKind(r.Kind, req.Namespace, req.Name)
+		return ctrl.Result{}, nil
+	}
+
+	// Each secret becomes a host entry without a host, since a mounted
+	// certificate isn't tied to one
+	info := &cache.IngressInfo{
+		Kind:        r.Kind,
+		Namespace:   workload.Namespace,
+		Name:        workload.Name,
+		Hosts:       make([]cache.HostInfo, 0, len(secrets)),
+		Labels:      workload.Labels,
+		SecretCount: len(secrets),
+	}
+	for _, name := range secrets {
+		certInfo := r.fetchCertificate(ctx, types.NamespacedName{Namespace: workload.Namespace, Name: name})
+		r.SecretNames.Check(certInfo)
+		r.ACMEIssuers.Check(certInfo)
+		info.Hosts = append(info.Hosts, cache.HostInfo{NoHost: true, Certificate: certInfo})
+	}
+	r.Cache.Add(info)
+
+	logger.V(1).Info("successfully updated cache", "kind", r.Kind, "secrets", len(secrets))
+	return ctrl.Result{}, nil
+}
+
+// fetchCertificate fetches and parses an annotated Secret. Missing or
+// unparsable secrets still produce certificate info without expiry.
+func (r *WorkloadReconciler) fetchCertificate(ctx context.Context, ref types.NamespacedName) *cache.CertificateInfo {
+	var secret corev1.Secret
+	if err := r.Get(ctx, ref, &secret); err != nil {
+		return unreadSecretInfo(ref.Name, ref.Namespace, err)
+	}
+
+	now := time.Now()
+	certInfo, err := parseSecret(&secret, r.CertificateKeys, r.ScanOpaqueSecrets, r.PKCS12,
+		r.ReadCACertificates, now)
+	r.Renewals.Observe(certInfo, ref.Namespace, now)
+	if err != nil {
+		r.Stats.RecordParseError()
+		log.FromContext(ctx).V(1).Info("failed to extract certificate expiry",
+			"secret", ref.Name,
+			"error", err.Error())
+	}
+	return certInfo
+}
+
+// watchedSecrets returns the distinct secret names of the workload's
+// WatchSecretAnnotation, in annotation order
+func watchedSecrets(workload client.Object) []string {
+	var names []string
+	for name := range strings.SplitSeq(workload.GetAnnotations()[WatchSecretAnnotation], ",") {
+		name = strings.TrimSpace(name)
+		if name != "" && !slices.Contains(names, name) {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// newObject returns an empty metadata-only object of the workload kind
+func (r *WorkloadReconciler) newObject() *metav1.PartialObjectMetadata {
+	workload := &metav1.PartialObjectMetadata{}
+	workload.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(r.Kind))
+	return workload
+}
+
+// findWorkloadsForSecret returns reconcile requests for the workloads of the
+// kind whose annotation names the given Secret
+func (r *WorkloadReconciler) findWorkloadsForSecret(ctx context.Context, secret client.Object) []reconcile.Request {
+	logger := log.FromContext(ctx)
+
+	if !r.Namespaces.Allows(secret.GetNamespace()) {
+		return []reconcile.Request{}
+	}
+
+	list := &metav1.PartialObjectMetadataList{}
+	list.SetGroupVersionKind(appsv1.SchemeGroupVersion.WithKind(r.Kind + "List"))
+	if err := r.List(ctx, list, client.InNamespace(secret.GetNamespace())); err != nil {
+		logger.Error(err, "failed to list workloads", "kind", r.Kind, "namespace", secret.GetNamespace())
+		return []reconcile.Request{}
+	}
+
+	var requests []reconcile.Request
+	for i := range list.Items {
+		if slices.Contains(watchedSecrets(&list.Items[i]), secret.GetName()) {
+			requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&list.Items[i])})
+		}
+	}
+	if len(requests) > 0 {
+		logger.V(1).Info("secret change triggers workload reconciliation",
+			"namespace", secret.GetNamespace(),
+			"secret", secret.GetName(),
+			"kind", r.Kind,
+			"workloads", len(requests))
+	}
+	return requests
+}
+
+// SetupWithManager sets up the controller with the Manager
+func (r *WorkloadReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if !slices.Contains(WorkloadKinds, r.Kind) {
+		return fmt.Errorf("unsupported workload kind %q", r.Kind)
+	}
+	return ctrl.NewControllerManagedBy(mgr).
+		For(r.newObject(), builder.OnlyMetadata).
+		Watches(
+			&corev1.Secret{},
+			r.RateLimit.enqueueSecretRequests(r.findWorkloadsForSecret, nil),
+		).
+		Named(strings.ToLower(r.Kind)).
+		WithOptions(cacheControllerOptions(r.RateLimit)).
+		Complete(r)
+}
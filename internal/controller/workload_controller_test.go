@@ -0,0 +1,160 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"os"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+var _ = Describe("Workload Controller", func() {
+	ctx := context.Background()
+
+	newDeployment := func(namespace, name, secrets string) *appsv1.Deployment {
+		deployment := &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: name}}
+		if secrets != "" {
+			deployment.Annotations = map[string]string{WatchSecretAnnotation: secrets}
+		}
+		return deployment
+	}
+
+	Context("When a workload names secrets in its annotation", func() {
+		It("should cache the certificates of the annotated secrets", func() {
+			certData, err := os.ReadFile("../certutil/testdata/webapp-cert.pem")
+			Expect(err).NotTo(HaveOccurred())
+
+			deployment := newDeployment("default", "api", "api-tls, missing-tls,api-tls")
+			deployment.Labels = map[string]string{"team": "platform"}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "api-tls", Namespace: "default"},
+				Type:       corev1.SecretTypeTLS,
+				Data:       map[string][]byte{corev1.TLSCertKey: certData},
+			}
+			ingressCache := cache.NewIngressCache("test-cluster")
+			reconciler := &WorkloadReconciler{
+				Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).
+					WithObjects(deployment, secret).Build(),
+				Scheme: clientgoscheme.Scheme,
+				Cache:  ingressCache,
+				Kind:   cache.KindDeployment,
+			}
+
+			_, err = reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			entries := ingressCache.GetAll()
+			Expect(entries).To(HaveLen(1))
+			Expect(entries[0].Kind).To(Equal(cache.KindDeployment))
+			Expect(entries[0].Labels).To(Equal(map[string]string{"team": "platform"}))
+			Expect(entries[0].SecretCount).To(Equal(2))
+			states := make(map[string]string)
+			for _, host := range entries[0].Hosts {
+				Expect(host.NoHost).To(BeTrue())
+				states[host.Certificate.Name] = host.Certificate.State
+			}
+			Expect(states).To(Equal(map[string]string{
+				"api-tls":     cache.CertificateStateOK,
+				"missing-tls": cache.CertificateStateMissingSecret,
+			}))
+		})
+
+		It("should drop the workload once the annotation is removed", func() {
+			deployment := newDeployment("default", "api", "api-tls")
+			k8sClient := fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(deployment).Build()
+			ingressCache := cache.NewIngressCache("test-cluster")
+			reconciler := &WorkloadReconciler{
+				Client: k8sClient,
+				Scheme: clientgoscheme.Scheme,
+				Cache:  ingressCache,
+				Kind:   cache.KindDeployment,
+			}
+			req := reconcile.Request{NamespacedName: client.ObjectKeyFromObject(deployment)}
+
+			_, err := reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ingressCache.GetAll()).To(HaveLen(1))
+
+			deployment.Annotations = nil
+			Expect(k8sClient.Update(ctx, deployment)).To(Succeed())
+			_, err = reconciler.Reconcile(ctx, req)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ingressCache.GetAll()).To(BeEmpty())
+		})
+
+		It("should not touch ingresses of the same name", func() {
+			ingressCache := cache.NewIngressCache("test-cluster")
+			ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "api"})
+			reconciler := &WorkloadReconciler{
+				Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).Build(),
+				Scheme: clientgoscheme.Scheme,
+				Cache:  ingressCache,
+				Kind:   cache.KindDeployment,
+			}
+
+			_, err := reconciler.Reconcile(ctx, reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "default", Name: "api"},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ingressCache.GetAll()).To(HaveLen(1))
+		})
+	})
+
+	Context("When a secret changes", func() {
+		It("should map it only to the workloads of the kind naming it", func() {
+			daemonSet := &appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "default",
+				Name:        "node-agent",
+				Annotations: map[string]string{WatchSecretAnnotation: "agent-tls,ca-tls"},
+			}}
+			reconciler := &WorkloadReconciler{
+				Client: fake.NewClientBuilder().WithScheme(clientgoscheme.Scheme).WithObjects(
+					daemonSet,
+					&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "plain"}},
+					&appsv1.DaemonSet{ObjectMeta: metav1.ObjectMeta{
+						Namespace:   "other",
+						Name:        "node-agent",
+						Annotations: map[string]string{WatchSecretAnnotation: "ca-tls"},
+					}},
+					newDeployment("default", "api", "ca-tls"),
+				).Build(),
+				Scheme: clientgoscheme.Scheme,
+				Cache:  cache.NewIngressCache("test-cluster"),
+				Kind:   cache.KindDaemonSet,
+			}
+
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "ca-tls", Namespace: "default"}}
+			Expect(reconciler.findWorkloadsForSecret(ctx, secret)).To(ConsistOf(
+				reconcile.Request{NamespacedName: client.ObjectKeyFromObject(daemonSet)},
+			))
+		})
+	})
+})
@@ -0,0 +1,101 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// requiredAccess lists the permissions the observer needs in a namespace
+// to watch Ingresses and their TLS Secrets
+var requiredAccess = []authorizationv1.ResourceAttributes{
+	{Group: "networking.k8s.io", Resource: "ingresses", Verb: "list"},
+	{Group: "networking.k8s.io", Resource: "ingresses", Verb: "watch"},
+	{Group: "", Resource: "secrets", Verb: "list"},
+	{Group: "", Resource: "secrets", Verb: "watch"},
+}
+
+// AccessReviewer checks whether the observer is allowed to perform an action
+type AccessReviewer interface {
+	Allowed(ctx context.Context, attrs authorizationv1.ResourceAttributes) (bool, error)
+}
+
+// SelfSubjectAccessReviewer checks access with SelfSubjectAccessReviews
+type SelfSubjectAccessReviewer struct {
+	Client client.Client
+}
+
+// Allowed creates a SelfSubjectAccessReview for the given attributes
+func (r *SelfSubjectAccessReviewer) Allowed(ctx context.Context, attrs authorizationv1.ResourceAttributes) (bool, error) {
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &attrs,
+		},
+	}
+	if err := r.Client.Create(ctx, review); err != nil {
+		return false, fmt.Errorf("failed to create SelfSubjectAccessReview: %w", err)
+	}
+	return review.Status.Allowed, nil
+}
+
+// CandidateNamespaces returns the namespaces allowed by the filter. When the
+// filter has no include list, all namespaces in the cluster are listed, which
+// requires permission to list namespaces.
+func CandidateNamespaces(ctx context.Context, k8sClient client.Client, filter config.NamespaceFilter) ([]string, error) {
+	var names []string
+	if len(filter.Include) > 0 {
+		names = filter.Include
+	} else {
+		var namespaces corev1.NamespaceList
+		if err := k8sClient.List(ctx, &namespaces); err != nil {
+			return nil, fmt.Errorf("failed to list namespaces, set watchNamespaces to discover without it: %w", err)
+		}
+		for _, ns := range namespaces.Items {
+			names = append(names, ns.Name)
+		}
+	}
+
+	var candidates []string
+	for _, name := range names {
+		if filter.Allows(name) {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates, nil
+}
+
+// AccessibleNamespaces returns the candidate namespaces in which the observer
+// can list and watch both Ingresses and Secrets
+func AccessibleNamespaces(ctx context.Context, reviewer AccessReviewer, candidates []string) ([]string, error) {
+	var accessible []string
+	for _, namespace := range candidates {
+		allowed, err := namespaceAllowed(ctx, reviewer, namespace)
+		if err != nil {
+			return nil, err
+		}
+		if allowed {
+			accessible = append(accessible, namespace)
+		}
+	}
+	return accessible, nil
+}
+
+// namespaceAllowed reports whether every required permission is granted in the namespace
+func namespaceAllowed(ctx context.Context, reviewer AccessReviewer, namespace string) (bool, error) {
+	for _, attrs := range requiredAccess {
+		attrs.Namespace = namespace
+		allowed, err := reviewer.Allowed(ctx, attrs)
+		if err != nil {
+			return false, err
+		}
+		if !allowed {
+			return false, nil
+		}
+	}
+	return true, nil
+}
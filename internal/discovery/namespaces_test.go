@@ -0,0 +1,106 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// fakeAuthorizer grants the listed "namespace/resource/verb" permissions
+type fakeAuthorizer struct {
+	granted map[string]bool
+	err     error
+}
+
+func (f *fakeAuthorizer) Allowed(_ context.Context, attrs authorizationv1.ResourceAttributes) (bool, error) {
+	if f.err != nil {
+		return false, f.err
+	}
+	return f.granted[attrs.Namespace+"/"+attrs.Resource+"/"+attrs.Verb], nil
+}
+
+// grantAll returns the permissions required to observe the given namespaces
+func grantAll(namespaces ...string) map[string]bool {
+	granted := make(map[string]bool)
+	for _, ns := range namespaces {
+		for _, attrs := range requiredAccess {
+			granted[ns+"/"+attrs.Resource+"/"+attrs.Verb] = true
+		}
+	}
+	return granted
+}
+
+func TestAccessibleNamespaces(t *testing.T) {
+	granted := grantAll("team-a", "team-b")
+	// team-c can read ingresses but not secrets
+	granted["team-c/ingresses/list"] = true
+	granted["team-c/ingresses/watch"] = true
+	reviewer := &fakeAuthorizer{granted: granted}
+
+	got, err := AccessibleNamespaces(context.Background(), reviewer, []string{"team-a", "team-b", "team-c", "kube-system"})
+	if err != nil {
+		t.Fatalf("AccessibleNamespaces() error = %v", err)
+	}
+	if want := []string{"team-a", "team-b"}; !slices.Equal(got, want) {
+		t.Errorf("AccessibleNamespaces() = %v, want %v", got, want)
+	}
+}
+
+func TestAccessibleNamespaces_ReviewError(t *testing.T) {
+	reviewer := &fakeAuthorizer{err: errors.New("boom")}
+
+	if _, err := AccessibleNamespaces(context.Background(), reviewer, []string{"team-a"}); err == nil {
+		t.Error("AccessibleNamespaces() expected error")
+	}
+}
+
+func TestCandidateNamespaces(t *testing.T) {
+	k8sClient := fake.NewClientBuilder().WithObjects(
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "default"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "kube-system"}},
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}},
+	).Build()
+
+	tests := []struct {
+		name   string
+		filter config.NamespaceFilter
+		want   []string
+	}{
+		{
+			name:   "lists all namespaces without include list",
+			filter: config.NamespaceFilter{},
+			want:   []string{"default", "kube-system", "team-a"},
+		},
+		{
+			name:   "drops excluded namespaces",
+			filter: config.NamespaceFilter{Exclude: []string{"kube-system"}},
+			want:   []string{"default", "team-a"},
+		},
+		{
+			name:   "uses include list without listing",
+			filter: config.NamespaceFilter{Include: []string{"team-a", "team-b"}},
+			want:   []string{"team-a", "team-b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := CandidateNamespaces(context.Background(), k8sClient, tt.filter)
+			if err != nil {
+				t.Fatalf("CandidateNamespaces() error = %v", err)
+			}
+			slices.Sort(got)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CandidateNamespaces() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -2,42 +2,243 @@ package metrics
 
 import (
 	"fmt"
+	"maps"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/reporter"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
 )
 
-// Handler serves a simple metrics endpoint
+// ReportStatsSource provides report delivery counters, e.g. an HTTPReporter
+type ReportStatsSource interface {
+	DeliveryStats() reporter.DeliveryStats
+}
+
+var (
+	ingressesDesc = prometheus.NewDesc("cert_observer_ingresses_total",
+		"Total number of observed ingresses", []string{"cluster"}, nil)
+	plaintextHostsDesc = prometheus.NewDesc("cert_observer_plaintext_hosts_total",
+		"Total number of ingress hosts served without TLS", nil, nil)
+	uniqueCertificatesDesc = prometheus.NewDesc("cert_observer_unique_certificates_total",
+		"Number of distinct certificates referenced by observed ingresses", nil, nil)
+	tlsSecretsDesc = prometheus.NewDesc("cert_observer_tls_secrets_total",
+		"Number of distinct secrets referenced by observed ingresses that a certificate was parsed from", nil, nil)
+	nonconformingSecretNamesDesc = prometheus.NewDesc("cert_observer_nonconforming_secret_names",
+		"Number of distinct certificates whose secret name doesn't match SECRET_NAME_PATTERN", nil, nil)
+	certificatesByKeyDesc = prometheus.NewDesc("cert_observer_certificates_by_key_algorithm",
+		"Number of distinct certificates by public key algorithm and size", []string{"algorithm", "bits"}, nil)
+	preV3CertificatesDesc = prometheus.NewDesc("cert_observer_pre_v3_certificates",
+		"Number of distinct certificates older than X.509v3", nil, nil)
+	duplicateSANsDesc = prometheus.NewDesc("cert_observer_duplicate_sans",
+		"Number of SANs covered by more than one distinct certificate", nil, nil)
+	acmeCertificatesDesc = prometheus.NewDesc("cert_observer_acme_certificates",
+		"Number of distinct certificates issued by ACME certificate authorities by provider", []string{"provider"}, nil)
+	certificatesByStatusDesc = prometheus.NewDesc("cert_observer_certificates_by_status",
+		"Number of observed certificates by expiry status", []string{"status"}, nil)
+	expiredCertificatesDesc = prometheus.NewDesc("cert_observer_certificates_expired_total",
+		"Number of observed certificates that have already expired", nil, nil)
+
+	uptimeDesc = prometheus.NewDesc("cert_observer_uptime_seconds",
+		"Seconds since the observer process started", nil, nil)
+	reconcilesDesc = prometheus.NewDesc("cert_observer_reconciles_total",
+		"Total number of reconciles by kind", []string{"kind"}, nil)
+	parseErrorsDesc = prometheus.NewDesc("cert_observer_certificate_parse_errors_total",
+		"Total number of times a referenced secret's certificate couldn't be parsed", nil, nil)
+
+	reportsSentDesc = prometheus.NewDesc("cert_observer_reports_sent_total",
+		"Total number of reports delivered", nil, nil)
+	reportsFailedDesc = prometheus.NewDesc("cert_observer_reports_failed_total",
+		"Total number of reports that failed after all retries", nil, nil)
+	reportLastSuccessDesc = prometheus.NewDesc("cert_observer_report_last_success_timestamp_seconds",
+		"Unix time of the last successful report, 0 if none", nil, nil)
+	reportLastComputedDesc = prometheus.NewDesc("cert_observer_report_last_computed_timestamp_seconds",
+		"Unix time the last report was built, including in dry-run mode, 0 if none", nil, nil)
+	reportConsecutiveFailuresDesc = prometheus.NewDesc("cert_observer_report_consecutive_failures",
+		"Number of reports that failed since the last success", nil, nil)
+)
+
+// Collector is a prometheus.Collector that reads the cache and observer
+// state on every scrape
+type Collector struct {
+	cache      *cache.IngressCache
+	thresholds cache.ExpiryThresholds
+	// stats provides observer uptime and reconcile counts; nil when disabled
+	stats *stats.Recorder
+	// reports provides report delivery counters; nil when reporting is disabled
+	reports ReportStatsSource
+	// clusterInfo describes the cluster info metric, whose constant labels
+	// are the cluster name and report labels; nil when not exposed
+	clusterInfo *prometheus.Desc
+}
+
+// Describe sends the descriptors of all metrics the collector may emit
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ingressesDesc
+	ch <- plaintextHostsDesc
+	ch <- uniqueCertificatesDesc
+	ch <- tlsSecretsDesc
+	ch <- nonconformingSecretNamesDesc
+	ch <- certificatesByKeyDesc
+	ch <- preV3CertificatesDesc
+	ch <- duplicateSANsDesc
+	ch <- acmeCertificatesDesc
+	ch <- certificatesByStatusDesc
+	ch <- expiredCertificatesDesc
+	ch <- uptimeDesc
+	ch <- reconcilesDesc
+	ch <- parseErrorsDesc
+	ch <- reportsSentDesc
+	ch <- reportsFailedDesc
+	ch <- reportLastSuccessDesc
+	ch <- reportLastComputedDesc
+	ch <- reportConsecutiveFailuresDesc
+	if c.clusterInfo != nil {
+		ch <- c.clusterInfo
+	}
+}
+
+// Collect walks the cache and emits the current metric values
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	ch <- gauge(ingressesDesc, c.cache.Len(), c.cache.ClusterName())
+	ch <- gauge(plaintextHostsDesc, c.cache.PlaintextHostCount())
+	ch <- gauge(uniqueCertificatesDesc, c.cache.UniqueCertificateCount())
+	ch <- gauge(tlsSecretsDesc, c.cache.TLSSecretCount())
+	ch <- gauge(nonconformingSecretNamesDesc, c.cache.NonconformingCertificateCount())
+	for key, count := range c.cache.KeyAlgorithmCounts() {
+		ch <- gauge(certificatesByKeyDesc, count, key.Algorithm, strconv.Itoa(key.Bits))
+	}
+	ch <- gauge(preV3CertificatesDesc, c.cache.PreV3CertificateCount())
+	ch <- gauge(duplicateSANsDesc, len(c.cache.FindDuplicateSANs()))
+	for provider, count := range c.cache.ACMECertificateCounts() {
+		ch <- gauge(acmeCertificatesDesc, count, provider)
+	}
+
+	counts := c.cache.CountByStatus(c.thresholds, time.Now())
+	for _, status := range cache.Statuses {
+		ch <- gauge(certificatesByStatusDesc, counts[status], status)
+	}
+	ch <- gauge(expiredCertificatesDesc, counts[cache.StatusExpired])
+	if c.clusterInfo != nil {
+		ch <- gauge(c.clusterInfo, 1)
+	}
+
+	if c.stats != nil {
+		ch <- prometheus.MustNewConstMetric(uptimeDesc, prometheus.GaugeValue, c.stats.Uptime().Seconds())
+		for kind, count := range c.stats.ReconcileCounts() {
+			ch <- counter(reconcilesDesc, count, c.stats.Started(), kind)
+		}
+		ch <- counter(parseErrorsDesc, c.stats.ParseErrors(), c.stats.Started())
+	}
+
+	if c.reports != nil {
+		// Delivery counters also start with the process
+		delivery := c.reports.DeliveryStats()
+		ch <- counter(reportsSentDesc, delivery.Sent, c.stats.Started())
+		ch <- counter(reportsFailedDesc, delivery.Failed, c.stats.Started())
+		lastSuccess := 0.0
+		if !delivery.LastSuccess.IsZero() {
+			lastSuccess = float64(delivery.LastSuccess.Unix())
+		}
+		ch <- prometheus.MustNewConstMetric(reportLastSuccessDesc, prometheus.GaugeValue, lastSuccess)
+		lastComputed := 0.0
+		if !delivery.LastComputed.IsZero() {
+			lastComputed = float64(delivery.LastComputed.Unix())
+		}
+		ch <- prometheus.MustNewConstMetric(reportLastComputedDesc, prometheus.GaugeValue, lastComputed)
+		ch <- gauge(reportConsecutiveFailuresDesc, delivery.ConsecutiveFailures)
+	}
+}
+
+// gauge builds a constant gauge sample from an integer value
+func gauge(desc *prometheus.Desc, value int, labelValues ...string) prometheus.Metric {
+	return prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, float64(value), labelValues...)
+}
+
+// counter builds a constant counter sample from an integer value, counted
+// since created. The created timestamp is exposed as a _created sample in the
+// OpenMetrics format and left out when zero.
+func counter(desc *prometheus.Desc, value int, created time.Time, labelValues ...string) prometheus.Metric {
+	if created.IsZero() {
+		return prometheus.MustNewConstMetric(desc, prometheus.CounterValue, float64(value), labelValues...)
+	}
+	return prometheus.MustNewConstMetricWithCreatedTimestamp(desc, prometheus.CounterValue, float64(value),
+		created, labelValues...)
+}
+
+// Handler serves the cert-observer metrics from a dedicated registry
 type Handler struct {
-	cache *cache.IngressCache
-	log   logr.Logger
+	collector *Collector
+	handler   http.Handler
 }
 
 // NewHandler creates a new metrics handler
-func NewHandler(ingressCache *cache.IngressCache, logger logr.Logger) *Handler {
+func NewHandler(ingressCache *cache.IngressCache, thresholds cache.ExpiryThresholds, logger logr.Logger) *Handler {
+	collector := &Collector{
+		cache:      ingressCache,
+		thresholds: thresholds,
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collector)
+
 	return &Handler{
-		cache: ingressCache,
-		log:   logger,
+		collector: collector,
+		// Scrapers asking for application/openmetrics-text get the OpenMetrics
+		// format with _created samples for counters, others the text format
+		handler: promhttp.HandlerFor(registry, promhttp.HandlerOpts{
+			ErrorLog:                            errorLogger{log: logger},
+			EnableOpenMetrics:                   true,
+			EnableOpenMetricsTextCreatedSamples: true,
+		}),
 	}
 }
 
+// WithStats exposes observer uptime and reconcile counts
+func (h *Handler) WithStats(recorder *stats.Recorder) *Handler {
+	h.collector.stats = recorder
+	return h
+}
+
+// WithReportStats exposes report delivery counters from the given source
+func (h *Handler) WithReportStats(source ReportStatsSource) *Handler {
+	h.collector.reports = source
+	return h
+}
+
+// WithClusterInfo exposes cert_observer_cluster_info, always 1, labeled with
+// the cluster name and the report labels so queries can join other series
+// on them. The label keys must pass config.ValidateReportLabels.
+func (h *Handler) WithClusterInfo(clusterName string, reportLabels map[string]string) *Handler {
+	constLabels := prometheus.Labels{"cluster": clusterName}
+	maps.Copy(constLabels, reportLabels)
+	h.collector.clusterInfo = prometheus.NewDesc("cert_observer_cluster_info",
+		"Cluster name and report labels of the observer, always 1", nil, constLabels)
+	return h
+}
+
+// Collector returns the collector behind the handler so that it can also be
+// registered with another registry, e.g. controller-runtime's
+func (h *Handler) Collector() prometheus.Collector {
+	return h.collector
+}
+
 // ServeHTTP handles /metrics requests
 func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ingresses := h.cache.GetAll()
-	count := len(ingresses)
+	h.handler.ServeHTTP(w, r)
+}
 
-	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
-	w.WriteHeader(http.StatusOK)
+// errorLogger adapts a logr.Logger to promhttp's error log
+type errorLogger struct {
+	log logr.Logger
+}
 
-	if _, err := fmt.Fprintf(w, "# HELP cert_observer_ingresses_total Total number of observed ingresses\n"); err != nil {
-		h.log.V(1).Info("failed to write metrics help line", "error", err.Error())
-	}
-	if _, err := fmt.Fprintf(w, "# TYPE cert_observer_ingresses_total gauge\n"); err != nil {
-		h.log.V(1).Info("failed to write metrics type line", "error", err.Error())
-	}
-	if _, err := fmt.Fprintf(w, "cert_observer_ingresses_total %d\n", count); err != nil {
-		h.log.V(1).Info("failed to write metrics value", "error", err.Error())
-	}
+// Println logs errors encountered while serving metrics
+func (l errorLogger) Println(v ...any) {
+	l.log.V(1).Info("failed to serve metrics", "error", fmt.Sprint(v...))
 }
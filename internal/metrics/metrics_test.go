@@ -0,0 +1,475 @@
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/reporter"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
+)
+
+func TestHandler_CertificatesByStatus(t *testing.T) {
+	at := func(d time.Duration) *time.Time {
+		expires := time.Now().Add(d)
+		return &expires
+	}
+
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []cache.HostInfo{
+			{Host: "ok1.local", Certificate: &cache.CertificateInfo{Name: "ok1-tls", Expires: at(90 * 24 * time.Hour)}},
+			{Host: "ok2.local", Certificate: &cache.CertificateInfo{Name: "ok2-tls", Expires: at(60 * 24 * time.Hour)}},
+			{Host: "warn.local", Certificate: &cache.CertificateInfo{Name: "warn-tls", Expires: at(10 * 24 * time.Hour)}},
+			{Host: "crit.local", Certificate: &cache.CertificateInfo{Name: "crit-tls", Expires: at(24 * time.Hour)}},
+			{Host: "old.local", Certificate: &cache.CertificateInfo{Name: "old-tls", Expires: at(-24 * time.Hour)}},
+			{Host: "plain.local"},
+		},
+	})
+
+	thresholds := cache.ExpiryThresholds{Warning: 30 * 24 * time.Hour, Critical: 3 * 24 * time.Hour}
+	handler := NewHandler(ingressCache, thresholds, logr.Discard())
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE cert_observer_certificates_by_status gauge\n",
+		`cert_observer_certificates_by_status{status="ok"} 2` + "\n",
+		`cert_observer_certificates_by_status{status="warning"} 1` + "\n",
+		`cert_observer_certificates_by_status{status="critical"} 1` + "\n",
+		`cert_observer_certificates_by_status{status="expired"} 1` + "\n",
+		`cert_observer_ingresses_total{cluster="test-cluster"} 1` + "\n",
+		"cert_observer_plaintext_hosts_total 1\n",
+		"cert_observer_unique_certificates_total 5\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q\n%s", want, body)
+		}
+	}
+}
+
+func TestHandler_ExpiredCertificates(t *testing.T) {
+	at := func(d time.Duration) *time.Time {
+		expires := time.Now().Add(d)
+		return &expires
+	}
+
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []cache.HostInfo{
+			{Host: "ok.local", Certificate: &cache.CertificateInfo{Name: "ok-tls", Expires: at(90 * 24 * time.Hour)}},
+			{Host: "crit.local", Certificate: &cache.CertificateInfo{Name: "crit-tls", Expires: at(time.Hour)}},
+			{Host: "old.local", Certificate: &cache.CertificateInfo{Name: "old-tls", Expires: at(-24 * time.Hour)}},
+			{Host: "www.old.local", Certificate: &cache.CertificateInfo{Name: "old-tls", Expires: at(-24 * time.Hour)}},
+			{Host: "older.local", Certificate: &cache.CertificateInfo{Name: "older-tls", Expires: at(-90 * 24 * time.Hour)}},
+			// Expiry unknown, e.g. a missing secret
+			{Host: "missing.local", Certificate: &cache.CertificateInfo{Name: "missing-tls"}},
+		},
+	})
+
+	thresholds := cache.ExpiryThresholds{Warning: 30 * 24 * time.Hour, Critical: 7 * 24 * time.Hour}
+	server := httptest.NewServer(NewHandler(ingressCache, thresholds, logr.Discard()))
+	defer server.Close()
+
+	expected := `
+# HELP cert_observer_certificates_expired_total Number of observed certificates that have already expired
+# TYPE cert_observer_certificates_expired_total gauge
+cert_observer_certificates_expired_total 2
+`
+	if err := testutil.ScrapeAndCompare(server.URL, strings.NewReader(expected),
+		"cert_observer_certificates_expired_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_IngressesClusterLabel(t *testing.T) {
+	// Cluster names come from configuration and may need escaping
+	ingressCache := cache.NewIngressCache(`prod "eu"\west`)
+	ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "webapp"})
+	server := httptest.NewServer(NewHandler(ingressCache, cache.ExpiryThresholds{}, logr.Discard()))
+	defer server.Close()
+
+	expected := `
+# HELP cert_observer_ingresses_total Total number of observed ingresses
+# TYPE cert_observer_ingresses_total gauge
+cert_observer_ingresses_total{cluster="prod \"eu\"\\west"} 1
+`
+	if err := testutil.ScrapeAndCompare(server.URL, strings.NewReader(expected),
+		"cert_observer_ingresses_total"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_ClusterInfo(t *testing.T) {
+	handler := NewHandler(cache.NewIngressCache("test-cluster"), cache.ExpiryThresholds{}, logr.Discard()).
+		WithClusterInfo("test-cluster", map[string]string{"region": "eu", "env": "prod"})
+	server := httptest.NewServer(handler)
+	defer server.Close()
+
+	expected := `
+# HELP cert_observer_cluster_info Cluster name and report labels of the observer, always 1
+# TYPE cert_observer_cluster_info gauge
+cert_observer_cluster_info{cluster="test-cluster",env="prod",region="eu"} 1
+`
+	if err := testutil.ScrapeAndCompare(server.URL, strings.NewReader(expected),
+		"cert_observer_cluster_info"); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_TLSSecrets(t *testing.T) {
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []cache.HostInfo{
+			{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "shared-tls", ChainLength: 1}},
+			{Host: "missing.local", Certificate: &cache.CertificateInfo{Name: "missing-tls"}},
+		},
+	})
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "api",
+		Hosts: []cache.HostInfo{
+			{Host: "api.local", Certificate: &cache.CertificateInfo{Name: "shared-tls", ChainLength: 1}},
+			{Host: "admin.local", Certificate: &cache.CertificateInfo{Name: "admin-tls", ChainLength: 2}},
+		},
+	})
+
+	server := httptest.NewServer(NewHandler(ingressCache, cache.ExpiryThresholds{}, logr.Discard()))
+	defer server.Close()
+
+	expected := `
+# HELP cert_observer_tls_secrets_total Number of distinct secrets referenced by observed ingresses that a certificate was parsed from
+# TYPE cert_observer_tls_secrets_total gauge
+cert_observer_tls_secrets_total 2
+`
+	if err := testutil.ScrapeAndCompare(server.URL, strings.NewReader(expected),
+		"cert_observer_tls_secrets_total",
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_NonconformingSecretNames(t *testing.T) {
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []cache.HostInfo{
+			{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls"}},
+			{Host: "legacy.local", Certificate: &cache.CertificateInfo{Name: "legacy-cert", NonconformingName: true}},
+		},
+	})
+
+	server := httptest.NewServer(NewHandler(ingressCache, cache.ExpiryThresholds{}, logr.Discard()))
+	defer server.Close()
+
+	expected := `
+# HELP cert_observer_nonconforming_secret_names Number of distinct certificates whose secret name doesn't match SECRET_NAME_PATTERN
+# TYPE cert_observer_nonconforming_secret_names gauge
+cert_observer_nonconforming_secret_names 1
+`
+	if err := testutil.ScrapeAndCompare(server.URL, strings.NewReader(expected),
+		"cert_observer_nonconforming_secret_names",
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_CertificatesByKeyAlgorithm(t *testing.T) {
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []cache.HostInfo{
+			{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", KeyAlgorithm: "RSA", KeyBits: 2048}},
+			{Host: "www.webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", KeyAlgorithm: "RSA", KeyBits: 2048}},
+			{Host: "legacy.local", Certificate: &cache.CertificateInfo{Name: "legacy-tls", KeyAlgorithm: "RSA", KeyBits: 2048}},
+			{Host: "api.local", Certificate: &cache.CertificateInfo{Name: "api-tls", KeyAlgorithm: "ECDSA", KeyBits: 256}},
+			{Host: "pending.local", Certificate: &cache.CertificateInfo{Name: "pending-tls"}},
+		},
+	})
+
+	server := httptest.NewServer(NewHandler(ingressCache, cache.ExpiryThresholds{}, logr.Discard()))
+	defer server.Close()
+
+	expected := `
+# HELP cert_observer_certificates_by_key_algorithm Number of distinct certificates by public key algorithm and size
+# TYPE cert_observer_certificates_by_key_algorithm gauge
+cert_observer_certificates_by_key_algorithm{algorithm="ECDSA",bits="256"} 1
+cert_observer_certificates_by_key_algorithm{algorithm="RSA",bits="2048"} 2
+`
+	if err := testutil.ScrapeAndCompare(server.URL, strings.NewReader(expected),
+		"cert_observer_certificates_by_key_algorithm",
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_PreV3Certificates(t *testing.T) {
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []cache.HostInfo{
+			{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", Version: 3}},
+			{Host: "legacy.local", Certificate: &cache.CertificateInfo{Name: "legacy-tls", Version: 1, PreV3: true}},
+			{Host: "www.legacy.local", Certificate: &cache.CertificateInfo{Name: "legacy-tls", Version: 1, PreV3: true}},
+		},
+	})
+
+	server := httptest.NewServer(NewHandler(ingressCache, cache.ExpiryThresholds{}, logr.Discard()))
+	defer server.Close()
+
+	expected := `
+# HELP cert_observer_pre_v3_certificates Number of distinct certificates older than X.509v3
+# TYPE cert_observer_pre_v3_certificates gauge
+cert_observer_pre_v3_certificates 1
+`
+	if err := testutil.ScrapeAndCompare(server.URL, strings.NewReader(expected),
+		"cert_observer_pre_v3_certificates",
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_DuplicateSANs(t *testing.T) {
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "api",
+		Hosts: []cache.HostInfo{
+			{Host: "api.example.com", Certificate: &cache.CertificateInfo{Name: "api-tls", Fingerprint: "aaaa", DNSNames: []string{"api.example.com"}}},
+			{Host: "old.example.com", Certificate: &cache.CertificateInfo{Name: "api-tls-old", Fingerprint: "bbbb", DNSNames: []string{"api.example.com"}}},
+		},
+	})
+
+	server := httptest.NewServer(NewHandler(ingressCache, cache.ExpiryThresholds{}, logr.Discard()))
+	defer server.Close()
+
+	expected := `
+# HELP cert_observer_duplicate_sans Number of SANs covered by more than one distinct certificate
+# TYPE cert_observer_duplicate_sans gauge
+cert_observer_duplicate_sans 1
+`
+	if err := testutil.ScrapeAndCompare(server.URL, strings.NewReader(expected),
+		"cert_observer_duplicate_sans",
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_ACMECertificates(t *testing.T) {
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []cache.HostInfo{
+			{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", IsACME: true, ACMEProvider: "letsencrypt"}},
+			{Host: "www.webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", IsACME: true, ACMEProvider: "letsencrypt"}},
+			{Host: "shop.local", Certificate: &cache.CertificateInfo{Name: "shop-tls", IsACME: true, ACMEProvider: "zerossl"}},
+			{Host: "api.internal", Certificate: &cache.CertificateInfo{Name: "api-tls"}},
+		},
+	})
+
+	server := httptest.NewServer(NewHandler(ingressCache, cache.ExpiryThresholds{}, logr.Discard()))
+	defer server.Close()
+
+	expected := `
+# HELP cert_observer_acme_certificates Number of distinct certificates issued by ACME certificate authorities by provider
+# TYPE cert_observer_acme_certificates gauge
+cert_observer_acme_certificates{provider="letsencrypt"} 1
+cert_observer_acme_certificates{provider="zerossl"} 1
+`
+	if err := testutil.ScrapeAndCompare(server.URL, strings.NewReader(expected),
+		"cert_observer_acme_certificates",
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_Stats(t *testing.T) {
+	recorder := stats.NewRecorder()
+	recorder.RecordReconcile(stats.KindIngress)
+	recorder.RecordReconcile(stats.KindIngress)
+	recorder.RecordReconcile(stats.KindSecret)
+	recorder.RecordParseError()
+
+	handler := NewHandler(cache.NewIngressCache("test-cluster"), cache.ExpiryThresholds{}, logr.Discard()).
+		WithStats(recorder)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE cert_observer_uptime_seconds gauge\n",
+		"# TYPE cert_observer_reconciles_total counter\n",
+		`cert_observer_reconciles_total{kind="ingress"} 2` + "\n",
+		`cert_observer_reconciles_total{kind="secret"} 1` + "\n",
+		"# TYPE cert_observer_certificate_parse_errors_total counter\n",
+		"cert_observer_certificate_parse_errors_total 1\n",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics output missing %q\n%s", want, body)
+		}
+	}
+}
+
+func TestHandler_OpenMetrics(t *testing.T) {
+	recorder := stats.NewRecorder()
+	recorder.RecordReconcile(stats.KindIngress)
+
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "webapp"})
+	handler := NewHandler(ingressCache, cache.ExpiryThresholds{}, logr.Discard()).WithStats(recorder)
+
+	tests := []struct {
+		name            string
+		accept          string
+		wantContentType string
+		want            []string
+		wantMissing     []string
+	}{
+		{
+			name:            "text format by default",
+			wantContentType: "text/plain; version=0.0.4",
+			want: []string{
+				`cert_observer_ingresses_total{cluster="test-cluster"} 1` + "\n",
+				`cert_observer_reconciles_total{kind="ingress"} 1` + "\n",
+			},
+			wantMissing: []string{"# EOF", "_created"},
+		},
+		{
+			name:            "OpenMetrics when accepted",
+			accept:          "application/openmetrics-text; version=1.0.0; charset=utf-8",
+			wantContentType: "application/openmetrics-text; version=1.0.0",
+			want: []string{
+				`cert_observer_ingresses_total{cluster="test-cluster"} 1.0` + "\n",
+				"# TYPE cert_observer_reconciles counter\n",
+				`cert_observer_reconciles_total{kind="ingress"} 1.0` + "\n",
+				`cert_observer_reconciles_created{kind="ingress"} `,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, tt.wantContentType) {
+				t.Errorf("Content-Type = %q, want prefix %q", got, tt.wantContentType)
+			}
+			body := rec.Body.String()
+			for _, want := range tt.want {
+				if !strings.Contains(body, want) {
+					t.Errorf("metrics output missing %q\n%s", want, body)
+				}
+			}
+			for _, missing := range tt.wantMissing {
+				if strings.Contains(body, missing) {
+					t.Errorf("metrics output contains %q\n%s", missing, body)
+				}
+			}
+			if tt.accept != "" && !strings.HasSuffix(body, "# EOF\n") {
+				t.Errorf("OpenMetrics output doesn't end with # EOF\n%s", body)
+			}
+		})
+	}
+}
+
+// staticReportStats is a fixed ReportStatsSource
+type staticReportStats reporter.DeliveryStats
+
+func (s staticReportStats) DeliveryStats() reporter.DeliveryStats {
+	return reporter.DeliveryStats(s)
+}
+
+func TestHandler_ReportStats(t *testing.T) {
+	source := staticReportStats{
+		Sent:                7,
+		Failed:              3,
+		ConsecutiveFailures: 2,
+		LastSuccess:         time.Unix(1700000000, 0),
+		LastComputed:        time.Unix(1700000060, 0),
+	}
+	handler := NewHandler(cache.NewIngressCache("test-cluster"), cache.ExpiryThresholds{}, logr.Discard()).
+		WithReportStats(source)
+
+	expected := `
+# HELP cert_observer_report_consecutive_failures Number of reports that failed since the last success
+# TYPE cert_observer_report_consecutive_failures gauge
+cert_observer_report_consecutive_failures 2
+# HELP cert_observer_report_last_computed_timestamp_seconds Unix time the last report was built, including in dry-run mode, 0 if none
+# TYPE cert_observer_report_last_computed_timestamp_seconds gauge
+cert_observer_report_last_computed_timestamp_seconds 1.70000006e+09
+# HELP cert_observer_report_last_success_timestamp_seconds Unix time of the last successful report, 0 if none
+# TYPE cert_observer_report_last_success_timestamp_seconds gauge
+cert_observer_report_last_success_timestamp_seconds 1.7e+09
+# HELP cert_observer_reports_failed_total Total number of reports that failed after all retries
+# TYPE cert_observer_reports_failed_total counter
+cert_observer_reports_failed_total 3
+# HELP cert_observer_reports_sent_total Total number of reports delivered
+# TYPE cert_observer_reports_sent_total counter
+cert_observer_reports_sent_total 7
+`
+	if err := testutil.CollectAndCompare(handler.Collector(), strings.NewReader(expected),
+		"cert_observer_reports_sent_total",
+		"cert_observer_reports_failed_total",
+		"cert_observer_report_last_success_timestamp_seconds",
+		"cert_observer_report_last_computed_timestamp_seconds",
+		"cert_observer_report_consecutive_failures",
+	); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestHandler_Scrape(t *testing.T) {
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts:     []cache.HostInfo{{Host: "webapp.local"}, {Host: "plain.local"}},
+	})
+	ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "api"})
+
+	server := httptest.NewServer(NewHandler(ingressCache, cache.ExpiryThresholds{}, logr.Discard()))
+	defer server.Close()
+
+	expected := `
+# HELP cert_observer_ingresses_total Total number of observed ingresses
+# TYPE cert_observer_ingresses_total gauge
+cert_observer_ingresses_total{cluster="test-cluster"} 2
+# HELP cert_observer_plaintext_hosts_total Total number of ingress hosts served without TLS
+# TYPE cert_observer_plaintext_hosts_total gauge
+cert_observer_plaintext_hosts_total 2
+`
+	if err := testutil.ScrapeAndCompare(server.URL, strings.NewReader(expected),
+		"cert_observer_ingresses_total",
+		"cert_observer_plaintext_hosts_total",
+	); err != nil {
+		t.Error(err)
+	}
+}
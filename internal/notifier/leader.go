@@ -0,0 +1,32 @@
+package notifier
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// Notifier is a notifier's check loop, run until the context is cancelled
+type Notifier interface {
+	Start(ctx context.Context)
+}
+
+// LeaderRunnable runs a Notifier as a manager.Runnable that needs leader
+// election, so that several replicas don't page or post the same expiring
+// certificate once each. Without leader election the notifier runs right away.
+type LeaderRunnable struct {
+	Notifier Notifier
+}
+
+var _ manager.LeaderElectionRunnable = LeaderRunnable{}
+
+// Start runs the notifier until the context is cancelled
+func (l LeaderRunnable) Start(ctx context.Context) error {
+	l.Notifier.Start(ctx)
+	return nil
+}
+
+// NeedLeaderElection returns true so that only the leader notifies
+func (l LeaderRunnable) NeedLeaderElection() bool {
+	return true
+}
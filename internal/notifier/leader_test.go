@@ -0,0 +1,45 @@
+package notifier
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+func TestLeaderRunnable(t *testing.T) {
+	recorder := &messageRecorder{}
+	server := httptest.NewServer(recorder)
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("prod")
+	addIngress(ingressCache, time.Now().Add(24*time.Hour))
+
+	runnable := LeaderRunnable{Notifier: newTestSlackNotifier(server.URL, ingressCache)}
+	if !runnable.NeedLeaderElection() {
+		t.Error("NeedLeaderElection() = false, want true")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- runnable.Start(ctx) }()
+
+	deadline := time.Now().Add(time.Second)
+	for len(recorder.received()) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(recorder.received()) == 0 {
+		t.Error("notifier did not post while running")
+	}
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Start() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start() did not return after the context was cancelled")
+	}
+}
@@ -0,0 +1,226 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// PagerDuty Events API v2 event actions
+const (
+	eventActionTrigger = "trigger"
+	eventActionResolve = "resolve"
+)
+
+// pagerDutyEvent is the request body for the PagerDuty Events API v2
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes the incident for trigger events
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// observedCert is a certificate found in the cache along with where it lives
+type observedCert struct {
+	namespace string
+	name      string
+	expires   time.Time
+}
+
+// PagerDutyNotifier triggers PagerDuty incidents for certificates that are
+// about to expire and resolves them once the certificate is renewed
+type PagerDutyNotifier struct {
+	config      *config.PagerDutyConfig
+	cache       *cache.IngressCache
+	clusterName string
+	client      *http.Client
+	log         logr.Logger
+	// sent holds the last event action delivered per dedup key, so a
+	// certificate is only triggered or resolved again when its state changes
+	sent map[string]string
+}
+
+// NewPagerDutyNotifier creates a new PagerDutyNotifier instance
+func NewPagerDutyNotifier(cfg *config.PagerDutyConfig, ingressCache *cache.IngressCache, clusterName string, log logr.Logger) *PagerDutyNotifier {
+	return &PagerDutyNotifier{
+		config:      cfg,
+		cache:       ingressCache,
+		clusterName: clusterName,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		log:  log,
+		sent: make(map[string]string),
+	}
+}
+
+// Start begins the periodic expiry check loop
+func (n *PagerDutyNotifier) Start(ctx context.Context) {
+	n.log.Info("starting PagerDuty notifier", "interval", n.config.CheckInterval, "threshold", n.config.CriticalThreshold)
+
+	n.check(ctx)
+
+	ticker := time.NewTicker(n.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			n.log.Info("stopping PagerDuty notifier")
+			return
+		case <-ticker.C:
+			n.check(ctx)
+		}
+	}
+}
+
+// check triggers incidents for certificates past the critical threshold and
+// resolves them for certificates that are healthy again. Healthy
+// certificates are resolved once when first seen, since incidents opened
+// before a restart or by a previous leader aren't known; PagerDuty ignores
+// resolves without an open incident. Incidents of certificates that left the
+// cache, e.g. with their ingress or secret, are resolved too.
+func (n *PagerDutyNotifier) check(ctx context.Context) {
+	now := time.Now()
+	certs := n.observedCerts()
+	for key, cert := range certs {
+		action := eventActionResolve
+		if cert.expires.Sub(now) < n.config.CriticalThreshold {
+			action = eventActionTrigger
+		}
+		previous := n.sent[key]
+		if previous == action {
+			continue
+		}
+
+		if action == eventActionTrigger {
+			if err := n.send(ctx, n.triggerEvent(key, cert)); err != nil {
+				n.log.Error(err, "failed to trigger PagerDuty incident", "certificate", key)
+				continue
+			}
+			n.sent[key] = action
+			n.log.Info("triggered PagerDuty incident", "certificate", key, "expires", cert.expires)
+			continue
+		}
+		if err := n.send(ctx, n.resolveEvent(key)); err != nil {
+			n.log.Error(err, "failed to resolve PagerDuty incident", "certificate", key)
+			continue
+		}
+		n.sent[key] = action
+		if previous == eventActionTrigger {
+			n.log.Info("resolved PagerDuty incident", "certificate", key, "expires", cert.expires)
+		} else {
+			n.log.V(1).Info("resolved any open PagerDuty incident", "certificate", key, "expires", cert.expires)
+		}
+	}
+
+	for key, action := range n.sent {
+		if _, ok := certs[key]; ok {
+			continue
+		}
+		if action == eventActionTrigger {
+			if err := n.send(ctx, n.resolveEvent(key)); err != nil {
+				n.log.Error(err, "failed to resolve PagerDuty incident", "certificate", key)
+				continue
+			}
+			n.log.Info("resolved PagerDuty incident of a certificate no longer observed", "certificate", key)
+		}
+		delete(n.sent, key)
+	}
+}
+
+// observedCerts returns every certificate with a known expiry keyed by its
+// dedup key, so certificates shared by several hosts alert only once
+func (n *PagerDutyNotifier) observedCerts() map[string]observedCert {
+	certs := make(map[string]observedCert)
+	for _, ingress := range n.cache.GetAllShared() {
+		for _, host := range ingress.Hosts {
+			if host.Certificate == nil || host.Certificate.Expires == nil {
+				continue
+			}
+			namespace := host.Certificate.SecretNamespaceOr(ingress.Namespace)
+			key := n.clusterName + "/" + namespace + "/" + host.Certificate.Name
+			certs[key] = observedCert{
+				namespace: namespace,
+				name:      host.Certificate.Name,
+				expires:   *host.Certificate.Expires,
+			}
+		}
+	}
+	return certs
+}
+
+// triggerEvent builds a trigger event for a certificate
+func (n *PagerDutyNotifier) triggerEvent(key string, cert observedCert) *pagerDutyEvent {
+	return &pagerDutyEvent{
+		RoutingKey:  n.config.RoutingKey,
+		EventAction: eventActionTrigger,
+		DedupKey:    key,
+		Payload: &pagerDutyPayload{
+			Summary: fmt.Sprintf("Certificate %s/%s in cluster %s expires at %s",
+				cert.namespace, cert.name, n.clusterName, cert.expires.UTC().Format(time.RFC3339)),
+			Source:   n.clusterName,
+			Severity: "critical",
+			CustomDetails: map[string]string{
+				"cluster":   n.clusterName,
+				"namespace": cert.namespace,
+				"secret":    cert.name,
+				"expires":   cert.expires.UTC().Format(time.RFC3339),
+			},
+		},
+	}
+}
+
+// resolveEvent builds a resolve event for the incident of a certificate
+func (n *PagerDutyNotifier) resolveEvent(key string) *pagerDutyEvent {
+	return &pagerDutyEvent{
+		RoutingKey:  n.config.RoutingKey,
+		EventAction: eventActionResolve,
+		DedupKey:    key,
+	}
+}
+
+// send posts an event to the PagerDuty Events API
+func (n *PagerDutyNotifier) send(ctx context.Context, event *pagerDutyEvent) error {
+	jsonData, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.EventsURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			n.log.V(1).Info("failed to close response body", "error", err.Error())
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("received non-success status code: %d", resp.StatusCode)
+	}
+	return nil
+}
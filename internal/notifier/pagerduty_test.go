@@ -0,0 +1,201 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// eventRecorder is a stub PagerDuty Events API that records received events
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []pagerDutyEvent
+}
+
+func (e *eventRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var event pagerDutyEvent
+	if err := json.NewDecoder(r.Body).Decode(&event); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	e.mu.Lock()
+	e.events = append(e.events, event)
+	e.mu.Unlock()
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (e *eventRecorder) received() []pagerDutyEvent {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return append([]pagerDutyEvent(nil), e.events...)
+}
+
+func addIngress(ingressCache *cache.IngressCache, expires time.Time) {
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []cache.HostInfo{
+			{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", Expires: &expires}},
+			{Host: "www.webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", Expires: &expires}},
+		},
+	})
+}
+
+func TestPagerDutyNotifier_TriggerAndResolve(t *testing.T) {
+	stub := &eventRecorder{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("prod")
+	notifier := NewPagerDutyNotifier(&config.PagerDutyConfig{
+		RoutingKey:        "routing-key",
+		EventsURL:         server.URL,
+		CriticalThreshold: 7 * 24 * time.Hour,
+		CheckInterval:     time.Minute,
+	}, ingressCache, "prod", logr.Discard())
+	ctx := context.Background()
+
+	// Certificate expiring within the critical threshold triggers an incident
+	addIngress(ingressCache, time.Now().Add(24*time.Hour))
+	notifier.check(ctx)
+	// A second check must not trigger again
+	notifier.check(ctx)
+
+	events := stub.received()
+	if len(events) != 1 {
+		t.Fatalf("received %d events after trigger, want 1", len(events))
+	}
+	trigger := events[0]
+	if trigger.EventAction != "trigger" {
+		t.Errorf("EventAction = %v, want trigger", trigger.EventAction)
+	}
+	if trigger.RoutingKey != "routing-key" {
+		t.Errorf("RoutingKey = %v, want routing-key", trigger.RoutingKey)
+	}
+	if trigger.DedupKey != "prod/default/webapp-tls" {
+		t.Errorf("DedupKey = %v, want prod/default/webapp-tls", trigger.DedupKey)
+	}
+	if trigger.Payload == nil || trigger.Payload.Severity != "critical" || trigger.Payload.Source != "prod" {
+		t.Errorf("Payload = %+v, want critical severity from prod", trigger.Payload)
+	}
+
+	// Renewed certificate resolves the incident with the same dedup key
+	addIngress(ingressCache, time.Now().Add(90*24*time.Hour))
+	notifier.check(ctx)
+
+	events = stub.received()
+	if len(events) != 2 {
+		t.Fatalf("received %d events after renewal, want 2", len(events))
+	}
+	resolve := events[1]
+	if resolve.EventAction != "resolve" {
+		t.Errorf("EventAction = %v, want resolve", resolve.EventAction)
+	}
+	if resolve.DedupKey != trigger.DedupKey {
+		t.Errorf("DedupKey = %v, want %v", resolve.DedupKey, trigger.DedupKey)
+	}
+	if resolve.Payload != nil {
+		t.Errorf("resolve Payload = %+v, want nil", resolve.Payload)
+	}
+}
+
+func TestPagerDutyNotifier_RetriesFailedTrigger(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		if calls == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("prod")
+	notifier := NewPagerDutyNotifier(&config.PagerDutyConfig{
+		RoutingKey:        "routing-key",
+		EventsURL:         server.URL,
+		CriticalThreshold: 7 * 24 * time.Hour,
+	}, ingressCache, "prod", logr.Discard())
+
+	addIngress(ingressCache, time.Now().Add(time.Hour))
+	notifier.check(context.Background())
+	notifier.check(context.Background())
+	notifier.check(context.Background())
+
+	mu.Lock()
+	defer mu.Unlock()
+	if calls != 2 {
+		t.Errorf("Events API called %d times, want 2 (failed trigger retried once)", calls)
+	}
+}
+
+func TestPagerDutyNotifier_ResolvesAfterRestart(t *testing.T) {
+	stub := &eventRecorder{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	// A new notifier doesn't know the incident opened before the restart
+	ingressCache := cache.NewIngressCache("prod")
+	notifier := NewPagerDutyNotifier(&config.PagerDutyConfig{
+		RoutingKey:        "routing-key",
+		EventsURL:         server.URL,
+		CriticalThreshold: 7 * 24 * time.Hour,
+	}, ingressCache, "prod", logr.Discard())
+	ctx := context.Background()
+
+	addIngress(ingressCache, time.Now().Add(90*24*time.Hour))
+	notifier.check(ctx)
+	notifier.check(ctx)
+
+	events := stub.received()
+	if len(events) != 1 {
+		t.Fatalf("received %d events, want a single resolve", len(events))
+	}
+	if events[0].EventAction != "resolve" || events[0].DedupKey != "prod/default/webapp-tls" {
+		t.Errorf("event = %s %s, want resolve prod/default/webapp-tls", events[0].EventAction, events[0].DedupKey)
+	}
+}
+
+func TestPagerDutyNotifier_ResolvesRemovedCertificate(t *testing.T) {
+	stub := &eventRecorder{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("prod")
+	notifier := NewPagerDutyNotifier(&config.PagerDutyConfig{
+		RoutingKey:        "routing-key",
+		EventsURL:         server.URL,
+		CriticalThreshold: 7 * 24 * time.Hour,
+	}, ingressCache, "prod", logr.Discard())
+	ctx := context.Background()
+
+	addIngress(ingressCache, time.Now().Add(24*time.Hour))
+	notifier.check(ctx)
+	ingressCache.Delete("default", "webapp")
+	notifier.check(ctx)
+	notifier.check(ctx)
+
+	events := stub.received()
+	if len(events) != 2 {
+		t.Fatalf("received %d events, want a trigger and a resolve", len(events))
+	}
+	if events[1].EventAction != "resolve" || events[1].DedupKey != events[0].DedupKey {
+		t.Errorf("second event = %s %s, want resolve %s", events[1].EventAction, events[1].DedupKey, events[0].DedupKey)
+	}
+	if len(notifier.sent) != 0 {
+		t.Errorf("sent = %v, want the removed certificate forgotten", notifier.sent)
+	}
+}
@@ -0,0 +1,215 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// slackMessage is the request body for a Slack incoming webhook
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// expiringHost is a host whose certificate is within the expiry threshold
+type expiringHost struct {
+	namespace string
+	ingress   string
+	host      string
+	secret    string
+	expires   time.Time
+}
+
+// slackAlert records what a host was last alerted for
+type slackAlert struct {
+	expires time.Time
+	expired bool
+}
+
+// SlackNotifier posts to a Slack incoming webhook when certificates come
+// within the expiry threshold. A host is alerted once, and again only when its
+// certificate expires or is replaced by one that is also about to expire.
+type SlackNotifier struct {
+	config      *config.SlackConfig
+	cache       *cache.IngressCache
+	clusterName string
+	client      *http.Client
+	log         logr.Logger
+	// alerted holds the last alert per host, keyed by namespace/ingress/host
+	alerted map[string]slackAlert
+}
+
+// NewSlackNotifier creates a new SlackNotifier instance
+func NewSlackNotifier(cfg *config.SlackConfig, ingressCache *cache.IngressCache, clusterName string, log logr.Logger) *SlackNotifier {
+	return &SlackNotifier{
+		config:      cfg,
+		cache:       ingressCache,
+		clusterName: clusterName,
+		client: &http.Client{
+			Timeout: 10 * time.Second,
+		},
+		log:     log,
+		alerted: make(map[string]slackAlert),
+	}
+}
+
+// Start begins the periodic expiry check loop
+func (n *SlackNotifier) Start(ctx context.Context) {
+	n.log.Info("starting Slack notifier", "interval", n.config.CheckInterval, "threshold", n.config.ExpiryThreshold)
+
+	n.check(ctx)
+
+	ticker := time.NewTicker(n.config.CheckInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			n.log.Info("stopping Slack notifier")
+			return
+		case <-ticker.C:
+			n.check(ctx)
+		}
+	}
+}
+
+// check posts a single message for every host that needs a new alert and
+// forgets hosts that are no longer expiring
+func (n *SlackNotifier) check(ctx context.Context) {
+	now := time.Now()
+	expiring := n.expiringHosts(now)
+
+	var pending []expiringHost
+	for key, host := range expiring {
+		last, ok := n.alerted[key]
+		if ok && last.expires.Equal(host.expires) && last.expired == !host.expires.After(now) {
+			continue
+		}
+		pending = append(pending, host)
+	}
+	for key := range n.alerted {
+		if _, ok := expiring[key]; !ok {
+			delete(n.alerted, key)
+		}
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	if err := n.send(ctx, n.message(pending, now)); err != nil {
+		// Not recorded as alerted, so the next check tries again
+		n.log.Error(err, "failed to post Slack notification", "certificates", len(pending))
+		return
+	}
+	for _, host := range pending {
+		n.alerted[host.key()] = slackAlert{expires: host.expires, expired: !host.expires.After(now)}
+	}
+	n.log.Info("posted Slack notification", "certificates", len(pending))
+}
+
+// expiringHosts returns every host whose certificate expires within the
+// threshold, keyed by namespace/ingress/host
+func (n *SlackNotifier) expiringHosts(now time.Time) map[string]expiringHost {
+	deadline := now.Add(n.config.ExpiryThreshold)
+	hosts := make(map[string]expiringHost)
+	for _, ingress := range n.cache.GetExpiringSoon(n.config.ExpiryThreshold, now) {
+		for _, host := range ingress.Hosts {
+			cert := host.Certificate
+			if cert == nil || cert.Expires == nil || cert.Expires.After(deadline) {
+				continue
+			}
+			entry := expiringHost{
+				namespace: ingress.Namespace,
+				ingress:   ingress.Name,
+				host:      host.Host,
+				secret:    cert.Name,
+				expires:   *cert.Expires,
+			}
+			hosts[entry.key()] = entry
+		}
+	}
+	return hosts
+}
+
+// key identifies the host across checks
+func (h expiringHost) key() string {
+	return h.namespace + "/" + h.ingress + "/" + h.host
+}
+
+// message formats the hosts soonest to expire first
+func (n *SlackNotifier) message(hosts []expiringHost, now time.Time) *slackMessage {
+	slices.SortFunc(hosts, func(a, b expiringHost) int {
+		if c := a.expires.Compare(b.expires); c != 0 {
+			return c
+		}
+		return strings.Compare(a.key(), b.key())
+	})
+
+	var text strings.Builder
+	fmt.Fprintf(&text, ":warning: %d certificate(s) in cluster *%s* expire within %s\n",
+		len(hosts), n.clusterName, formatDays(n.config.ExpiryThreshold))
+	for _, host := range hosts {
+		fmt.Fprintf(&text, "• `%s/%s` %s (secret `%s`): %s\n",
+			host.namespace, host.ingress, host.host, host.secret, daysLeft(host.expires.Sub(now)))
+	}
+	return &slackMessage{Text: strings.TrimSuffix(text.String(), "\n")}
+}
+
+// daysLeft describes the remaining validity in whole days
+func daysLeft(remaining time.Duration) string {
+	switch {
+	case remaining <= 0:
+		return "expired " + formatDays(-remaining) + " ago"
+	case remaining < 24*time.Hour:
+		return "less than a day left"
+	}
+	return formatDays(remaining) + " left"
+}
+
+// formatDays formats a duration in whole days
+func formatDays(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	if days == 1 {
+		return "1 day"
+	}
+	return fmt.Sprintf("%d days", days)
+}
+
+// send posts a message to the Slack webhook
+func (n *SlackNotifier) send(ctx context.Context, message *slackMessage) error {
+	jsonData, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal message: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.config.WebhookURL, bytes.NewBuffer(jsonData))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := resp.Body.Close(); err != nil {
+			n.log.V(1).Info("failed to close response body", "error", err.Error())
+		}
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("received non-success status code: %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// messageRecorder is a stub Slack incoming webhook that records the raw
+// payloads it receives
+type messageRecorder struct {
+	mu       sync.Mutex
+	payloads []map[string]any
+	status   int
+}
+
+func (m *messageRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload map[string]any
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.status != 0 {
+		w.WriteHeader(m.status)
+		return
+	}
+	m.payloads = append(m.payloads, payload)
+}
+
+func (m *messageRecorder) received() []map[string]any {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]map[string]any(nil), m.payloads...)
+}
+
+func (m *messageRecorder) fail(status int) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.status = status
+}
+
+func newTestSlackNotifier(url string, ingressCache *cache.IngressCache) *SlackNotifier {
+	return NewSlackNotifier(&config.SlackConfig{
+		WebhookURL:      url,
+		ExpiryThreshold: 7 * 24 * time.Hour,
+		CheckInterval:   time.Minute,
+	}, ingressCache, "prod", logr.Discard())
+}
+
+func TestSlackNotifier_Payload(t *testing.T) {
+	stub := &messageRecorder{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("prod")
+	addIngress(ingressCache, time.Now().Add(3*24*time.Hour+time.Hour))
+	healthy := time.Now().Add(90 * 24 * time.Hour)
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "shop",
+		Name:      "storefront",
+		Hosts:     []cache.HostInfo{{Host: "shop.local", Certificate: &cache.CertificateInfo{Name: "shop-tls", Expires: &healthy}}},
+	})
+
+	newTestSlackNotifier(server.URL, ingressCache).check(context.Background())
+
+	payloads := stub.received()
+	if len(payloads) != 1 {
+		t.Fatalf("received %d messages, want 1", len(payloads))
+	}
+	if len(payloads[0]) != 1 {
+		t.Errorf("payload = %v, want only a text field", payloads[0])
+	}
+	text, ok := payloads[0]["text"].(string)
+	if !ok {
+		t.Fatalf("payload text = %v, want a string", payloads[0]["text"])
+	}
+
+	want := strings.Join([]string{
+		":warning: 2 certificate(s) in cluster *prod* expire within 7 days",
+		"• `default/webapp` webapp.local (secret `webapp-tls`): 3 days left",
+		"• `default/webapp` www.webapp.local (secret `webapp-tls`): 3 days left",
+	}, "\n")
+	if text != want {
+		t.Errorf("text =\n%s\nwant\n%s", text, want)
+	}
+}
+
+func TestSlackNotifier_Deduplicates(t *testing.T) {
+	stub := &messageRecorder{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("prod")
+	notifier := newTestSlackNotifier(server.URL, ingressCache)
+	ctx := context.Background()
+
+	// The first check alerts, later ones don't repeat it
+	addIngress(ingressCache, time.Now().Add(24*time.Hour+time.Hour))
+	notifier.check(ctx)
+	notifier.check(ctx)
+	if got := len(stub.received()); got != 1 {
+		t.Fatalf("received %d messages for the same certificate, want 1", got)
+	}
+
+	// Expiring alerts again
+	addIngress(ingressCache, time.Now().Add(-time.Hour))
+	notifier.check(ctx)
+	notifier.check(ctx)
+	payloads := stub.received()
+	if len(payloads) != 2 {
+		t.Fatalf("received %d messages after expiry, want 2", len(payloads))
+	}
+	if text := payloads[1]["text"].(string); !strings.Contains(text, "expired 0 days ago") {
+		t.Errorf("text = %q, want the certificate reported as expired", text)
+	}
+
+	// A renewal clears the alert, so the next expiry alerts again
+	addIngress(ingressCache, time.Now().Add(90*24*time.Hour))
+	notifier.check(ctx)
+	addIngress(ingressCache, time.Now().Add(2*24*time.Hour))
+	notifier.check(ctx)
+	if got := len(stub.received()); got != 3 {
+		t.Errorf("received %d messages after renewal, want 3", got)
+	}
+}
+
+func TestSlackNotifier_RetriesFailedPost(t *testing.T) {
+	stub := &messageRecorder{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("prod")
+	notifier := newTestSlackNotifier(server.URL, ingressCache)
+	addIngress(ingressCache, time.Now().Add(time.Hour))
+
+	stub.fail(http.StatusInternalServerError)
+	notifier.check(context.Background())
+	stub.fail(0)
+	notifier.check(context.Background())
+	notifier.check(context.Background())
+
+	if got := len(stub.received()); got != 1 {
+		t.Errorf("received %d messages, want 1 (failed post retried once)", got)
+	}
+}
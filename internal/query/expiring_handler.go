@@ -0,0 +1,132 @@
+package query
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+// ExpiringPattern is the route pattern served by ExpiringHandler
+const ExpiringPattern = "GET /api/expiring"
+
+// expiringCertificate is one host's certificate in the expiry list
+type expiringCertificate struct {
+	Namespace string `json:"namespace"`
+	// Kind is empty for Ingresses, as in the cache
+	Kind    string    `json:"kind,omitempty"`
+	Ingress string    `json:"ingress"`
+	Host    string    `json:"host,omitempty"`
+	Secret  string    `json:"secret"`
+	Expires time.Time `json:"expires"`
+	// DaysRemaining is the number of whole days until expiry, rounded
+	// toward zero and negative once expired by a day or more
+	DaysRemaining int `json:"daysRemaining"`
+}
+
+// expiringResponse is the body served by ExpiringHandler
+type expiringResponse struct {
+	Certificates []expiringCertificate `json:"certificates"`
+}
+
+// ExpiringHandler serves the observed certificates sorted by expiry, soonest
+// first, for dashboards
+type ExpiringHandler struct {
+	cache *cache.IngressCache
+	log   logr.Logger
+	// now returns the time days remaining are computed at
+	now func() time.Time
+}
+
+// NewExpiringHandler creates a new read-only expiry list handler
+func NewExpiringHandler(ingressCache *cache.IngressCache, logger logr.Logger) *ExpiringHandler {
+	return &ExpiringHandler{
+		cache: ingressCache,
+		log:   logger,
+		now:   time.Now,
+	}
+}
+
+// ServeHTTP handles /api/expiring requests. The optional within query
+// parameter (e.g. 14d or 36h) limits the list to certificates expiring
+// within that duration, including expired ones. Certificates without a known
+// expiry are left out. Hosts are listed once per certificate they are served
+// with.
+func (h *ExpiringHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	now := h.now()
+
+	var ingresses []*cache.IngressInfo
+	var deadline *time.Time
+	if value := r.URL.Query().Get("within"); value != "" {
+		within, err := parseDays(value)
+		if err != nil {
+			writeError(w, h.log, http.StatusBadRequest, fmt.Sprintf("invalid within: %v", err))
+			return
+		}
+		ingresses = h.cache.GetExpiringSoon(within, now)
+		t := now.Add(within)
+		deadline = &t
+	} else {
+		ingresses = h.cache.GetAllShared()
+	}
+
+	certificates := []expiringCertificate{}
+	for _, info := range ingresses {
+		for _, host := range info.Hosts {
+			cert := host.Certificate
+			if cert == nil || cert.Expires == nil || (deadline != nil && cert.Expires.After(*deadline)) {
+				continue
+			}
+			certificates = append(certificates, expiringCertificate{
+				Namespace:     info.Namespace,
+				Kind:          info.Kind,
+				Ingress:       info.Name,
+				Host:          host.Host,
+				Secret:        cert.Name,
+				Expires:       cert.Expires.UTC(),
+				DaysRemaining: int(cert.Expires.Sub(now) / (24 * time.Hour)),
+			})
+		}
+	}
+	slices.SortFunc(certificates, func(a, b expiringCertificate) int {
+		return cmp.Or(
+			a.Expires.Compare(b.Expires),
+			cmp.Compare(a.Namespace, b.Namespace),
+			cmp.Compare(a.Ingress, b.Ingress),
+			cmp.Compare(a.Kind, b.Kind),
+			cmp.Compare(a.Host, b.Host),
+		)
+	})
+
+	if wantsText(r) {
+		h.writeTable(w, certificates)
+		return
+	}
+	writeJSON(w, h.log, http.StatusOK, expiringResponse{Certificates: certificates})
+}
+
+// writeTable writes one row per certificate as a human-readable table
+func (h *ExpiringHandler) writeTable(w http.ResponseWriter, certificates []expiringCertificate) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAMESPACE\tNAME\tHOST\tSECRET\tEXPIRES\tDAYS")
+	for _, cert := range certificates {
+		host := cert.Host
+		if host == "" {
+			host = "-"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\n", cert.Namespace, entryName(cert.Kind, cert.Ingress),
+			host, cert.Secret, cert.Expires.Format(time.RFC3339), cert.DaysRemaining)
+	}
+	if err := tw.Flush(); err != nil {
+		h.log.V(1).Info("failed to write response", "error", err.Error())
+	}
+}
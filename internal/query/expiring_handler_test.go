@@ -0,0 +1,160 @@
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+func newExpiringHandler(now time.Time) *ExpiringHandler {
+	at := func(d time.Duration) *time.Time {
+		expires := now.Add(d)
+		return &expires
+	}
+	day := 24 * time.Hour
+
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts: []cache.HostInfo{
+			{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", Expires: at(5*day + time.Hour)}},
+			{Host: "admin.local", Certificate: &cache.CertificateInfo{Name: "admin-tls", Expires: at(90 * day)}},
+			{Host: "pending.local", Certificate: &cache.CertificateInfo{Name: "pending-tls"}},
+			{Host: "plain.local"},
+		},
+	})
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "team-a",
+		Name:      "shop",
+		Hosts:     []cache.HostInfo{{Host: "shop.local", Certificate: &cache.CertificateInfo{Name: "shop-tls", Expires: at(-2*day - time.Hour)}}},
+	})
+	ingressCache.Add(&cache.IngressInfo{
+		Kind:      cache.KindGateway,
+		Namespace: "default",
+		Name:      "edge",
+		Hosts: []cache.HostInfo{{Host: "api.local", Listener: "https",
+			Certificate: &cache.CertificateInfo{Name: "api-tls", Expires: at(30 * day)}}},
+	})
+
+	handler := NewExpiringHandler(ingressCache, logr.Discard())
+	handler.now = func() time.Time { return now }
+	return handler
+}
+
+func TestExpiringHandler_JSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		query    string
+		want     []string
+		wantDays []int
+	}{
+		{
+			name:     "sorted by expiry",
+			query:    "",
+			want:     []string{"shop.local", "webapp.local", "api.local", "admin.local"},
+			wantDays: []int{-2, 5, 30, 90},
+		},
+		{
+			name:     "within days",
+			query:    "?within=14d",
+			want:     []string{"shop.local", "webapp.local"},
+			wantDays: []int{-2, 5},
+		},
+		{name: "within duration", query: "?within=24h", want: []string{"shop.local"}, wantDays: []int{-2}},
+		{name: "only expired", query: "?within=0d", want: []string{"shop.local"}, wantDays: []int{-2}},
+	}
+
+	handler := newExpiringHandler(time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC))
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/expiring"+tt.query, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			var response expiringResponse
+			if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			var hosts []string
+			var days []int
+			for _, cert := range response.Certificates {
+				hosts = append(hosts, cert.Host)
+				days = append(days, cert.DaysRemaining)
+			}
+			if !slices.Equal(hosts, tt.want) {
+				t.Errorf("hosts = %v, want %v", hosts, tt.want)
+			}
+			if !slices.Equal(days, tt.wantDays) {
+				t.Errorf("days remaining = %v, want %v", days, tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestExpiringHandler_Fields(t *testing.T) {
+	handler := newExpiringHandler(time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/expiring?within=40d", nil))
+
+	var response expiringResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	want := expiringCertificate{
+		Namespace:     "default",
+		Kind:          cache.KindGateway,
+		Ingress:       "edge",
+		Host:          "api.local",
+		Secret:        "api-tls",
+		Expires:       time.Date(2026, time.April, 1, 12, 0, 0, 0, time.UTC),
+		DaysRemaining: 30,
+	}
+	if len(response.Certificates) != 3 || response.Certificates[2] != want {
+		t.Errorf("certificates = %+v, want %+v last", response.Certificates, want)
+	}
+}
+
+func TestExpiringHandler_Table(t *testing.T) {
+	handler := newExpiringHandler(time.Date(2026, time.March, 2, 12, 0, 0, 0, time.UTC))
+	req := httptest.NewRequest(http.MethodGet, "/api/expiring?within=14d", nil)
+	req.Header.Set("Accept", "text/plain")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("Content-Type = %q, want text/plain", ct)
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want header and 2 rows:\n%s", len(lines), rec.Body.String())
+	}
+	if fields := strings.Fields(lines[0]); strings.Join(fields, " ") != "NAMESPACE NAME HOST SECRET EXPIRES DAYS" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); strings.Join(fields, " ") !=
+		"team-a shop shop.local shop-tls 2026-02-28T11:00:00Z -2" {
+		t.Errorf("first row = %q, want the expired certificate", lines[1])
+	}
+}
+
+func TestExpiringHandler_InvalidWithin(t *testing.T) {
+	handler := newExpiringHandler(time.Now())
+	for _, value := range []string{"soon", "-1d", "-5h"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/expiring?within="+value, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("within=%s: status = %d, want %d", value, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
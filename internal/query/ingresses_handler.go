@@ -0,0 +1,154 @@
+package query
+
+import (
+	"fmt"
+	"mime"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/reporter"
+)
+
+// IngressesPattern is the route pattern served by IngressesHandler
+const IngressesPattern = "GET /api/ingresses"
+
+// IngressesHandler serves the current cache contents, in the same shape as
+// the reports sent to the collector
+type IngressesHandler struct {
+	cache       *cache.IngressCache
+	clusterName string
+	log         logr.Logger
+}
+
+// NewIngressesHandler creates a new read-only cache inspection handler
+func NewIngressesHandler(ingressCache *cache.IngressCache, clusterName string, logger logr.Logger) *IngressesHandler {
+	return &IngressesHandler{
+		cache:       ingressCache,
+		clusterName: clusterName,
+		log:         logger,
+	}
+}
+
+// ServeHTTP handles /api/ingresses requests. The optional namespace query
+// parameter limits results to one namespace and expiringWithin (e.g. 14d or
+// 36h) to entries with a certificate expiring within that duration.
+func (h *IngressesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	var ingresses []*cache.IngressInfo
+	if value := query.Get("expiringWithin"); value != "" {
+		within, err := parseDays(value)
+		if err != nil {
+			writeError(w, h.log, http.StatusBadRequest, fmt.Sprintf("invalid expiringWithin: %v", err))
+			return
+		}
+		ingresses = h.cache.GetExpiringSoon(within, time.Now())
+		if namespace := query.Get("namespace"); namespace != "" {
+			ingresses = slices.DeleteFunc(ingresses, func(info *cache.IngressInfo) bool {
+				return info.Namespace != namespace
+			})
+		}
+	} else if namespace := query.Get("namespace"); namespace != "" {
+		ingresses = h.cache.GetByNamespace(namespace)
+	} else {
+		// Sorting below reorders the slice, not the shared entries
+		ingresses = slices.Clone(h.cache.GetAllShared())
+	}
+
+	if ingresses == nil {
+		ingresses = []*cache.IngressInfo{}
+	}
+	slices.SortFunc(ingresses, func(a, b *cache.IngressInfo) int {
+		return strings.Compare(a.Namespace+"/"+a.Name+"/"+a.Kind, b.Namespace+"/"+b.Name+"/"+b.Kind)
+	})
+
+	if wantsText(r) {
+		h.writeTable(w, ingresses)
+		return
+	}
+
+	writeJSON(w, h.log, http.StatusOK, reporter.Report{
+		Cluster:                h.clusterName,
+		PlaintextHostCount:     h.cache.PlaintextHostCount(),
+		UniqueCertificateCount: h.cache.UniqueCertificateCount(),
+		Ingresses:              ingresses,
+	})
+}
+
+// writeTable writes one row per host as a human-readable table
+func (h *IngressesHandler) writeTable(w http.ResponseWriter, ingresses []*cache.IngressInfo) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.WriteHeader(http.StatusOK)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAMESPACE\tNAME\tHOST\tSECRET\tEXPIRES")
+	for _, info := range ingresses {
+		name := entryName(info.Kind, info.Name)
+		for _, host := range info.Hosts {
+			secret, expires := "-", "-"
+			if host.Certificate != nil {
+				secret = host.Certificate.Name
+				if host.Certificate.Expires != nil {
+					expires = host.Certificate.Expires.UTC().Format(time.RFC3339)
+				}
+			}
+			_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", info.Namespace, name, host.Host, secret, expires)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		h.log.V(1).Info("failed to write response", "error", err.Error())
+	}
+}
+
+// entryName returns the name of a cache entry in tables, prefixed with the
+// lower-case kind for entries other than Ingresses, e.g. "gateway/web"
+func entryName(kind, name string) string {
+	if kind == "" {
+		return name
+	}
+	return strings.ToLower(kind) + "/" + name
+}
+
+// wantsText reports whether the client prefers a plain-text table over JSON
+func wantsText(r *http.Request) bool {
+	for _, accept := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+		switch mediaType {
+		case "text/plain":
+			return true
+		case "application/json", "*/*":
+			return false
+		}
+	}
+	return false
+}
+
+// parseDays parses a duration, additionally accepting a whole number of
+// days such as "14d"
+func parseDays(value string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("%q is not a whole number of days", value)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+	if d < 0 {
+		return 0, fmt.Errorf("%q is negative", value)
+	}
+	return d, nil
+}
@@ -0,0 +1,115 @@
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/reporter"
+)
+
+func newIngressesCache() *cache.IngressCache {
+	at := func(d time.Duration) *time.Time {
+		expires := time.Now().Add(d)
+		return &expires
+	}
+
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts:     []cache.HostInfo{{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", Expires: at(5 * 24 * time.Hour)}}},
+	})
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "api",
+		Hosts:     []cache.HostInfo{{Host: "api.local", Certificate: &cache.CertificateInfo{Name: "api-tls", Expires: at(60 * 24 * time.Hour)}}},
+	})
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "team-a",
+		Name:      "shop",
+		Hosts:     []cache.HostInfo{{Host: "shop.local", Certificate: &cache.CertificateInfo{Name: "shop-tls", Expires: at(2 * 24 * time.Hour)}}},
+	})
+	return ingressCache
+}
+
+func TestIngressesHandler_JSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "all", query: "", want: []string{"default/api", "default/webapp", "team-a/shop"}},
+		{name: "namespace", query: "?namespace=default", want: []string{"default/api", "default/webapp"}},
+		{name: "expiring within days", query: "?expiringWithin=14d", want: []string{"default/webapp", "team-a/shop"}},
+		{name: "namespace and expiring", query: "?namespace=team-a&expiringWithin=72h", want: []string{"team-a/shop"}},
+		{name: "no matches", query: "?namespace=missing", want: []string{}},
+	}
+
+	handler := NewIngressesHandler(newIngressesCache(), "test-cluster", logr.Discard())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/ingresses"+tt.query, nil))
+
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+			}
+			var report reporter.Report
+			if err := json.Unmarshal(rec.Body.Bytes(), &report); err != nil {
+				t.Fatalf("failed to decode response: %v", err)
+			}
+			if report.Cluster != "test-cluster" {
+				t.Errorf("Cluster = %v, want test-cluster", report.Cluster)
+			}
+			got := make([]string, 0, len(report.Ingresses))
+			for _, info := range report.Ingresses {
+				got = append(got, info.Namespace+"/"+info.Name)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ingresses = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIngressesHandler_InvalidExpiringWithin(t *testing.T) {
+	handler := NewIngressesHandler(newIngressesCache(), "test-cluster", logr.Discard())
+
+	for _, value := range []string{"soon", "-1d", "1.5d"} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/ingresses?expiringWithin="+value, nil))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("expiringWithin=%s: status = %d, want %d", value, rec.Code, http.StatusBadRequest)
+		}
+	}
+}
+
+func TestIngressesHandler_PlainText(t *testing.T) {
+	handler := NewIngressesHandler(newIngressesCache(), "test-cluster", logr.Discard())
+
+	req := httptest.NewRequest(http.MethodGet, "/api/ingresses?namespace=team-a", nil)
+	req.Header.Set("Accept", "text/plain")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if got := rec.Header().Get("Content-Type"); !strings.HasPrefix(got, "text/plain") {
+		t.Errorf("Content-Type = %v, want text/plain", got)
+	}
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want header and one row:\n%s", len(lines), rec.Body.String())
+	}
+	if fields := strings.Fields(lines[0]); strings.Join(fields, " ") != "NAMESPACE NAME HOST SECRET EXPIRES" {
+		t.Errorf("header = %q", lines[0])
+	}
+	if fields := strings.Fields(lines[1]); len(fields) != 5 || fields[2] != "shop.local" || fields[3] != "shop-tls" {
+		t.Errorf("row = %q", lines[1])
+	}
+}
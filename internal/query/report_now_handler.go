@@ -0,0 +1,43 @@
+package query
+
+import (
+	"net/http"
+
+	"github.com/go-logr/logr"
+)
+
+// ReportNowPattern is the route pattern served by ReportNowHandler
+const ReportNowPattern = "POST /report-now"
+
+// ReportTrigger starts an immediate report, e.g. a reporter or a manager of
+// per-observer reporters
+type ReportTrigger interface {
+	// TriggerReport returns the number of reporters triggered
+	TriggerReport() int
+}
+
+// ReportNowHandler triggers an immediate report, e.g. after fixing a
+// collector outage, without waiting for the next interval
+type ReportNowHandler struct {
+	triggers []ReportTrigger
+	log      logr.Logger
+}
+
+// NewReportNowHandler creates a handler triggering the given reporters
+func NewReportNowHandler(logger logr.Logger, triggers ...ReportTrigger) *ReportNowHandler {
+	return &ReportNowHandler{
+		triggers: triggers,
+		log:      logger,
+	}
+}
+
+// ServeHTTP handles POST /report-now requests. Reports are sent
+// asynchronously, so 202 Accepted only means they were requested.
+func (h *ReportNowHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	triggered := 0
+	for _, trigger := range h.triggers {
+		triggered += trigger.TriggerReport()
+	}
+	h.log.Info("report requested", "reporters", triggered)
+	writeJSON(w, h.log, http.StatusAccepted, map[string]int{"triggered": triggered})
+}
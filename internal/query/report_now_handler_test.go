@@ -0,0 +1,55 @@
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+)
+
+// countingTrigger records how often it was triggered
+type countingTrigger struct {
+	reporters int
+	calls     int
+}
+
+func (c *countingTrigger) TriggerReport() int {
+	c.calls++
+	return c.reporters
+}
+
+func TestReportNowHandler(t *testing.T) {
+	single := &countingTrigger{reporters: 1}
+	manager := &countingTrigger{reporters: 2}
+	handler := NewReportNowHandler(logr.Discard(), single, manager)
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/report-now", nil))
+
+	if rec.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusAccepted)
+	}
+	var body map[string]int
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode body: %v", err)
+	}
+	if body["triggered"] != 3 {
+		t.Errorf("triggered = %d, want 3", body["triggered"])
+	}
+	if single.calls != 1 || manager.calls != 1 {
+		t.Errorf("calls = %d, %d, want 1, 1", single.calls, manager.calls)
+	}
+}
+
+func TestReportNowHandler_Route(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.Handle(ReportNowPattern, NewReportNowHandler(logr.Discard(), &countingTrigger{reporters: 1}))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report-now", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("GET status = %d, want %d", rec.Code, http.StatusMethodNotAllowed)
+	}
+}
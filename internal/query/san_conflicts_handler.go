@@ -0,0 +1,41 @@
+package query
+
+import (
+	"net/http"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+// SANConflictsPattern is the route pattern served by SANConflictsHandler
+const SANConflictsPattern = "GET /api/san-conflicts"
+
+// sanConflictsResponse is the body served by SANConflictsHandler
+type sanConflictsResponse struct {
+	Conflicts []cache.SANConflict `json:"conflicts"`
+}
+
+// SANConflictsHandler serves the SANs covered by more than one distinct
+// certificate, which points to stale certificates shadowing current ones
+type SANConflictsHandler struct {
+	cache *cache.IngressCache
+	log   logr.Logger
+}
+
+// NewSANConflictsHandler creates a new SAN conflict handler
+func NewSANConflictsHandler(ingressCache *cache.IngressCache, logger logr.Logger) *SANConflictsHandler {
+	return &SANConflictsHandler{
+		cache: ingressCache,
+		log:   logger,
+	}
+}
+
+// ServeHTTP handles /api/san-conflicts requests
+func (h *SANConflictsHandler) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	conflicts := h.cache.FindDuplicateSANs()
+	if conflicts == nil {
+		conflicts = []cache.SANConflict{}
+	}
+	writeJSON(w, h.log, http.StatusOK, sanConflictsResponse{Conflicts: conflicts})
+}
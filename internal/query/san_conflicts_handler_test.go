@@ -0,0 +1,47 @@
+package query
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+func TestSANConflictsHandler(t *testing.T) {
+	ingressCache := cache.NewIngressCache("test-cluster")
+	handler := NewSANConflictsHandler(ingressCache, logr.Discard())
+
+	serve := func() string {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/san-conflicts", nil))
+		if rec.Code != http.StatusOK {
+			t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+		}
+		return strings.TrimSpace(rec.Body.String())
+	}
+
+	if got, want := serve(), `{"conflicts":[]}`; got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+
+	for _, secret := range []struct{ name, fingerprint string }{{"api-tls", "aaaa"}, {"api-tls-old", "bbbb"}} {
+		ingressCache.Add(&cache.IngressInfo{
+			Namespace: "default",
+			Name:      secret.name,
+			Hosts: []cache.HostInfo{{Host: "api.example.com", Certificate: &cache.CertificateInfo{
+				Name: secret.name, Fingerprint: secret.fingerprint, DNSNames: []string{"api.example.com"},
+			}}},
+		})
+	}
+	want := `{"conflicts":[{"san":"api.example.com","certificates":[` +
+		`{"fingerprint":"aaaa","secrets":["default/api-tls"]},` +
+		`{"fingerprint":"bbbb","secrets":["default/api-tls-old"]}]}]}`
+	if got := serve(); got != want {
+		t.Errorf("body = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,114 @@
+package query
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/certutil"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// SecretCertPattern is the route pattern served by SecretHandler
+const SecretCertPattern = "GET /secrets/{namespace}/{name}/cert"
+
+// SecretHandler serves parsed certificate details for a single Secret,
+// whether or not it is referenced by an Ingress
+type SecretHandler struct {
+	reader client.Reader
+	log    logr.Logger
+	// keys are the secret keys tried in order for the certificate; tls.crt when empty
+	keys []string
+	// pkcs12 reads the certificate from a PKCS#12 keystore when the secret
+	// holds one; nil disables keystores
+	pkcs12 *certutil.PKCS12
+	// namespaces limits the secrets served; the zero value serves all
+	namespaces config.NamespaceFilter
+}
+
+// NewSecretHandler creates a new secret certificate handler. The reader
+// should read from the API server rather than the manager's cache, which may
+// only hold referenced, labeled or namespaced secrets.
+func NewSecretHandler(reader client.Reader, logger logr.Logger) *SecretHandler {
+	return &SecretHandler{
+		reader: reader,
+		log:    logger,
+	}
+}
+
+// WithCertificateKeys reads the certificate from the first of the given secret keys present
+func (h *SecretHandler) WithCertificateKeys(keys []string) *SecretHandler {
+	h.keys = keys
+	return h
+}
+
+// WithPKCS12 reads the certificate from a PKCS#12 keystore when the secret holds one
+func (h *SecretHandler) WithPKCS12(p12 *certutil.PKCS12) *SecretHandler {
+	h.pkcs12 = p12
+	return h
+}
+
+// WithNamespaces serves only secrets in namespaces the filter allows. Others
+// are reported as missing, so the endpoint doesn't reveal secrets the
+// operator is configured to ignore.
+func (h *SecretHandler) WithNamespaces(filter config.NamespaceFilter) *SecretHandler {
+	h.namespaces = filter
+	return h
+}
+
+// ServeHTTP handles /secrets/{namespace}/{name}/cert requests
+func (h *SecretHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := types.NamespacedName{
+		Namespace: r.PathValue("namespace"),
+		Name:      r.PathValue("name"),
+	}
+	if !h.namespaces.Allows(key.Namespace) {
+		writeError(w, h.log, http.StatusNotFound, "secret not found")
+		return
+	}
+
+	var secret corev1.Secret
+	if err := h.reader.Get(r.Context(), key, &secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			writeError(w, h.log, http.StatusNotFound, "secret not found")
+			return
+		}
+		h.log.Error(err, "failed to get secret", "namespace", key.Namespace, "secret", key.Name)
+		writeError(w, h.log, http.StatusInternalServerError, "failed to get secret")
+		return
+	}
+
+	var info *cache.CertificateInfo
+	var err error
+	if h.pkcs12 != nil {
+		info, err = certutil.ParsePKCS12Secret(&secret, h.keys, *h.pkcs12)
+	} else {
+		info, err = certutil.ParseSecret(&secret, h.keys)
+	}
+	if err != nil {
+		writeError(w, h.log, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	writeJSON(w, h.log, http.StatusOK, info)
+}
+
+// writeJSON encodes the value as the JSON response body
+func writeJSON(w http.ResponseWriter, logger logr.Logger, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logger.V(1).Info("failed to write response", "error", err.Error())
+	}
+}
+
+// writeError writes a JSON error body with the given status code
+func writeError(w http.ResponseWriter, logger logr.Logger, status int, message string) {
+	writeJSON(w, logger, status, map[string]string{"error": message})
+}
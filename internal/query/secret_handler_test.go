@@ -0,0 +1,164 @@
+package query
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// newCertPEM generates a self-signed certificate for the given host
+func newCertPEM(t *testing.T, host string, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: host},
+		DNSNames:     []string{host},
+		NotBefore:    notAfter.Add(-24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newMux(handler http.Handler) *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.Handle(SecretCertPattern, handler)
+	return mux
+}
+
+func TestSecretHandler_ValidSecret(t *testing.T) {
+	notAfter := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second).UTC()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "webapp-tls"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{"tls.crt": newCertPEM(t, "webapp.local", notAfter)},
+	}
+	reader := fake.NewClientBuilder().WithObjects(secret).Build()
+	mux := newMux(NewSecretHandler(reader, logr.Discard()))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/secrets/default/webapp-tls/cert", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+
+	var info cache.CertificateInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if info.Name != "webapp-tls" {
+		t.Errorf("Name = %v, want webapp-tls", info.Name)
+	}
+	if info.Expires == nil || !info.Expires.Equal(notAfter) {
+		t.Errorf("Expires = %v, want %v", info.Expires, notAfter)
+	}
+	if len(info.DNSNames) != 1 || info.DNSNames[0] != "webapp.local" {
+		t.Errorf("DNSNames = %v, want [webapp.local]", info.DNSNames)
+	}
+	if info.Fingerprint == "" {
+		t.Error("Fingerprint is empty")
+	}
+	if info.ChainLength != 1 {
+		t.Errorf("ChainLength = %d, want 1", info.ChainLength)
+	}
+}
+
+func TestSecretHandler_MissingSecret(t *testing.T) {
+	reader := fake.NewClientBuilder().Build()
+	mux := newMux(NewSecretHandler(reader, logr.Discard()))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/secrets/default/missing/cert", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestSecretHandler_SecretOutsideCache(t *testing.T) {
+	// The reader stands in for the API reader, which also sees secrets the
+	// manager's cache leaves out, here one in an unwatched namespace
+	notAfter := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second).UTC()
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: "unwatched", Name: "standalone-tls"},
+		Type:       corev1.SecretTypeTLS,
+		Data:       map[string][]byte{"tls.crt": newCertPEM(t, "standalone.local", notAfter)},
+	}
+	reader := fake.NewClientBuilder().WithObjects(secret).Build()
+	mux := newMux(NewSecretHandler(reader, logr.Discard()))
+
+	rec := httptest.NewRecorder()
+	mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/secrets/unwatched/standalone-tls/cert", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d (body: %s)", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var info cache.CertificateInfo
+	if err := json.Unmarshal(rec.Body.Bytes(), &info); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if info.Expires == nil || !info.Expires.Equal(notAfter) {
+		t.Errorf("Expires = %v, want %v", info.Expires, notAfter)
+	}
+}
+
+func TestSecretHandler_FilteredNamespace(t *testing.T) {
+	notAfter := time.Now().Add(30 * 24 * time.Hour)
+	var secrets []client.Object
+	for _, namespace := range []string{"default", "kube-system", "team-b"} {
+		secrets = append(secrets, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "webapp-tls"},
+			Type:       corev1.SecretTypeTLS,
+			Data:       map[string][]byte{"tls.crt": newCertPEM(t, "webapp.local", notAfter)},
+		})
+	}
+	reader := fake.NewClientBuilder().WithObjects(secrets...).Build()
+	mux := newMux(NewSecretHandler(reader, logr.Discard()).WithNamespaces(config.NamespaceFilter{
+		Include: []string{"default", "kube-system"},
+		Exclude: []string{"kube-system"},
+	}))
+
+	tests := []struct {
+		namespace string
+		want      int
+	}{
+		{namespace: "default", want: http.StatusOK},
+		{namespace: "kube-system", want: http.StatusNotFound},
+		{namespace: "team-b", want: http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.namespace, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			mux.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/secrets/"+tt.namespace+"/webapp-tls/cert", nil))
+			if rec.Code != tt.want {
+				t.Errorf("status = %d, want %d (body: %s)", rec.Code, tt.want, rec.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package reporter
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// maxResponseBodySize bounds how much of a collector response is read to
+// check that it accepted a report
+const maxResponseBodySize = 1 << 20
+
+// checkAcceptance reads a collector's JSON response and checks that it
+// confirms the delivery. It returns the receipt id the collector assigned,
+// empty if none is configured or returned.
+func checkAcceptance(body io.Reader, acceptance *config.ResponseAcceptance) (string, error) {
+	data, err := io.ReadAll(io.LimitReader(body, maxResponseBodySize))
+	if err != nil {
+		return "", fmt.Errorf("failed to read response body: %w", err)
+	}
+	var response any
+	if err := json.Unmarshal(data, &response); err != nil {
+		return "", fmt.Errorf("collector response is not JSON: %w", err)
+	}
+
+	if len(acceptance.Field) > 0 {
+		field := strings.Join(acceptance.Field, ".")
+		value, ok := lookupField(response, acceptance.Field)
+		if !ok {
+			return "", fmt.Errorf("collector response has no %s field", field)
+		}
+		if got := fieldString(value); got != acceptance.Value {
+			return "", fmt.Errorf("collector did not accept the report: %s is %s, want %s", field, got, acceptance.Value)
+		}
+	}
+
+	receiptID := ""
+	if value, ok := lookupField(response, acceptance.ReceiptIDField); ok && len(acceptance.ReceiptIDField) > 0 {
+		receiptID = fieldString(value)
+	}
+	return receiptID, nil
+}
+
+// lookupField follows a path of object keys into a decoded JSON value
+func lookupField(value any, path []string) (any, bool) {
+	for _, key := range path {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+// fieldString formats a decoded JSON value for comparison: strings as they
+// are, anything else in its JSON encoding, e.g. true, 1 or null
+func fieldString(value any) string {
+	if s, ok := value.(string); ok {
+		return s
+	}
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(encoded)
+}
@@ -0,0 +1,54 @@
+package reporter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+func TestCheckAcceptance(t *testing.T) {
+	accepted := &config.ResponseAcceptance{Field: []string{"accepted"}, Value: "true", ReceiptIDField: []string{"id"}}
+
+	tests := []struct {
+		name          string
+		body          string
+		acceptance    *config.ResponseAcceptance
+		wantReceiptID string
+		wantErr       bool
+	}{
+		{name: "accepted", body: `{"accepted": true, "id": "r-123"}`, acceptance: accepted, wantReceiptID: "r-123"},
+		{name: "rejected", body: `{"accepted": false, "id": "r-123"}`, acceptance: accepted, wantErr: true},
+		{name: "missing field", body: `{"id": "r-123"}`, acceptance: accepted, wantErr: true},
+		{name: "not JSON", body: `OK`, acceptance: accepted, wantErr: true},
+		{name: "accepted without id", body: `{"accepted": true}`, acceptance: accepted},
+		{
+			name: "nested string value",
+			body: `{"result": {"status": "queued", "id": 42}}`,
+			acceptance: &config.ResponseAcceptance{
+				Field:          []string{"result", "status"},
+				Value:          "queued",
+				ReceiptIDField: []string{"result", "id"},
+			},
+			wantReceiptID: "42",
+		},
+		{
+			name:          "receipt id only",
+			body:          `{"id": "r-7"}`,
+			acceptance:    &config.ResponseAcceptance{ReceiptIDField: []string{"id"}},
+			wantReceiptID: "r-7",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			receiptID, err := checkAcceptance(strings.NewReader(tt.body), tt.acceptance)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("checkAcceptance() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if receiptID != tt.wantReceiptID {
+				t.Errorf("receipt id = %q, want %q", receiptID, tt.wantReceiptID)
+			}
+		})
+	}
+}
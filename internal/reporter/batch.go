@@ -0,0 +1,33 @@
+package reporter
+
+import (
+	"crypto/rand"
+)
+
+// Batch identifies one part of a report that was split into several
+// requests. All parts share the ReportID; Index counts from 0 to Total-1.
+type Batch struct {
+	ReportID string `json:"reportId"`
+	Index    int    `json:"index"`
+	Total    int    `json:"total"`
+}
+
+// splitReport splits a report into parts of at most size ingresses, each
+// carrying the rest of the report and its place in the batch. A report that
+// fits, or any report when size is 0, is returned whole and untagged.
+func splitReport(report Report, size int) []Report {
+	if size <= 0 || len(report.Ingresses) <= size {
+		return []Report{report}
+	}
+
+	total := (len(report.Ingresses) + size - 1) / size
+	id := rand.Text()
+	parts := make([]Report, 0, total)
+	for index := range total {
+		part := report
+		part.Ingresses = report.Ingresses[index*size : min((index+1)*size, len(report.Ingresses))]
+		part.Batch = &Batch{ReportID: id, Index: index, Total: total}
+		parts = append(parts, part)
+	}
+	return parts
+}
@@ -0,0 +1,153 @@
+package reporter
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+func TestSplitReport(t *testing.T) {
+	tests := []struct {
+		name      string
+		ingresses int
+		size      int
+		wantSizes []int
+	}{
+		{name: "disabled", ingresses: 2500, size: 0, wantSizes: []int{2500}},
+		{name: "fits", ingresses: 1000, size: 1000, wantSizes: []int{1000}},
+		{name: "split", ingresses: 2501, size: 1000, wantSizes: []int{1000, 1000, 501}},
+		{name: "even split", ingresses: 3000, size: 1000, wantSizes: []int{1000, 1000, 1000}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			report := Report{Cluster: "test-cluster", Ingresses: make([]*cache.IngressInfo, tt.ingresses)}
+			for i := range report.Ingresses {
+				report.Ingresses[i] = &cache.IngressInfo{Namespace: "default", Name: fmt.Sprintf("ingress-%d", i)}
+			}
+
+			parts := splitReport(report, tt.size)
+			if len(parts) != len(tt.wantSizes) {
+				t.Fatalf("got %d parts, want %d", len(parts), len(tt.wantSizes))
+			}
+			if len(parts) == 1 {
+				if parts[0].Batch != nil {
+					t.Errorf("Batch = %+v, want nil for an unsplit report", parts[0].Batch)
+				}
+				return
+			}
+
+			next := 0
+			for i, part := range parts {
+				if len(part.Ingresses) != tt.wantSizes[i] {
+					t.Errorf("part %d has %d ingresses, want %d", i, len(part.Ingresses), tt.wantSizes[i])
+				}
+				if part.Cluster != "test-cluster" {
+					t.Errorf("part %d Cluster = %q, want test-cluster", i, part.Cluster)
+				}
+				if part.Batch == nil || part.Batch.Index != i || part.Batch.Total != len(parts) {
+					t.Fatalf("part %d Batch = %+v, want index %d of %d", i, part.Batch, i, len(parts))
+				}
+				if part.Batch.ReportID == "" || part.Batch.ReportID != parts[0].Batch.ReportID {
+					t.Errorf("part %d ReportID = %q, want the shared %q", i, part.Batch.ReportID, parts[0].Batch.ReportID)
+				}
+				// Parts cover the ingresses in order
+				for _, info := range part.Ingresses {
+					if want := fmt.Sprintf("ingress-%d", next); info.Name != want {
+						t.Fatalf("part %d has %s, want %s", i, info.Name, want)
+					}
+					next++
+				}
+			}
+		})
+	}
+}
+
+func TestHTTPReporter_Batches(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("test-cluster")
+	for i := range 25 {
+		ingressCache.Add(&cache.IngressInfo{
+			Namespace: "default",
+			Name:      fmt.Sprintf("ingress-%d", i),
+			Hosts:     []cache.HostInfo{{Host: fmt.Sprintf("host-%d.local", i)}},
+		})
+	}
+	reporter := newTestReporter(server.URL, ingressCache)
+	cfg := *reporter.settings()
+	cfg.ReportBatchSize = 10
+	reporter.Update(&cfg)
+
+	if err := reporter.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+
+	reports := stub.received()
+	if len(reports) != 3 {
+		t.Fatalf("received %d requests, want 3", len(reports))
+	}
+	total := 0
+	for i, report := range reports {
+		if report.Batch == nil || report.Batch.Index != i || report.Batch.Total != 3 {
+			t.Errorf("request %d Batch = %+v, want index %d of 3", i, report.Batch, i)
+		}
+		if report.Cluster != "test-cluster" {
+			t.Errorf("request %d Cluster = %q, want test-cluster", i, report.Cluster)
+		}
+		total += len(report.Ingresses)
+	}
+	if total != 25 {
+		t.Errorf("batches carry %d ingresses, want 25", total)
+	}
+	if got := reporter.DeliveryStats().Sent; got != 1 {
+		t.Errorf("Sent = %d, want 1 for one batched report", got)
+	}
+}
+
+func TestHTTPReporter_FailedBatch(t *testing.T) {
+	stub := &collector{healthy: true}
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The second batch fails on every attempt
+		requests++
+		if requests > 1 {
+			stub.setHealthy(false)
+		}
+		stub.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	queue, err := NewDeadLetterQueue(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue() error = %v", err)
+	}
+	ingressCache := cache.NewIngressCache("test-cluster")
+	for i := range 25 {
+		ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: fmt.Sprintf("ingress-%d", i)})
+	}
+	reporter := newTestReporter(server.URL, ingressCache).WithDeadLetterQueue(queue)
+	cfg := *reporter.settings()
+	cfg.ReportBatchSize = 10
+	reporter.Update(&cfg)
+
+	if err := reporter.sendReport(context.Background()); err == nil {
+		t.Fatal("sendReport() expected error when a batch fails")
+	}
+	if got := len(stub.received()); got != 1 {
+		t.Errorf("received %d batches, want 1 before the failure", got)
+	}
+	// The failed batch and the one after it are queued for replay
+	if n, _ := queue.Len(); n != 2 {
+		t.Errorf("queued batches = %d, want 2", n)
+	}
+	if got := reporter.DeliveryStats().Failed; got != 1 {
+		t.Errorf("Failed = %d, want 1", got)
+	}
+}
@@ -0,0 +1,63 @@
+package reporter
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+// ReportCertificate is a certificate listed once in a normalized report,
+// identified by the namespace/name key of its secret
+type ReportCertificate struct {
+	Key string `json:"key"`
+	*cache.CertificateInfo
+}
+
+// normalizeCertificates moves the certificates of a report's hosts into its
+// Certificates, one per secret, and has the hosts reference them by key
+// instead. The entries must be copies, as returned by GetAll.
+func normalizeCertificates(report Report) Report {
+	seen := make(map[string]bool)
+	var certificates []ReportCertificate
+	for _, info := range report.Ingresses {
+		for i := range info.Hosts {
+			host := &info.Hosts[i]
+			if host.Certificate == nil {
+				continue
+			}
+			key := cache.SecretKey(info, host.Certificate)
+			if !seen[key] {
+				seen[key] = true
+				cert := host.Certificate
+				if cert.SecretNamespace == "" {
+					cert.SecretNamespace = info.Namespace
+				}
+				certificates = append(certificates, ReportCertificate{Key: key, CertificateInfo: cert})
+			}
+			host.Certificate = nil
+			host.CertificateRef = key
+		}
+	}
+	slices.SortFunc(certificates, func(a, b ReportCertificate) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+
+	report.Certificates = certificates
+	return report
+}
+
+// certificateResolver returns a function looking up the certificate of a
+// host in the report, whether embedded or referenced
+func (r *Report) certificateResolver() func(host cache.HostInfo) *cache.CertificateInfo {
+	byKey := make(map[string]*cache.CertificateInfo, len(r.Certificates))
+	for _, cert := range r.Certificates {
+		byKey[cert.Key] = cert.CertificateInfo
+	}
+	return func(host cache.HostInfo) *cache.CertificateInfo {
+		if host.Certificate != nil {
+			return host.Certificate
+		}
+		return byKey[host.CertificateRef]
+	}
+}
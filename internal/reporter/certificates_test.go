@@ -0,0 +1,186 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// sharedCertificateCache holds ingresses that all serve one wildcard
+// certificate, plus one with its own certificate and one without TLS
+func sharedCertificateCache(ingresses int) *cache.IngressCache {
+	expires := time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)
+	wildcard := func() *cache.CertificateInfo {
+		return &cache.CertificateInfo{
+			Name:        "wildcard-tls",
+			Expires:     &expires,
+			Issuer:      "CN=Example CA",
+			DNSNames:    []string{"*.apps.example.com", "apps.example.com"},
+			Fingerprint: "3f2a9c1e5b7d4f6a8c0e2b4d6f8a0c2e4b6d8f0a2c4e6b8d0f2a4c6e8b0d2f4a",
+			ChainLength: 2,
+		}
+	}
+
+	ingressCache := cache.NewIngressCache("test-cluster")
+	for i := range ingresses {
+		host := fmt.Sprintf("app-%d.apps.example.com", i)
+		ingressCache.Add(&cache.IngressInfo{
+			Namespace:   "apps",
+			Name:        fmt.Sprintf("app-%d", i),
+			Hosts:       []cache.HostInfo{{Host: host, Certificate: wildcard()}},
+			SecretCount: 1,
+		})
+	}
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "shop",
+		Name:      "storefront",
+		Hosts: []cache.HostInfo{
+			{Host: "shop.example.com", Certificate: &cache.CertificateInfo{Name: "shop-tls", Expires: &expires}},
+			{Host: "plain.example.com"},
+		},
+		SecretCount: 1,
+	})
+	return ingressCache
+}
+
+func TestNormalizeCertificates(t *testing.T) {
+	report := Report{Cluster: "test-cluster", Ingresses: sharedCertificateCache(10).GetAll()}
+
+	normalized := normalizeCertificates(report)
+	if len(normalized.Certificates) != 2 {
+		t.Fatalf("got %d certificates, want 2", len(normalized.Certificates))
+	}
+	if normalized.Certificates[0].Key != "apps/wildcard-tls" || normalized.Certificates[1].Key != "shop/shop-tls" {
+		t.Errorf("certificate keys = %q, %q, want apps/wildcard-tls, shop/shop-tls",
+			normalized.Certificates[0].Key, normalized.Certificates[1].Key)
+	}
+	if ns := normalized.Certificates[0].SecretNamespace; ns != "apps" {
+		t.Errorf("SecretNamespace = %q, want apps", ns)
+	}
+
+	certificateOf := normalized.certificateResolver()
+	for _, info := range normalized.Ingresses {
+		for _, host := range info.Hosts {
+			if host.Certificate != nil {
+				t.Errorf("%s: certificate embedded in a normalized report", host.Host)
+			}
+			if host.Host == "plain.example.com" {
+				if host.CertificateRef != "" {
+					t.Errorf("%s: CertificateRef = %q, want none without TLS", host.Host, host.CertificateRef)
+				}
+				continue
+			}
+			if cert := certificateOf(host); cert == nil || cert.Expires == nil {
+				t.Errorf("%s: CertificateRef %q doesn't resolve to a certificate", host.Host, host.CertificateRef)
+			}
+		}
+	}
+}
+
+func TestNormalizeCertificates_PayloadSize(t *testing.T) {
+	for _, ingresses := range []int{1, 10, 100} {
+		t.Run(fmt.Sprintf("%d ingresses sharing a certificate", ingresses), func(t *testing.T) {
+			ingressCache := sharedCertificateCache(ingresses)
+
+			embedded, err := json.Marshal(Report{Cluster: "test-cluster", Ingresses: ingressCache.GetAll()})
+			if err != nil {
+				t.Fatalf("failed to marshal embedded report: %v", err)
+			}
+			normalized, err := json.Marshal(normalizeCertificates(
+				Report{Cluster: "test-cluster", Ingresses: ingressCache.GetAll()}))
+			if err != nil {
+				t.Fatalf("failed to marshal normalized report: %v", err)
+			}
+
+			t.Logf("embedded %d bytes, normalized %d bytes", len(embedded), len(normalized))
+			// A certificate used once pays for the reference, a shared one
+			// is only sent once
+			switch {
+			case ingresses >= 100 && len(normalized)*2 > len(embedded):
+				t.Errorf("normalized report is %d bytes, want less than half of the embedded %d bytes",
+					len(normalized), len(embedded))
+			case ingresses >= 10 && len(normalized) >= len(embedded):
+				t.Errorf("normalized report is %d bytes, want less than the embedded %d bytes",
+					len(normalized), len(embedded))
+			}
+		})
+	}
+}
+
+func TestHTTPReporter_CertificateMode(t *testing.T) {
+	tests := []struct {
+		name      string
+		mode      string
+		batchSize int
+		wantParts int
+	}{
+		{name: "embedded by default", mode: "", wantParts: 1},
+		{name: "normalized", mode: config.CertificateModeNormalized, wantParts: 1},
+		{name: "normalized batches", mode: config.CertificateModeNormalized, batchSize: 4, wantParts: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := &collector{healthy: true}
+			server := httptest.NewServer(stub)
+			defer server.Close()
+
+			reporter := NewHTTPReporter(&config.Config{
+				ClusterName:           "test-cluster",
+				ReportEndpoint:        server.URL,
+				ReportInterval:        time.Minute,
+				ReportBatchSize:       tt.batchSize,
+				ReportCertificateMode: tt.mode,
+			}, sharedCertificateCache(10), logr.Discard())
+			if err := reporter.sendReport(context.Background()); err != nil {
+				t.Fatalf("sendReport() error = %v", err)
+			}
+
+			reports := stub.received()
+			if len(reports) != tt.wantParts {
+				t.Fatalf("received %d reports, want %d", len(reports), tt.wantParts)
+			}
+			hosts := 0
+			for _, report := range reports {
+				certificateOf := report.certificateResolver()
+				for _, info := range report.Ingresses {
+					for _, host := range info.Hosts {
+						if host.Host == "plain.example.com" {
+							continue
+						}
+						hosts++
+						embedded := host.Certificate != nil
+						if wantEmbedded := tt.mode == ""; embedded != wantEmbedded {
+							t.Errorf("%s: certificate embedded = %v, want %v", host.Host, embedded, wantEmbedded)
+						}
+						// Each batch lists the certificates its hosts reference
+						if cert := certificateOf(host); cert == nil {
+							t.Errorf("%s: certificate %q not in its report", host.Host, host.CertificateRef)
+						}
+					}
+				}
+			}
+			if hosts != 11 {
+				t.Errorf("got %d hosts with TLS, want 11", hosts)
+			}
+		})
+	}
+}
+
+func TestExpiryMetrics_NormalizedReport(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	report := normalizeCertificates(Report{Cluster: "test-cluster", Ingresses: sharedCertificateCache(3).GetAll()})
+
+	request := expiryMetrics(&report, now)
+	if len(request.ResourceMetrics) != 4 {
+		t.Errorf("got %d resources, want one per host with a referenced certificate", len(request.ResourceMetrics))
+	}
+}
@@ -0,0 +1,177 @@
+package reporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// deadLetterExt is the file extension of queued report payloads
+const deadLetterExt = ".json"
+
+// DeadLetterQueue persists failed report payloads on disk so they can be
+// replayed in order once the endpoint is reachable again. When the queue is
+// full the oldest payload is dropped, as are payloads older than the maximum
+// age if one is set.
+type DeadLetterQueue struct {
+	mu      sync.Mutex
+	dir     string
+	maxSize int
+	// maxAge is how long payloads are kept; 0 keeps them until the queue is full
+	maxAge time.Duration
+	// lastID guarantees increasing file names even within the same nanosecond
+	lastID int64
+	// now returns the time entries are named after and aged against
+	now func() time.Time
+}
+
+// NewDeadLetterQueue creates the queue directory if needed
+func NewDeadLetterQueue(dir string, maxSize int) (*DeadLetterQueue, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("dead-letter queue size must be positive, got %d", maxSize)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create dead-letter queue directory: %w", err)
+	}
+	return &DeadLetterQueue{
+		dir:     dir,
+		maxSize: maxSize,
+		now:     time.Now,
+	}, nil
+}
+
+// WithMaxAge drops payloads once they have been queued for longer than
+// maxAge. A zero maxAge keeps them until the queue is full.
+func (q *DeadLetterQueue) WithMaxAge(maxAge time.Duration) *DeadLetterQueue {
+	q.maxAge = maxAge
+	return q
+}
+
+// Enqueue stores a payload at the end of the queue, dropping expired entries
+// and the oldest entries if the queue exceeds its maximum size. It returns the
+// number of dropped entries.
+func (q *DeadLetterQueue) Enqueue(payload []byte) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	id := q.now().UnixNano()
+	if id <= q.lastID {
+		id = q.lastID + 1
+	}
+	q.lastID = id
+
+	path := filepath.Join(q.dir, fmt.Sprintf("%020d%s", id, deadLetterExt))
+	if err := os.WriteFile(path, payload, 0o600); err != nil {
+		return 0, fmt.Errorf("failed to write dead-letter entry: %w", err)
+	}
+
+	entries, err := q.entries()
+	if err != nil {
+		return 0, err
+	}
+	dropped := 0
+	for len(entries)-dropped > q.maxSize || (dropped < len(entries) && q.expired(entries[dropped])) {
+		if err := os.Remove(entries[dropped]); err != nil {
+			return dropped, fmt.Errorf("failed to drop dead-letter entry: %w", err)
+		}
+		dropped++
+	}
+	return dropped, nil
+}
+
+// Len returns the number of queued payloads
+func (q *DeadLetterQueue) Len() (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := q.entries()
+	return len(entries), err
+}
+
+// Drain sends queued payloads oldest first, removing each one after it was
+// sent. Expired payloads are removed without being sent. It stops at the
+// first failure and returns the number of sent payloads.
+func (q *DeadLetterQueue) Drain(send func(payload []byte) error) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := q.entries()
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	for _, path := range entries {
+		if q.expired(path) {
+			if err := os.Remove(path); err != nil {
+				return sent, fmt.Errorf("failed to drop dead-letter entry: %w", err)
+			}
+			continue
+		}
+		payload, err := os.ReadFile(path)
+		if err != nil {
+			return sent, fmt.Errorf("failed to read dead-letter entry: %w", err)
+		}
+		if err := send(payload); err != nil {
+			return sent, err
+		}
+		if err := os.Remove(path); err != nil {
+			return sent, fmt.Errorf("failed to remove dead-letter entry: %w", err)
+		}
+		sent++
+	}
+	return sent, nil
+}
+
+// Clear removes all queued payloads and returns how many were removed
+func (q *DeadLetterQueue) Clear() (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries, err := q.entries()
+	if err != nil {
+		return 0, err
+	}
+	for i, path := range entries {
+		if err := os.Remove(path); err != nil {
+			return i, fmt.Errorf("failed to remove dead-letter entry: %w", err)
+		}
+	}
+	return len(entries), nil
+}
+
+// entries returns the paths of queued payloads, oldest first
+func (q *DeadLetterQueue) entries() ([]string, error) {
+	dirEntries, err := os.ReadDir(q.dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dead-letter queue: %w", err)
+	}
+
+	var paths []string
+	for _, entry := range dirEntries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), deadLetterExt) {
+			continue
+		}
+		paths = append(paths, filepath.Join(q.dir, entry.Name()))
+	}
+	slices.Sort(paths)
+	return paths, nil
+}
+
+// expired reports whether the entry at path was queued longer than the
+// maximum age ago. Entry names are the nanosecond time they were queued at.
+func (q *DeadLetterQueue) expired(path string) bool {
+	if q.maxAge <= 0 {
+		return false
+	}
+	id, err := strconv.ParseInt(strings.TrimSuffix(filepath.Base(path), deadLetterExt), 10, 64)
+	if err != nil {
+		return false
+	}
+	return q.now().Sub(time.Unix(0, id)) > q.maxAge
+}
@@ -0,0 +1,197 @@
+package reporter
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDeadLetterQueue_EnqueueAndDrainInOrder(t *testing.T) {
+	queue, err := NewDeadLetterQueue(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue() error = %v", err)
+	}
+
+	for _, payload := range []string{"first", "second", "third"} {
+		if _, err := queue.Enqueue([]byte(payload)); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	var got []string
+	sent, err := queue.Drain(func(payload []byte) error {
+		got = append(got, string(payload))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if sent != 3 {
+		t.Errorf("Drain() sent = %d, want 3", sent)
+	}
+	want := []string{"first", "second", "third"}
+	for i := range want {
+		if i >= len(got) || got[i] != want[i] {
+			t.Fatalf("Drain() order = %v, want %v", got, want)
+		}
+	}
+
+	if n, _ := queue.Len(); n != 0 {
+		t.Errorf("Len() after drain = %d, want 0", n)
+	}
+}
+
+func TestDeadLetterQueue_DropsOldestWhenFull(t *testing.T) {
+	queue, err := NewDeadLetterQueue(t.TempDir(), 2)
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue() error = %v", err)
+	}
+
+	var dropped int
+	for _, payload := range []string{"first", "second", "third"} {
+		n, err := queue.Enqueue([]byte(payload))
+		if err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+		dropped += n
+	}
+	if dropped != 1 {
+		t.Errorf("dropped = %d, want 1", dropped)
+	}
+
+	var got []string
+	if _, err := queue.Drain(func(payload []byte) error {
+		got = append(got, string(payload))
+		return nil
+	}); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if len(got) != 2 || got[0] != "second" || got[1] != "third" {
+		t.Errorf("Drain() = %v, want [second third]", got)
+	}
+}
+
+func TestDeadLetterQueue_DrainStopsAtFailure(t *testing.T) {
+	queue, err := NewDeadLetterQueue(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue() error = %v", err)
+	}
+	for _, payload := range []string{"first", "second", "third"} {
+		if _, err := queue.Enqueue([]byte(payload)); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	sent, err := queue.Drain(func(payload []byte) error {
+		if string(payload) == "second" {
+			return errors.New("endpoint unavailable")
+		}
+		return nil
+	})
+	if err == nil {
+		t.Fatal("Drain() expected error")
+	}
+	if sent != 1 {
+		t.Errorf("Drain() sent = %d, want 1", sent)
+	}
+	if n, _ := queue.Len(); n != 2 {
+		t.Errorf("Len() = %d, want 2 remaining", n)
+	}
+}
+
+func TestDeadLetterQueue_Clear(t *testing.T) {
+	queue, err := NewDeadLetterQueue(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue() error = %v", err)
+	}
+	for _, payload := range []string{"first", "second"} {
+		if _, err := queue.Enqueue([]byte(payload)); err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+	}
+
+	cleared, err := queue.Clear()
+	if err != nil {
+		t.Fatalf("Clear() error = %v", err)
+	}
+	if cleared != 2 {
+		t.Errorf("Clear() = %d, want 2", cleared)
+	}
+	if n, _ := queue.Len(); n != 0 {
+		t.Errorf("Len() = %d, want 0", n)
+	}
+}
+
+func TestDeadLetterQueue_MaxAge(t *testing.T) {
+	queue, err := NewDeadLetterQueue(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue() error = %v", err)
+	}
+	queue.WithMaxAge(time.Hour)
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	queue.now = func() time.Time { return now }
+
+	enqueue := func(payload string) int {
+		t.Helper()
+		dropped, err := queue.Enqueue([]byte(payload))
+		if err != nil {
+			t.Fatalf("Enqueue() error = %v", err)
+		}
+		return dropped
+	}
+
+	enqueue("first")
+	now = now.Add(30 * time.Minute)
+	enqueue("second")
+	now = now.Add(45 * time.Minute)
+
+	// first is now 75 minutes old and dropped when the next payload is queued
+	if dropped := enqueue("third"); dropped != 1 {
+		t.Errorf("Enqueue() dropped = %d, want 1", dropped)
+	}
+	if n, _ := queue.Len(); n != 2 {
+		t.Errorf("Len() = %d, want 2", n)
+	}
+
+	// second expires while the endpoint is still down and is never sent
+	now = now.Add(30 * time.Minute)
+	var got []string
+	sent, err := queue.Drain(func(payload []byte) error {
+		got = append(got, string(payload))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if sent != 1 || !slices.Equal(got, []string{"third"}) {
+		t.Errorf("Drain() = %d %v, want 1 [third]", sent, got)
+	}
+	if n, _ := queue.Len(); n != 0 {
+		t.Errorf("Len() after drain = %d, want 0", n)
+	}
+}
+
+func TestDeadLetterQueue_NoMaxAge(t *testing.T) {
+	queue, err := NewDeadLetterQueue(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue() error = %v", err)
+	}
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	queue.now = func() time.Time { return now }
+
+	if _, err := queue.Enqueue([]byte("first")); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	now = now.Add(365 * 24 * time.Hour)
+	sent, err := queue.Drain(func([]byte) error { return nil })
+	if err != nil || sent != 1 {
+		t.Errorf("Drain() = %d, %v, want 1 without a maximum age", sent, err)
+	}
+}
+
+func TestNewDeadLetterQueue_InvalidSize(t *testing.T) {
+	if _, err := NewDeadLetterQueue(t.TempDir(), 0); err == nil {
+		t.Error("NewDeadLetterQueue() expected error for zero size")
+	}
+}
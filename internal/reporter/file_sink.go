@@ -0,0 +1,125 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// FileSink appends reports as newline-delimited JSON to a file for an
+// out-of-band agent to ship. Once a report would grow the file beyond
+// maxSize, the file is rotated: it is renamed to path.1, older files move up
+// to path.2 and so on, and files beyond maxBackups are removed.
+type FileSink struct {
+	mu         sync.Mutex
+	path       string
+	maxSize    int64
+	maxBackups int
+	file       *os.File
+	// size is the current size of file
+	size int64
+}
+
+// NewFileSink opens the report file for appending, creating it and its
+// directory if needed
+func NewFileSink(path string, maxSize int64, maxBackups int) (*FileSink, error) {
+	if maxSize <= 0 {
+		return nil, fmt.Errorf("report file size must be positive, got %d", maxSize)
+	}
+	if maxBackups < 0 {
+		return nil, fmt.Errorf("report file backups must not be negative, got %d", maxBackups)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create report file directory: %w", err)
+	}
+	s := &FileSink{
+		path:       path,
+		maxSize:    maxSize,
+		maxBackups: maxBackups,
+	}
+	if err := s.open(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Send appends the report as a single line. A report larger than maxSize is
+// written to a file of its own.
+func (s *FileSink) Send(_ context.Context, report []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	line := append(bytes.Clone(report), '\n')
+	if s.size > 0 && s.size+int64(len(line)) > s.maxSize {
+		if err := s.rotate(); err != nil {
+			return err
+		}
+	}
+
+	n, err := s.file.Write(line)
+	s.size += int64(n)
+	if err != nil {
+		return fmt.Errorf("failed to write report file: %w", err)
+	}
+	return nil
+}
+
+// Close closes the report file
+func (s *FileSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.file.Close()
+}
+
+// open opens the report file for appending. s.mu must be held.
+func (s *FileSink) open() error {
+	file, err := os.OpenFile(s.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to open report file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("failed to stat report file: %w", err)
+	}
+	s.file = file
+	s.size = info.Size()
+	return nil
+}
+
+// rotate shifts the backups, moves the current file to path.1 and opens a
+// new, empty file. s.mu must be held.
+func (s *FileSink) rotate() error {
+	if err := s.file.Close(); err != nil {
+		return fmt.Errorf("failed to close report file: %w", err)
+	}
+
+	if s.maxBackups == 0 {
+		if err := os.Remove(s.path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove report file: %w", err)
+		}
+		return s.open()
+	}
+
+	// Drop the oldest backup, then shift the others up by one
+	if err := os.Remove(s.backupPath(s.maxBackups)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove old report file: %w", err)
+	}
+	for i := s.maxBackups - 1; i >= 1; i-- {
+		if err := os.Rename(s.backupPath(i), s.backupPath(i+1)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to rotate report file: %w", err)
+		}
+	}
+	if err := os.Rename(s.path, s.backupPath(1)); err != nil {
+		return fmt.Errorf("failed to rotate report file: %w", err)
+	}
+	return s.open()
+}
+
+// backupPath returns the path of the n-th most recent rotated file
+func (s *FileSink) backupPath(n int) string {
+	return fmt.Sprintf("%s.%d", s.path, n)
+}
@@ -0,0 +1,183 @@
+package reporter
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+// readLines returns the lines of a report file, or nil if it doesn't exist
+func readLines(t *testing.T, path string) []string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", path, err)
+	}
+	return strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")
+}
+
+func TestFileSink_RotationBoundary(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "reports.ndjson")
+	// Each report is 9 bytes plus a newline, so exactly two fit
+	sink, err := NewFileSink(path, 20, 2)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+
+	send := func(report string) {
+		t.Helper()
+		if err := sink.Send(context.Background(), []byte(report)); err != nil {
+			t.Fatalf("Send(%s) error = %v", report, err)
+		}
+	}
+
+	send("report-01")
+	send("report-02")
+	if got := readLines(t, path); len(got) != 2 {
+		t.Fatalf("file has %d lines at the size limit, want 2 without rotation", len(got))
+	}
+	if got := readLines(t, path+".1"); got != nil {
+		t.Fatalf("rotated before reaching the size limit: %v", got)
+	}
+
+	// One byte over the limit rotates
+	send("report-03")
+	if got := readLines(t, path); len(got) != 1 || got[0] != "report-03" {
+		t.Errorf("current file = %v, want only the third report", got)
+	}
+	if got := readLines(t, path+".1"); len(got) != 2 {
+		t.Errorf("first backup = %v, want the first two reports", got)
+	}
+}
+
+func TestFileSink_Backups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports.ndjson")
+	// Room for a single report per file
+	sink, err := NewFileSink(path, 5, 2)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+
+	for _, report := range []string{"r1", "r2", "r3", "r4"} {
+		if err := sink.Send(context.Background(), []byte(report)); err != nil {
+			t.Fatalf("Send(%s) error = %v", report, err)
+		}
+	}
+
+	for file, want := range map[string]string{path: "r4", path + ".1": "r3", path + ".2": "r2"} {
+		if got := readLines(t, file); len(got) != 1 || got[0] != want {
+			t.Errorf("%s = %v, want [%s]", filepath.Base(file), got, want)
+		}
+	}
+	if got := readLines(t, path+".3"); got != nil {
+		t.Errorf("kept more than 2 backups: %v", got)
+	}
+}
+
+func TestFileSink_NoBackups(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports.ndjson")
+	sink, err := NewFileSink(path, 5, 0)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+
+	for _, report := range []string{"r1", "r2"} {
+		if err := sink.Send(context.Background(), []byte(report)); err != nil {
+			t.Fatalf("Send(%s) error = %v", report, err)
+		}
+	}
+
+	if got := readLines(t, path); len(got) != 1 || got[0] != "r2" {
+		t.Errorf("current file = %v, want [r2]", got)
+	}
+	if got := readLines(t, path+".1"); got != nil {
+		t.Errorf("kept a backup with maxBackups 0: %v", got)
+	}
+}
+
+func TestFileSink_Reopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports.ndjson")
+	sink, err := NewFileSink(path, 10, 1)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	if err := sink.Send(context.Background(), []byte("first")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	// The existing file counts towards the size limit after a restart
+	sink, err = NewFileSink(path, 10, 1)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+	if err := sink.Send(context.Background(), []byte("second")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+
+	if got := readLines(t, path+".1"); len(got) != 1 || got[0] != "first" {
+		t.Errorf("backup = %v, want [first]", got)
+	}
+	if got := readLines(t, path); len(got) != 1 || got[0] != "second" {
+		t.Errorf("current file = %v, want [second]", got)
+	}
+}
+
+func TestFileSink_OversizedReport(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports.ndjson")
+	sink, err := NewFileSink(path, 4, 1)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+
+	// A report larger than the limit still goes into an otherwise empty file
+	if err := sink.Send(context.Background(), []byte("oversized")); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got := readLines(t, path); len(got) != 1 || got[0] != "oversized" {
+		t.Errorf("current file = %v, want [oversized]", got)
+	}
+	if got := readLines(t, path+".1"); got != nil {
+		t.Errorf("rotated an empty file: %v", got)
+	}
+}
+
+func TestHTTPReporter_FileSink(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports.ndjson")
+	sink, err := NewFileSink(path, 1<<20, 1)
+	if err != nil {
+		t.Fatalf("NewFileSink() error = %v", err)
+	}
+	defer func() { _ = sink.Close() }()
+
+	// The endpoint is never contacted with a file sink
+	r := newTestReporter("http://unreachable.invalid/report", cache.NewIngressCache("test-cluster")).WithSink(sink)
+	if err := r.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+	if err := r.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+
+	lines := readLines(t, path)
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], `{"cluster":"test-cluster"`) {
+		t.Errorf("report file = %v, want two JSON reports", lines)
+	}
+	if got := r.DeliveryStats().Sent; got != 2 {
+		t.Errorf("Sent = %d, want 2", got)
+	}
+}
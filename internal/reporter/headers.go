@@ -0,0 +1,46 @@
+package reporter
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/google/uuid"
+
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// RequestIDHeader carries a unique id per report, the same for all attempts
+// at delivering it, so collectors can correlate retries in their access logs
+const RequestIDHeader = "X-Request-ID"
+
+// Version is the operator version in the User-Agent of report requests. It
+// is set at build time with
+// -ldflags "-X github.com/ugurcancaykara/cert-observer/internal/reporter.Version=v1.2.0";
+// when empty, the module version of the build is used.
+var Version string
+
+// version returns the operator version, "dev" for builds without one
+func version() string {
+	if Version != "" {
+		return Version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		return info.Main.Version
+	}
+	return "dev"
+}
+
+// userAgent returns the User-Agent of report requests, REPORT_USER_AGENT if
+// set, otherwise the operator version and the cluster name, e.g.
+// "cert-observer/v1.2.0 (cluster prod-eu)"
+func userAgent(cfg *config.Config) string {
+	if cfg.ReportUserAgent != "" {
+		return cfg.ReportUserAgent
+	}
+	return fmt.Sprintf("cert-observer/%s (cluster %s)", version(), cfg.ClusterName)
+}
+
+// newRequestID returns a random id for RequestIDHeader
+func newRequestID() string {
+	return uuid.NewString()
+}
@@ -1,87 +1,321 @@
 package reporter
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand/v2"
 	"net"
-	"net/http"
 	"net/url"
+	"sync"
 	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/ugurcancaykara/cert-observer/internal/cache"
 	"github.com/ugurcancaykara/cert-observer/internal/config"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
 )
 
 // Report represents the JSON structure sent to the endpoint
 type Report struct {
-	Cluster   string               `json:"cluster"`
-	Ingresses []*cache.IngressInfo `json:"ingresses"`
+	Cluster string `json:"cluster"`
+	// Labels are the configured report labels, e.g. region and environment
+	Labels      map[string]string `json:"labels,omitempty"`
+	GeneratedAt time.Time         `json:"generatedAt,omitzero"`
+	// Batch is set when the report is sent in several requests
+	Batch                  *Batch               `json:"batch,omitempty"`
+	PlaintextHostCount     int                  `json:"plaintextHostCount"`
+	UniqueCertificateCount int                  `json:"uniqueCertificateCount"`
+	ObserverUptimeSeconds  int64                `json:"observerUptimeSeconds,omitempty"`
+	ReconcileCounts        map[string]int       `json:"reconcileCounts,omitempty"`
+	Ingresses              []*cache.IngressInfo `json:"ingresses"`
+	// Certificates lists each certificate once when REPORT_CERTIFICATE_MODE
+	// is normalized; hosts then reference them by CertificateRef
+	Certificates []ReportCertificate `json:"certificates,omitempty"`
 }
 
-// HTTPReporter periodically sends reports to an HTTP endpoint
+// DeliveryStats summarizes report delivery since the reporter started
+type DeliveryStats struct {
+	Sent                int
+	Failed              int
+	ConsecutiveFailures int
+	// LastSuccess is zero until a report has been delivered
+	LastSuccess time.Time
+	// LastComputed is when the last report was built, whether or not it was
+	// sent; zero until the first report
+	LastComputed time.Time
+	// LastReceiptID is the id the collector returned for the last delivered
+	// report, when REPORT_RECEIPT_ID_FIELD is set
+	LastReceiptID string
+}
+
+// HTTPReporter periodically builds reports and delivers them to a Sink,
+// by default the HTTP endpoint of the configuration
 type HTTPReporter struct {
-	config       *config.Config
-	cache        *cache.IngressCache
-	client       *http.Client
-	log          logr.Logger
-	failureCount int
+	// configMu guards config, which Update replaces at runtime
+	configMu sync.RWMutex
+	config   *config.Config
+	sink     Sink
+	// intervalUpdates signals the reporting loop to reset its ticker
+	intervalUpdates chan time.Duration
+	// triggers asks the reporting loop for an immediate report
+	triggers chan struct{}
+	cache    *cache.IngressCache
+	log      logr.Logger
+	// statsMu guards delivery, which the metrics handler reads concurrently
+	statsMu  sync.Mutex
+	delivery DeliveryStats
+	// deadLetters holds reports that failed after all retries; nil when disabled
+	deadLetters *DeadLetterQueue
+	// stats provides observer uptime and reconcile counts; nil when disabled
+	stats *stats.Recorder
+	// scoped limits reports to the entries the configuration observes
+	scoped bool
+	// random returns a number in [0, 1) picking the initial report delay
+	random func() float64
+}
+
+// ingressSource is the cache, or a view of it, that reports are built from
+type ingressSource interface {
+	GetAllContext(ctx context.Context) ([]*cache.IngressInfo, error)
+	DeletedSince(since, now time.Time) []*cache.IngressInfo
+	PlaintextHostCount() int
+	UniqueCertificateCount() int
 }
 
 // NewHTTPReporter creates a new HTTPReporter instance
 func NewHTTPReporter(cfg *config.Config, ingressCache *cache.IngressCache, log logr.Logger) *HTTPReporter {
 	return &HTTPReporter{
-		config: cfg,
-		cache:  ingressCache,
-		client: &http.Client{
-			Timeout: 10 * time.Second,
-		},
-		log: log,
+		config:          cfg,
+		sink:            NewHTTPSink(cfg, log),
+		intervalUpdates: make(chan time.Duration, 1),
+		triggers:        make(chan struct{}, 1),
+		cache:           ingressCache,
+		log:             log,
+		random:          rand.Float64,
+	}
+}
+
+// settings returns the current configuration
+func (r *HTTPReporter) settings() *config.Config {
+	r.configMu.RLock()
+	defer r.configMu.RUnlock()
+	return r.config
+}
+
+// Update applies a new configuration without restarting the reporter. The
+// next report uses the new cluster name and endpoint; a report in flight
+// completes against the old ones. When the interval changes, the reporting
+// loop resets its ticker.
+func (r *HTTPReporter) Update(cfg *config.Config) {
+	r.configMu.Lock()
+	old := r.config
+	r.config = cfg
+	r.configMu.Unlock()
+	if sink, ok := r.sink.(reconfigurableSink); ok {
+		sink.Update(cfg)
+	}
+
+	if cfg.ReportEndpoint != old.ReportEndpoint || cfg.ClusterName != old.ClusterName {
+		r.log.Info("updated report settings", "endpoint", cfg.ReportEndpoint, "cluster", cfg.ClusterName)
+	}
+	if cfg.ReportInterval != old.ReportInterval && cfg.ReportInterval > 0 {
+		r.log.Info("updated report interval", "interval", cfg.ReportInterval)
+		// Keep only the latest interval if the loop hasn't picked up the last one
+		select {
+		case <-r.intervalUpdates:
+		default:
+		}
+		r.intervalUpdates <- cfg.ReportInterval
+	}
+}
+
+// WithDeadLetterQueue enables persisting failed reports to the given queue
+func (r *HTTPReporter) WithDeadLetterQueue(queue *DeadLetterQueue) *HTTPReporter {
+	r.deadLetters = queue
+	return r
+}
+
+// WithSink delivers reports to the given sink instead of the HTTP endpoint
+func (r *HTTPReporter) WithSink(sink Sink) *HTTPReporter {
+	r.sink = sink
+	return r
+}
+
+// WithStats includes observer uptime and reconcile counts in each report
+func (r *HTTPReporter) WithStats(recorder *stats.Recorder) *HTTPReporter {
+	r.stats = recorder
+	return r
+}
+
+// WithConfigScope limits reports to the namespaces and ingress selector of
+// the configuration, for reporters sharing a cache with other reporters
+func (r *HTTPReporter) WithConfigScope() *HTTPReporter {
+	r.scoped = true
+	return r
+}
+
+// CheckEndpoint verifies that the sink is reachable. Only the HTTP sink
+// checks anything; other sinks are always considered reachable.
+func (r *HTTPReporter) CheckEndpoint(ctx context.Context) error {
+	if sink, ok := r.sink.(*HTTPSink); ok {
+		return sink.CheckEndpoint(ctx)
 	}
+	return nil
 }
 
 // Start begins the periodic reporting loop
 func (r *HTTPReporter) Start(ctx context.Context) {
-	r.log.Info("starting HTTP reporter", "interval", r.config.ReportInterval, "endpoint", r.config.ReportEndpoint)
+	cfg := r.settings()
+	r.log.Info("starting HTTP reporter", "interval", cfg.ReportInterval, "endpoint", cfg.ReportEndpoint,
+		"dry_run", cfg.DryRun)
+	if cfg.ReportInsecureSkipVerify {
+		r.log.Info("WARNING: TLS certificate verification of the collector is disabled by "+
+			"REPORT_INSECURE_SKIP_VERIFY, reports can be intercepted; use only while bootstrapping, never in production",
+			"endpoint", cfg.ReportEndpoint)
+	}
+
+	// Spread the initial reports of pods started at once, e.g. after a
+	// cluster upgrade
+	if delay := r.initialDelay(cfg); delay > 0 {
+		r.log.Info("delaying initial report", "delay", delay)
+		if err := sleep(ctx, delay); err != nil {
+			r.sendFinalReport(ctx)
+			r.log.Info("stopping HTTP reporter")
+			return
+		}
+	}
 
 	// Send initial report
 	if err := r.sendReport(ctx); err != nil {
 		r.handleReportError(err, true)
 	}
 
-	ticker := time.NewTicker(r.config.ReportInterval)
+	ticker := time.NewTicker(cfg.ReportInterval)
 	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
+			r.sendFinalReport(ctx)
 			r.log.Info("stopping HTTP reporter")
 			return
+		case interval := <-r.intervalUpdates:
+			// Reports run on this goroutine, so none is in flight here
+			ticker.Reset(interval)
 		case <-ticker.C:
 			if err := r.sendReport(ctx); err != nil {
 				r.handleReportError(err, false)
 			}
+		case <-r.triggers:
+			// Sent between ticks without resetting the ticker
+			r.log.Info("sending triggered report")
+			if err := r.sendReport(ctx); err != nil {
+				r.handleReportError(err, false)
+			}
 		}
 	}
 }
 
+// initialDelay returns a random delay for the first report of up to
+// ReportInitialJitter of the report interval
+func (r *HTTPReporter) initialDelay(cfg *config.Config) time.Duration {
+	if cfg.ReportInitialJitter <= 0 || cfg.ReportInterval <= 0 {
+		return 0
+	}
+	return time.Duration(r.random() * cfg.ReportInitialJitter * float64(cfg.ReportInterval))
+}
+
+// errReporterRemoved is the cancellation cause of a reporter stopped because
+// its observer was deleted, which sends no final report
+var errReporterRemoved = errors.New("reporter removed")
+
+// sendFinalReport sends a last report when the reporter stops, e.g. on
+// SIGTERM, so that the collector's state isn't up to an interval old after a
+// restart. The report gets its own timeout since ctx is already cancelled,
+// and a failure is only logged so that shutdown never hangs on the collector.
+func (r *HTTPReporter) sendFinalReport(ctx context.Context) {
+	cfg := r.settings()
+	if cfg.ReportShutdownTimeout <= 0 || errors.Is(context.Cause(ctx), errReporterRemoved) {
+		return
+	}
+	finalCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), cfg.ReportShutdownTimeout)
+	defer cancel()
+
+	r.log.Info("sending final report", "timeout", cfg.ReportShutdownTimeout)
+	if err := r.sendReport(finalCtx); err != nil {
+		r.log.Info("failed to send final report", "error", err.Error())
+	}
+}
+
+// TriggerReport asks the reporting loop to send a report now. The report is
+// sent on the loop's goroutine, so it never overlaps a periodic one, and the
+// periodic schedule is unchanged. Triggers arriving while one is pending are
+// merged into it. It returns the number of reporters triggered, always 1.
+func (r *HTTPReporter) TriggerReport() int {
+	select {
+	case r.triggers <- struct{}{}:
+	default:
+	}
+	return 1
+}
+
+// DeliveryStats returns a copy of the report delivery counters
+func (r *HTTPReporter) DeliveryStats() DeliveryStats {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+	return r.delivery
+}
+
+// recordDelivery updates the delivery counters after a report attempt
+func (r *HTTPReporter) recordDelivery(err error) {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+	if err != nil {
+		r.delivery.Failed++
+		r.delivery.ConsecutiveFailures++
+		return
+	}
+	r.delivery.Sent++
+	r.delivery.ConsecutiveFailures = 0 // Reset failure count on success
+	r.delivery.LastSuccess = time.Now()
+	if sink, ok := r.sink.(receiptSink); ok {
+		r.delivery.LastReceiptID = sink.LastReceiptID()
+	}
+}
+
+// recordComputed notes that a report was built at now and returns when the
+// previous one was, zero if none
+func (r *HTTPReporter) recordComputed(now time.Time) time.Time {
+	r.statsMu.Lock()
+	defer r.statsMu.Unlock()
+	previous := r.delivery.LastComputed
+	r.delivery.LastComputed = now
+	return previous
+}
+
 // handleReportError provides intelligent error logging based on error type and state
 func (r *HTTPReporter) handleReportError(err error, isInitial bool) {
-	r.failureCount++
+	failureCount := r.DeliveryStats().ConsecutiveFailures
+	cfg := r.settings()
+
+	// A report cancelled on shutdown is followed by the final report
+	if errors.Is(err, context.Canceled) {
+		r.log.Info("report cancelled", "error", err.Error())
+		return
+	}
 
 	// Check if this is a DNS/connection error (server not available)
 	if isServerUnavailable(err) {
-		if isInitial || r.failureCount == 1 {
-			r.log.Info("waiting for report server to be available", "endpoint", r.config.ReportEndpoint)
-		} else if r.failureCount%5 == 0 {
+		if isInitial || failureCount == 1 {
+			r.log.Info("waiting for report server to be available", "endpoint", cfg.ReportEndpoint)
+		} else if failureCount%5 == 0 {
 			// Log every 5th failure to avoid spam
-			r.log.V(1).Info("report server still unavailable", "failures", r.failureCount, "endpoint", r.config.ReportEndpoint)
+			r.log.V(1).Info("report server still unavailable", "failures", failureCount, "endpoint", cfg.ReportEndpoint)
 		} else {
 			// Use debug level for other retries
-			r.log.V(2).Info("report server not reachable, will retry", "endpoint", r.config.ReportEndpoint)
+			r.log.V(2).Info("report server not reachable, will retry", "endpoint", cfg.ReportEndpoint)
 		}
 		return
 	}
@@ -116,72 +350,227 @@ func isServerUnavailable(err error) bool {
 
 // sendReport generates and sends a report to the configured endpoint
 func (r *HTTPReporter) sendReport(ctx context.Context) error {
-	// Get all ingress data from cache
-	ingresses := r.cache.GetAll()
+	cfg := r.settings()
 
-	report := Report{
-		Cluster:   r.config.ClusterName,
-		Ingresses: ingresses,
+	var source ingressSource = r.cache
+	if r.scoped {
+		source = r.cache.View(cfg.Observes)
 	}
 
-	// Marshal to JSON
-	jsonData, err := json.Marshal(report)
+	// Get all ingress data from cache, followed by tombstones for the
+	// entries deleted since the previous report. A report cancelled while
+	// copying the cache isn't recorded, so its tombstones go in the next one.
+	now := time.Now()
+	ingresses, err := source.GetAllContext(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to marshal report: %w", err)
+		return fmt.Errorf("report generation cancelled: %w", err)
 	}
+	previous := r.recordComputed(now)
+	annotateIntervals(ingresses, cfg.ReportInterval, now)
+	annotateBusinessHours(ingresses, cfg.BusinessHours)
+	if cfg.ReportDaysUntilExpiry {
+		annotateDaysUntilExpiry(ingresses, now)
+	}
+	ingresses = append(ingresses, source.DeletedSince(previous, now)...)
+	truncateExpiry(ingresses, cfg.ReportExpiryPrecision)
 
-	// Retry logic with exponential backoff
-	maxRetries := 3
-	for attempt := 1; attempt <= maxRetries; attempt++ {
-		// Check if context was cancelled
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		default:
-		}
+	report := Report{
+		Cluster:                cfg.ClusterName,
+		Labels:                 cfg.ReportLabels,
+		GeneratedAt:            now.UTC(),
+		PlaintextHostCount:     source.PlaintextHostCount(),
+		UniqueCertificateCount: source.UniqueCertificateCount(),
+		Ingresses:              ingresses,
+	}
+	if r.stats != nil {
+		report.ObserverUptimeSeconds = int64(r.stats.Uptime().Seconds())
+		report.ReconcileCounts = r.stats.ReconcileCounts()
+	}
 
-		req, err := http.NewRequestWithContext(ctx, "POST", r.config.ReportEndpoint, bytes.NewBuffer(jsonData))
+	normalize := cfg.ReportCertificateMode == config.CertificateModeNormalized
+	if cfg.DryRun {
+		// Log what would be sent without touching the endpoint
+		if normalize {
+			report = normalizeCertificates(report)
+		}
+		pretty, err := json.MarshalIndent(report, "", "  ")
 		if err != nil {
-			return fmt.Errorf("failed to create request: %w", err)
+			return fmt.Errorf("failed to marshal report: %w", err)
 		}
-		req.Header.Set("Content-Type", "application/json")
+		r.log.Info("dry run, report not sent", "cluster", cfg.ClusterName, "endpoint", cfg.ReportEndpoint,
+			"ingress_count", len(ingresses), "report", string(pretty))
+		return nil
+	}
 
-		resp, err := r.client.Do(req)
+	// Marshal to JSON, split into batches for collectors that limit the
+	// request size. Each batch lists the certificates its hosts reference.
+	parts := splitReport(report, cfg.ReportBatchSize)
+	payloads := make([][]byte, 0, len(parts))
+	for _, part := range parts {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("report generation cancelled: %w", err)
+		}
+		if normalize {
+			part = normalizeCertificates(part)
+		}
+		jsonData, err := json.Marshal(part)
 		if err != nil {
-			// Only log detailed errors on last attempt or non-connection errors
-			if attempt == maxRetries && !isServerUnavailable(err) {
-				r.log.Error(err, "failed to send report after retries", "endpoint", r.config.ReportEndpoint, "attempts", maxRetries)
+			r.recordDelivery(err)
+			return fmt.Errorf("failed to marshal report: %w", err)
+		}
+		payloads = append(payloads, jsonData)
+	}
+
+	// Queued reports are older, so they go first, keeping a collector that
+	// keeps the latest report from ending up with a stale one
+	r.replayDeadLetters(ctx)
+
+	// Batches are sent in order; once one fails, it and the rest are queued
+	// so that the collector still receives them all, in order
+	for i, payload := range payloads {
+		if err := r.sink.Send(ctx, payload); err != nil {
+			r.recordDelivery(err)
+			for _, unsent := range payloads[i:] {
+				r.deadLetter(unsent)
 			}
-			if attempt < maxRetries {
-				// Exponential backoff: 2s, 4s
-				backoff := time.Duration(attempt) * 2 * time.Second
-				time.Sleep(backoff)
-				continue
+			if len(payloads) > 1 {
+				return fmt.Errorf("failed to send batch %d of %d: %w", i+1, len(payloads), err)
 			}
 			return err
 		}
-		defer func() {
-			if err := resp.Body.Close(); err != nil {
-				r.log.V(1).Info("failed to close response body", "error", err.Error())
+	}
+	r.recordDelivery(nil)
+
+	logValues := []any{"cluster", cfg.ClusterName, "endpoint", cfg.ReportEndpoint, "ingress_count", len(ingresses)}
+	if len(payloads) > 1 {
+		logValues = append(logValues, "batches", len(payloads))
+	}
+	if receiptID := r.DeliveryStats().LastReceiptID; receiptID != "" {
+		logValues = append(logValues, "receipt_id", receiptID)
+	}
+	r.log.Info("report sent successfully", logValues...)
+
+	r.discardDeadLetters()
+	return nil
+}
+
+// annotateIntervals expresses each certificate's remaining validity in
+// report intervals, so collectors can tell urgency without knowing the
+// interval. The entries must be copies, as returned by GetAll.
+func annotateIntervals(ingresses []*cache.IngressInfo, interval time.Duration, now time.Time) {
+	if interval <= 0 {
+		return
+	}
+	for _, info := range ingresses {
+		for _, host := range info.Hosts {
+			cert := host.Certificate
+			if cert == nil || cert.Expires == nil {
+				continue
+			}
+			intervals := int(max(cert.Expires.Sub(now), 0) / interval)
+			cert.IntervalsUntilExpiry = &intervals
+			cert.ExpiresWithinNextInterval = intervals == 0
+		}
+	}
+}
+
+// annotateDaysUntilExpiry adds each certificate's whole days until expiry.
+// Days are rounded toward zero, so a certificate expiring in 36 hours has 1
+// and one that expired 36 hours ago has -1.
+func annotateDaysUntilExpiry(ingresses []*cache.IngressInfo, now time.Time) {
+	for _, info := range ingresses {
+		for _, host := range info.Hosts {
+			cert := host.Certificate
+			if cert == nil || cert.Expires == nil {
+				continue
 			}
-		}()
+			days := int(cert.Expires.Sub(now) / (24 * time.Hour))
+			cert.DaysUntilExpiry = &days
+		}
+	}
+}
 
-		if resp.StatusCode >= 200 && resp.StatusCode < 300 {
-			r.log.Info("report sent successfully", "endpoint", r.config.ReportEndpoint, "status", resp.StatusCode, "ingress_count", len(ingresses))
-			r.failureCount = 0 // Reset failure count on success
-			return nil
+// truncateExpiry truncates reported expiry times to the precision, e.g. to
+// the start of the UTC day. Fields derived from the expiry are computed
+// before, from the exact time. The entries must be copies, as returned by
+// GetAll.
+func truncateExpiry(ingresses []*cache.IngressInfo, precision time.Duration) {
+	if precision <= 0 {
+		return
+	}
+	for _, info := range ingresses {
+		for _, host := range info.Hosts {
+			cert := host.Certificate
+			if cert == nil || cert.Expires == nil {
+				continue
+			}
+			truncated := cert.Expires.UTC().Truncate(precision)
+			cert.Expires = &truncated
 		}
+	}
+}
 
-		// Non-2xx status code
-		if attempt < maxRetries {
-			r.log.V(1).Info("retrying after non-success status", "status", resp.StatusCode, "attempt", attempt)
-			backoff := time.Duration(attempt) * 2 * time.Second
-			time.Sleep(backoff)
-			continue
+// annotateBusinessHours flags certificates that expire when nobody may be
+// around to renew them. The entries must be copies, as returned by GetAll.
+func annotateBusinessHours(ingresses []*cache.IngressInfo, hours config.BusinessHours) {
+	if hours.Location == nil {
+		return
+	}
+	for _, info := range ingresses {
+		for _, host := range info.Hosts {
+			cert := host.Certificate
+			if cert == nil || cert.Expires == nil {
+				continue
+			}
+			cert.ExpiresOnWeekend = hours.IsWeekend(*cert.Expires)
+			cert.ExpiresAfterHours = hours.IsAfterHours(*cert.Expires)
 		}
+	}
+}
+
+// deadLetter persists a report that could not be delivered
+func (r *HTTPReporter) deadLetter(payload []byte) {
+	if r.deadLetters == nil {
+		return
+	}
+	dropped, err := r.deadLetters.Enqueue(payload)
+	if err != nil {
+		r.log.Error(err, "failed to queue undelivered report")
+		return
+	}
+	if dropped > 0 {
+		r.log.Info("dead-letter queue full, dropped oldest reports", "dropped", dropped)
+	}
+}
 
-		return fmt.Errorf("received non-success status code: %d", resp.StatusCode)
+// replayDeadLetters resends queued reports in order, before the current report
+func (r *HTTPReporter) replayDeadLetters(ctx context.Context) {
+	if r.deadLetters == nil {
+		return
+	}
+	sent, err := r.deadLetters.Drain(func(payload []byte) error {
+		return r.sink.Send(ctx, payload)
+	})
+	if sent > 0 {
+		r.log.Info("replayed queued reports", "count", sent)
 	}
+	if err != nil {
+		r.log.V(1).Info("stopped replaying queued reports", "error", err.Error())
+	}
+}
 
-	return fmt.Errorf("failed to send report after %d attempts", maxRetries)
+// discardDeadLetters drops the reports left queued after a full report was
+// delivered. They could not be replayed and are superseded by it, so
+// replaying them later would only send the collector stale state.
+func (r *HTTPReporter) discardDeadLetters() {
+	if r.deadLetters == nil {
+		return
+	}
+	dropped, err := r.deadLetters.Clear()
+	if dropped > 0 {
+		r.log.Info("dropped queued reports superseded by a delivered report", "count", dropped)
+	}
+	if err != nil {
+		r.log.Error(err, "failed to drop superseded queued reports")
+	}
 }
@@ -0,0 +1,1269 @@
+package reporter
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
+)
+
+// collector is a stub report endpoint that can be switched between failing and healthy
+type collector struct {
+	mu      sync.Mutex
+	healthy bool
+	reports []Report
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var report Report
+	if err := json.Unmarshal(body, &report); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	c.reports = append(c.reports, report)
+	w.WriteHeader(http.StatusOK)
+}
+
+func (c *collector) setHealthy(healthy bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.healthy = healthy
+}
+
+func (c *collector) received() []Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Report(nil), c.reports...)
+}
+
+// newTestReporter creates a reporter posting to the given URL without retry delays
+func newTestReporter(endpoint string, ingressCache *cache.IngressCache) *HTTPReporter {
+	r := NewHTTPReporter(&config.Config{
+		ClusterName:    "test-cluster",
+		ReportEndpoint: endpoint,
+		ReportInterval: time.Minute,
+	}, ingressCache, logr.Discard())
+	r.sink.(*HTTPSink).retryBackoff = time.Millisecond
+	return r
+}
+
+func TestHTTPReporter_DeadLetterQueue(t *testing.T) {
+	stub := &collector{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	queue, err := NewDeadLetterQueue(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue() error = %v", err)
+	}
+	ingressCache := cache.NewIngressCache("test-cluster")
+	reporter := newTestReporter(server.URL, ingressCache).WithDeadLetterQueue(queue)
+	ctx := context.Background()
+
+	// Failed reports are queued
+	ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "first", Hosts: []cache.HostInfo{{Host: "a.local"}}})
+	if err := reporter.sendReport(ctx); err == nil {
+		t.Fatal("sendReport() expected error while endpoint is failing")
+	}
+	ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "second", Hosts: []cache.HostInfo{{Host: "b.local"}}})
+	if err := reporter.sendReport(ctx); err == nil {
+		t.Fatal("sendReport() expected error while endpoint is failing")
+	}
+	if n, _ := queue.Len(); n != 2 {
+		t.Fatalf("queued reports = %d, want 2", n)
+	}
+
+	// Recovery drains the queue in order, then sends the current report, so
+	// the latest report received is the current one
+	stub.setHealthy(true)
+	if err := reporter.sendReport(ctx); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+
+	reports := stub.received()
+	if len(reports) != 3 {
+		t.Fatalf("received %d reports, want 3", len(reports))
+	}
+	wantCounts := []int{1, 2, 2}
+	for i, want := range wantCounts {
+		if got := len(reports[i].Ingresses); got != want {
+			t.Errorf("report %d has %d ingresses, want %d", i, got, want)
+		}
+	}
+	if n, _ := queue.Len(); n != 0 {
+		t.Errorf("queued reports after recovery = %d, want 0", n)
+	}
+}
+
+func TestHTTPReporter_DeadLetterQueueSuperseded(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	queue, err := NewDeadLetterQueue(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("NewDeadLetterQueue() error = %v", err)
+	}
+	// The collector rejects the queued report, so replaying it fails
+	if _, err := queue.Enqueue([]byte("not a report")); err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "web", Hosts: []cache.HostInfo{{Host: "a.local"}}})
+	reporter := newTestReporter(server.URL, ingressCache).WithDeadLetterQueue(queue)
+
+	if err := reporter.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+	if got := len(stub.received()); got != 1 {
+		t.Errorf("received %d reports, want 1", got)
+	}
+	// The delivered report supersedes the one left in the queue
+	if n, _ := queue.Len(); n != 0 {
+		t.Errorf("queued reports = %d, want 0", n)
+	}
+}
+
+func TestHTTPReporter_NoDeadLetterQueue(t *testing.T) {
+	stub := &collector{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	reporter := newTestReporter(server.URL, cache.NewIngressCache("test-cluster"))
+	if err := reporter.sendReport(context.Background()); err == nil {
+		t.Fatal("sendReport() expected error while endpoint is failing")
+	}
+
+	stub.setHealthy(true)
+	if err := reporter.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+	if got := len(stub.received()); got != 1 {
+		t.Errorf("received %d reports, want 1", got)
+	}
+}
+
+func TestHTTPReporter_Tombstones(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("test-cluster").WithTombstones(time.Hour)
+	reporter := newTestReporter(server.URL, ingressCache)
+	ctx := context.Background()
+
+	ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "webapp", Hosts: []cache.HostInfo{{Host: "webapp.local"}}})
+	ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "api", Hosts: []cache.HostInfo{{Host: "api.local"}}})
+	if err := reporter.sendReport(ctx); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+	ingressCache.Delete("default", "webapp")
+	for range 2 {
+		if err := reporter.sendReport(ctx); err != nil {
+			t.Fatalf("sendReport() error = %v", err)
+		}
+	}
+
+	reports := stub.received()
+	if len(reports) != 3 {
+		t.Fatalf("received %d reports, want 3", len(reports))
+	}
+	tombstones := func(report Report) []string {
+		var deleted []string
+		for _, info := range report.Ingresses {
+			if info.Deleted {
+				deleted = append(deleted, info.Namespace+"/"+info.Name)
+			}
+		}
+		return deleted
+	}
+	for i, want := range [][]string{nil, {"default/webapp"}, nil} {
+		if got := tombstones(reports[i]); !slices.Equal(got, want) {
+			t.Errorf("report %d tombstones = %v, want %v", i+1, got, want)
+		}
+	}
+	if got := len(reports[2].Ingresses); got != 1 {
+		t.Errorf("last report has %d ingresses, want only the live one", got)
+	}
+}
+
+func TestHTTPReporter_Stats(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	recorder := stats.NewRecorder()
+	recorder.RecordReconcile(stats.KindIngress)
+	recorder.RecordReconcile(stats.KindSecret)
+	recorder.RecordReconcile(stats.KindSecret)
+
+	reporter := newTestReporter(server.URL, cache.NewIngressCache("test-cluster")).WithStats(recorder)
+	if err := reporter.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+
+	reports := stub.received()
+	if len(reports) != 1 {
+		t.Fatalf("received %d reports, want 1", len(reports))
+	}
+	counts := reports[0].ReconcileCounts
+	if counts[stats.KindIngress] != 1 || counts[stats.KindSecret] != 2 {
+		t.Errorf("ReconcileCounts = %v, want ingress=1 secret=2", counts)
+	}
+}
+
+func TestReport_LabelsJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		labels map[string]string
+		want   string
+	}{
+		{name: "no labels", want: `{"cluster":"test-cluster","plaintextHostCount":0,"uniqueCertificateCount":0,"ingresses":null}`},
+		{
+			name:   "labels",
+			labels: map[string]string{"region": "eu", "env": "prod"},
+			want: `{"cluster":"test-cluster","labels":{"env":"prod","region":"eu"},` +
+				`"plaintextHostCount":0,"uniqueCertificateCount":0,"ingresses":null}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(Report{Cluster: "test-cluster", Labels: tt.labels})
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(data) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", data, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPReporter_ReportLabels(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("test-cluster")
+	for _, name := range []string{"webapp", "api", "shop"} {
+		ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: name, Hosts: []cache.HostInfo{{Host: name + ".local"}}})
+	}
+	reportLabels := map[string]string{"region": "eu", "env": "prod"}
+	reporter := NewHTTPReporter(&config.Config{
+		ClusterName:     "test-cluster",
+		ReportEndpoint:  server.URL,
+		ReportInterval:  time.Minute,
+		ReportBatchSize: 2,
+		ReportLabels:    reportLabels,
+	}, ingressCache, logr.Discard())
+	if err := reporter.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+
+	reports := stub.received()
+	if len(reports) != 2 {
+		t.Fatalf("received %d reports, want 2 batches", len(reports))
+	}
+	for _, report := range reports {
+		if !maps.Equal(report.Labels, reportLabels) {
+			t.Errorf("batch %d: Labels = %v, want %v", report.Batch.Index, report.Labels, reportLabels)
+		}
+	}
+}
+
+func TestHTTPReporter_MinTLSVersion(t *testing.T) {
+	server := httptest.NewUnstartedServer(&collector{healthy: true})
+	server.TLS = &tls.Config{MinVersion: tls.VersionTLS10, MaxVersion: tls.VersionTLS10}
+	server.StartTLS()
+	defer server.Close()
+
+	reporter := newTestReporter(server.URL, cache.NewIngressCache("test-cluster"))
+	err := reporter.sendReport(context.Background())
+	if err == nil {
+		t.Fatal("sendReport() expected handshake error against a TLS 1.0 server")
+	}
+	if !strings.Contains(err.Error(), "protocol version") {
+		t.Errorf("sendReport() error = %v, want protocol version error", err)
+	}
+}
+
+func TestHTTPReporter_InsecureSkipVerify(t *testing.T) {
+	// httptest serves a certificate no client trusts, like a bootstrapping
+	// collector with a self-signed one
+	server := httptest.NewTLSServer(&collector{healthy: true})
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		insecure bool
+		wantErr  bool
+	}{
+		{name: "verified by default", insecure: false, wantErr: true},
+		{name: "skipped when opted in", insecure: true, wantErr: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{
+				ClusterName:              "test-cluster",
+				ReportEndpoint:           server.URL,
+				ReportInterval:           time.Minute,
+				ReportInsecureSkipVerify: tt.insecure,
+			}
+			reporter := NewHTTPReporter(cfg, cache.NewIngressCache("test-cluster"), logr.Discard())
+			sink := reporter.sink.(*HTTPSink)
+			sink.retryBackoff = time.Millisecond
+
+			_, client := sink.settings()
+			tlsConfig := client.Transport.(*http.Transport).TLSClientConfig
+			if tlsConfig.InsecureSkipVerify != tt.insecure {
+				t.Errorf("InsecureSkipVerify = %v, want %v", tlsConfig.InsecureSkipVerify, tt.insecure)
+			}
+			if tlsConfig.MinVersion != tls.VersionTLS12 {
+				t.Errorf("MinVersion = %#x, want TLS 1.2", tlsConfig.MinVersion)
+			}
+
+			err := reporter.sendReport(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("sendReport() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestHTTPSink_UpdateInsecureSkipVerify(t *testing.T) {
+	cfg := &config.Config{ReportEndpoint: "https://collector.example/report"}
+	sink := NewHTTPSink(cfg, logr.Discard())
+
+	updated := *cfg
+	updated.ReportInsecureSkipVerify = true
+	sink.Update(&updated)
+	_, client := sink.settings()
+	if !client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+		t.Error("InsecureSkipVerify = false after enabling it, want true")
+	}
+
+	sink.Update(cfg)
+	_, client = sink.settings()
+	if client.Transport.(*http.Transport).TLSClientConfig.InsecureSkipVerify {
+		t.Error("InsecureSkipVerify = true after disabling it, want false")
+	}
+}
+
+func TestNewHTTPClient_Proxy(t *testing.T) {
+	t.Setenv("HTTP_PROXY", "http://env-proxy.example:3128")
+	t.Setenv("HTTPS_PROXY", "http://env-proxy.example:3128")
+	t.Setenv("NO_PROXY", "internal.example")
+	explicit, _ := url.Parse("http://explicit-proxy.example:8080")
+
+	tests := []struct {
+		name     string
+		proxyURL *url.URL
+		endpoint string
+		want     string
+	}{
+		{name: "https from environment", endpoint: "https://collector.example/report", want: "http://env-proxy.example:3128"},
+		{name: "http from environment", endpoint: "http://collector.example/report", want: "http://env-proxy.example:3128"},
+		{name: "excluded by NO_PROXY", endpoint: "https://collector.internal.example/report", want: ""},
+		{
+			name:     "explicit proxy",
+			proxyURL: explicit,
+			endpoint: "https://collector.internal.example/report",
+			want:     "http://explicit-proxy.example:8080",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := newHTTPClient(0, false, tt.proxyURL).Transport.(*http.Transport)
+			req := httptest.NewRequest(http.MethodPost, tt.endpoint, nil)
+
+			proxyURL, err := transport.Proxy(req)
+			if err != nil {
+				t.Fatalf("Proxy() error = %v", err)
+			}
+			got := ""
+			if proxyURL != nil {
+				got = proxyURL.String()
+			}
+			if got != tt.want {
+				t.Errorf("Proxy() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPReporter_ThroughProxy(t *testing.T) {
+	var mu sync.Mutex
+	var proxied []string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// A forward proxy receives the absolute URL of the collector
+		mu.Lock()
+		proxied = append(proxied, r.URL.String())
+		mu.Unlock()
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	reporter := NewHTTPReporter(&config.Config{
+		ClusterName:    "test-cluster",
+		ReportEndpoint: "http://collector.example/report",
+		ReportInterval: time.Minute,
+		ReportProxyURL: proxyURL,
+	}, cache.NewIngressCache("test-cluster"), logr.Discard())
+	if err := reporter.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(proxied) != 1 || proxied[0] != "http://collector.example/report" {
+		t.Errorf("proxy received %v, want the report for http://collector.example/report", proxied)
+	}
+}
+
+func TestHTTPReporter_DeliveryStats(t *testing.T) {
+	stub := &collector{}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	reporter := newTestReporter(server.URL, cache.NewIngressCache("test-cluster"))
+	ctx := context.Background()
+
+	for range 2 {
+		if err := reporter.sendReport(ctx); err == nil {
+			t.Fatal("sendReport() expected error while endpoint is failing")
+		}
+	}
+	stats := reporter.DeliveryStats()
+	if stats.Failed != 2 || stats.ConsecutiveFailures != 2 || stats.Sent != 0 {
+		t.Errorf("after failures: %+v, want Failed=2 ConsecutiveFailures=2 Sent=0", stats)
+	}
+	if !stats.LastSuccess.IsZero() {
+		t.Errorf("LastSuccess = %v, want zero before any delivery", stats.LastSuccess)
+	}
+
+	stub.setHealthy(true)
+	before := time.Now()
+	if err := reporter.sendReport(ctx); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+	stats = reporter.DeliveryStats()
+	if stats.Sent != 1 || stats.Failed != 2 || stats.ConsecutiveFailures != 0 {
+		t.Errorf("after success: %+v, want Sent=1 Failed=2 ConsecutiveFailures=0", stats)
+	}
+	if stats.LastSuccess.Before(before) {
+		t.Errorf("LastSuccess = %v, want at or after %v", stats.LastSuccess, before)
+	}
+}
+
+// cancelledAfterContext is a context that is done from its nth Err check
+// on, cancelling report generation midway
+type cancelledAfterContext struct {
+	context.Context
+	checks, n int
+}
+
+func (c *cancelledAfterContext) Err() error {
+	c.checks++
+	if c.checks >= c.n {
+		return context.Canceled
+	}
+	return nil
+}
+
+func TestHTTPReporter_CancelledGeneration(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	ingressCache := cache.NewIngressCache("test-cluster")
+	for i := range 1000 {
+		ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "webapp-" + strconv.Itoa(i),
+			Hosts: []cache.HostInfo{{Host: "webapp.local"}}})
+	}
+	reporter := newTestReporter(server.URL, ingressCache)
+
+	// Cancelled while copying the cache
+	err := reporter.sendReport(&cancelledAfterContext{Context: context.Background(), n: 2})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("sendReport() error = %v, want context.Canceled", err)
+	}
+	if got := stub.received(); len(got) != 0 {
+		t.Errorf("collector received %d reports, want none", len(got))
+	}
+	if stats := reporter.DeliveryStats(); stats.Failed != 0 || !stats.LastComputed.IsZero() {
+		t.Errorf("after cancellation: %+v, want no failure and no computed report", stats)
+	}
+
+	// Cancelled between batches, after the cache was copied
+	cfg := *reporter.settings()
+	cfg.ReportBatchSize = 100
+	reporter.Update(&cfg)
+	// Copying 1000 entries checks the context 4 times, once every 256
+	ctx := &cancelledAfterContext{Context: context.Background(), n: 5}
+	if err := reporter.sendReport(ctx); !errors.Is(err, context.Canceled) {
+		t.Fatalf("sendReport() error = %v, want context.Canceled", err)
+	}
+	if got := stub.received(); len(got) != 0 {
+		t.Errorf("collector received %d batches, want none", len(got))
+	}
+
+	if err := reporter.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+	if got := stub.received(); len(got) != 10 {
+		t.Errorf("collector received %d batches, want 10", len(got))
+	}
+}
+
+func TestHTTPReporter_CheckEndpoint(t *testing.T) {
+	// Collectors usually reject HEAD, which still proves reachability
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}))
+	reporter := newTestReporter(server.URL, cache.NewIngressCache("test-cluster"))
+	if err := reporter.CheckEndpoint(context.Background()); err != nil {
+		t.Errorf("CheckEndpoint() error = %v, want nil for a reachable endpoint", err)
+	}
+
+	server.Close()
+	if err := reporter.CheckEndpoint(context.Background()); err == nil {
+		t.Error("CheckEndpoint() expected error for an unreachable endpoint")
+	}
+}
+
+// waitForReports polls the collector until it has received at least n reports
+func waitForReports(t *testing.T, stub *collector, n int, timeout time.Duration) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for len(stub.received()) < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("received %d reports within %v, want at least %d", len(stub.received()), timeout, n)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestHTTPReporter_InitialDelay(t *testing.T) {
+	tests := []struct {
+		name   string
+		jitter float64
+		random float64
+		want   time.Duration
+	}{
+		{name: "disabled", jitter: 0, random: 0.5, want: 0},
+		{name: "lowest", jitter: 0.1, random: 0, want: 0},
+		{name: "halfway", jitter: 0.1, random: 0.5, want: 3 * time.Second},
+		{name: "bounded by jitter", jitter: 0.1, random: 0.999, want: 5994 * time.Millisecond},
+		{name: "whole interval", jitter: 1, random: 0.5, want: 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reporter := NewHTTPReporter(&config.Config{}, cache.NewIngressCache("test-cluster"), logr.Discard())
+			reporter.random = func() float64 { return tt.random }
+
+			got := reporter.initialDelay(&config.Config{ReportInterval: time.Minute, ReportInitialJitter: tt.jitter})
+			if got != tt.want {
+				t.Errorf("initialDelay() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPReporter_InitialReportDelayed(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	// The first report waits for half of the jitter, 100ms, and the next one
+	// follows an interval later
+	reporter := NewHTTPReporter(&config.Config{
+		ClusterName:         "test-cluster",
+		ReportEndpoint:      server.URL,
+		ReportInterval:      time.Second,
+		ReportInitialJitter: 0.2,
+	}, cache.NewIngressCache("test-cluster"), logr.Discard())
+	reporter.random = func() float64 { return 0.5 }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	started := time.Now()
+	go reporter.Start(ctx)
+
+	time.Sleep(50 * time.Millisecond)
+	if got := len(stub.received()); got != 0 {
+		t.Fatalf("received %d reports during the initial delay, want 0", got)
+	}
+	waitForReports(t, stub, 1, time.Second)
+	if elapsed := time.Since(started); elapsed < 100*time.Millisecond || elapsed >= time.Second {
+		t.Errorf("initial report sent after %v, want between the 100ms delay and the 1s interval", elapsed)
+	}
+}
+
+func TestHTTPReporter_UpdateInterval(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	cfg := &config.Config{
+		ClusterName:    "test-cluster",
+		ReportEndpoint: server.URL,
+		ReportInterval: time.Hour,
+	}
+	reporter := NewHTTPReporter(cfg, cache.NewIngressCache("test-cluster"), logr.Discard())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reporter.Start(ctx)
+
+	// Only the initial report is sent with an hourly interval
+	waitForReports(t, stub, 1, time.Second)
+
+	updated := *cfg
+	updated.ReportInterval = 10 * time.Millisecond
+	updated.ClusterName = "renamed-cluster"
+	reporter.Update(&updated)
+
+	waitForReports(t, stub, 4, 2*time.Second)
+	reports := stub.received()
+	if got := reports[len(reports)-1].Cluster; got != "renamed-cluster" {
+		t.Errorf("Cluster = %v, want renamed-cluster after update", got)
+	}
+}
+
+func TestHTTPReporter_FinalReport(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	reporter := newTestReporter(server.URL, cache.NewIngressCache("test-cluster"))
+	cfg := *reporter.settings()
+	cfg.ReportInterval = time.Hour
+	cfg.ReportShutdownTimeout = time.Second
+	reporter.Update(&cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reporter.Start(ctx)
+	}()
+	waitForReports(t, stub, 1, time.Second)
+
+	// Cancelling, as on SIGTERM, sends a final report before Start returns
+	cancel()
+	<-done
+	if got := len(stub.received()); got != 2 {
+		t.Errorf("received %d reports, want the initial and the final one", got)
+	}
+}
+
+func TestHTTPReporter_FinalReportTimeout(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Only the initial report is answered, the collector then hangs
+		if requests.Add(1) > 1 {
+			<-release
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	defer close(release)
+
+	reporter := newTestReporter(server.URL, cache.NewIngressCache("test-cluster"))
+	cfg := *reporter.settings()
+	cfg.ReportInterval = time.Hour
+	cfg.ReportShutdownTimeout = 50 * time.Millisecond
+	reporter.Update(&cfg)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		reporter.Start(ctx)
+	}()
+	for requests.Load() < 1 {
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Start() did not return within the shutdown timeout")
+	}
+	if got := requests.Load(); got < 2 {
+		t.Errorf("collector received %d requests, want the final report attempted", got)
+	}
+}
+
+func TestHTTPReporter_TriggerReport(t *testing.T) {
+	stub := &collector{healthy: true}
+	var inFlight, overlapped atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if inFlight.Add(1) > 1 {
+			overlapped.Add(1)
+		}
+		defer inFlight.Add(-1)
+		time.Sleep(5 * time.Millisecond)
+		stub.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	reporter := NewHTTPReporter(&config.Config{
+		ClusterName:    "test-cluster",
+		ReportEndpoint: server.URL,
+		ReportInterval: time.Hour,
+	}, cache.NewIngressCache("test-cluster"), logr.Discard())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go reporter.Start(ctx)
+	waitForReports(t, stub, 1, time.Second)
+
+	// A trigger sends a report well before the hourly tick
+	if got := reporter.TriggerReport(); got != 1 {
+		t.Errorf("TriggerReport() = %d, want 1", got)
+	}
+	waitForReports(t, stub, 2, time.Second)
+
+	// Concurrent triggers are serialized on the reporting loop
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reporter.TriggerReport()
+		}()
+	}
+	wg.Wait()
+	waitForReports(t, stub, 3, time.Second)
+	time.Sleep(50 * time.Millisecond)
+
+	if n := overlapped.Load(); n != 0 {
+		t.Errorf("%d reports overlapped another one", n)
+	}
+	if got := len(stub.received()); got > 12 {
+		t.Errorf("received %d reports, want at most 12", got)
+	}
+}
+
+func TestHTTPReporter_SuccessStatusCodes(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		codes    config.StatusCodeSet
+		wantSent bool
+	}{
+		{
+			name:     "302 configured as success",
+			status:   http.StatusFound,
+			codes:    config.StatusCodeSet{http.StatusFound: true},
+			wantSent: true,
+		},
+		{name: "500 fails by default", status: http.StatusInternalServerError, wantSent: false},
+		{name: "204 succeeds by default", status: http.StatusNoContent, wantSent: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			reporter := newTestReporter(server.URL, cache.NewIngressCache("test-cluster"))
+			cfg := *reporter.settings()
+			cfg.ReportSuccessStatusCodes = tt.codes
+			reporter.Update(&cfg)
+
+			err := reporter.sendReport(context.Background())
+			if (err == nil) != tt.wantSent {
+				t.Fatalf("sendReport() error = %v, want sent %v", err, tt.wantSent)
+			}
+			wantRequests := 1
+			if !tt.wantSent {
+				wantRequests = 3 // retried
+			}
+			if requests != wantRequests {
+				t.Errorf("collector received %d requests, want %d", requests, wantRequests)
+			}
+		})
+	}
+}
+
+func TestHTTPReporter_RequestHeaders(t *testing.T) {
+	Version = "v1.2.0"
+	t.Cleanup(func() { Version = "" })
+
+	var mu sync.Mutex
+	var headers []http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		headers = append(headers, r.Header.Clone())
+		if len(headers) == 1 {
+			// Fail the first attempt so that the first report is retried
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	reporter := newTestReporter(server.URL, cache.NewIngressCache("test-cluster"))
+	for range 2 {
+		if err := reporter.sendReport(context.Background()); err != nil {
+			t.Fatalf("sendReport() error = %v", err)
+		}
+	}
+
+	if len(headers) != 3 {
+		t.Fatalf("collector received %d requests, want 3", len(headers))
+	}
+	for _, header := range headers {
+		if got, want := header.Get("User-Agent"), "cert-observer/v1.2.0 (cluster test-cluster)"; got != want {
+			t.Errorf("User-Agent = %q, want %q", got, want)
+		}
+		if header.Get(RequestIDHeader) == "" {
+			t.Errorf("request without %s", RequestIDHeader)
+		}
+	}
+	if first, retry := headers[0].Get(RequestIDHeader), headers[1].Get(RequestIDHeader); first != retry {
+		t.Errorf("retry request id = %q, want %q of the first attempt", retry, first)
+	}
+	if first, next := headers[0].Get(RequestIDHeader), headers[2].Get(RequestIDHeader); first == next {
+		t.Errorf("next report reuses request id %q", next)
+	}
+
+	// REPORT_USER_AGENT replaces the default
+	cfg := *reporter.settings()
+	cfg.ReportUserAgent = "fleet-agent/7"
+	reporter.Update(&cfg)
+	if err := reporter.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+	if got := headers[3].Get("User-Agent"); got != "fleet-agent/7" {
+		t.Errorf("User-Agent = %q, want fleet-agent/7", got)
+	}
+}
+
+func TestHTTPReporter_ResponseAcceptance(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantSent      bool
+		wantReceiptID string
+	}{
+		{name: "accepted", body: `{"accepted": true, "id": "r-123"}`, wantSent: true, wantReceiptID: "r-123"},
+		{name: "rejected", body: `{"accepted": false, "id": "r-124"}`, wantSent: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests int
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, tt.body)
+			}))
+			defer server.Close()
+
+			reporter := newTestReporter(server.URL, cache.NewIngressCache("test-cluster"))
+			cfg := *reporter.settings()
+			cfg.ReportAcceptance = &config.ResponseAcceptance{
+				Field:          []string{"accepted"},
+				Value:          "true",
+				ReceiptIDField: []string{"id"},
+			}
+			reporter.Update(&cfg)
+
+			err := reporter.sendReport(context.Background())
+			if (err == nil) != tt.wantSent {
+				t.Fatalf("sendReport() error = %v, want sent %v", err, tt.wantSent)
+			}
+			delivery := reporter.DeliveryStats()
+			if delivery.LastReceiptID != tt.wantReceiptID {
+				t.Errorf("LastReceiptID = %q, want %q", delivery.LastReceiptID, tt.wantReceiptID)
+			}
+			if tt.wantSent {
+				return
+			}
+			if requests != 3 {
+				t.Errorf("collector received %d requests, want 3 for a retried rejection", requests)
+			}
+			if delivery.Failed != 1 {
+				t.Errorf("Failed = %d, want 1", delivery.Failed)
+			}
+		})
+	}
+}
+
+func TestAnnotateIntervals(t *testing.T) {
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		expires := now.Add(d)
+		return &expires
+	}
+
+	tests := []struct {
+		name          string
+		expires       *time.Time
+		wantIntervals *int
+		wantWithin    bool
+	}{
+		{name: "within the next interval", expires: at(30 * time.Minute), wantIntervals: ptr(0), wantWithin: true},
+		{name: "after one interval", expires: at(90 * time.Minute), wantIntervals: ptr(1)},
+		{name: "exactly one interval", expires: at(time.Hour), wantIntervals: ptr(1)},
+		{name: "many intervals", expires: at(30 * 24 * time.Hour), wantIntervals: ptr(720)},
+		{name: "already expired", expires: at(-time.Hour), wantIntervals: ptr(0), wantWithin: true},
+		{name: "unknown expiry", expires: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := &cache.CertificateInfo{Name: "webapp-tls", Expires: tt.expires}
+			ingresses := []*cache.IngressInfo{{
+				Namespace: "default",
+				Name:      "webapp",
+				Hosts:     []cache.HostInfo{{Host: "webapp.local", Certificate: cert}, {Host: "plain.local"}},
+			}}
+
+			annotateIntervals(ingresses, time.Hour, now)
+
+			switch {
+			case tt.wantIntervals == nil && cert.IntervalsUntilExpiry != nil:
+				t.Errorf("IntervalsUntilExpiry = %d, want unset", *cert.IntervalsUntilExpiry)
+			case tt.wantIntervals != nil && cert.IntervalsUntilExpiry == nil:
+				t.Errorf("IntervalsUntilExpiry unset, want %d", *tt.wantIntervals)
+			case tt.wantIntervals != nil && *cert.IntervalsUntilExpiry != *tt.wantIntervals:
+				t.Errorf("IntervalsUntilExpiry = %d, want %d", *cert.IntervalsUntilExpiry, *tt.wantIntervals)
+			}
+			if cert.ExpiresWithinNextInterval != tt.wantWithin {
+				t.Errorf("ExpiresWithinNextInterval = %v, want %v", cert.ExpiresWithinNextInterval, tt.wantWithin)
+			}
+		})
+	}
+}
+
+func TestAnnotateBusinessHours(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatalf("failed to load time zone: %v", err)
+	}
+	hours := config.BusinessHours{Location: newYork, Start: 9 * time.Hour, End: 17 * time.Hour}
+
+	tests := []struct {
+		name          string
+		expires       time.Time
+		wantWeekend   bool
+		wantAfterHour bool
+	}{
+		{name: "weekday business hours", expires: time.Date(2026, 1, 14, 15, 0, 0, 0, time.UTC)},
+		{name: "weekend", expires: time.Date(2026, 1, 17, 15, 0, 0, 0, time.UTC), wantWeekend: true},
+		{name: "weekday after hours", expires: time.Date(2026, 1, 13, 23, 30, 0, 0, time.UTC), wantAfterHour: true},
+		{name: "end of business hours", expires: time.Date(2026, 1, 14, 22, 0, 0, 0, time.UTC), wantAfterHour: true},
+		// Monday in UTC, but still Sunday evening in New York
+		{
+			name:          "weekend in the time zone",
+			expires:       time.Date(2026, 1, 19, 3, 0, 0, 0, time.UTC),
+			wantWeekend:   true,
+			wantAfterHour: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := &cache.CertificateInfo{Name: "webapp-tls", Expires: &tt.expires}
+			ingresses := []*cache.IngressInfo{{
+				Namespace: "default",
+				Name:      "webapp",
+				Hosts:     []cache.HostInfo{{Host: "webapp.local", Certificate: cert}, {Host: "plain.local"}},
+			}}
+
+			annotateBusinessHours(ingresses, hours)
+
+			if cert.ExpiresOnWeekend != tt.wantWeekend {
+				t.Errorf("ExpiresOnWeekend = %v, want %v", cert.ExpiresOnWeekend, tt.wantWeekend)
+			}
+			if cert.ExpiresAfterHours != tt.wantAfterHour {
+				t.Errorf("ExpiresAfterHours = %v, want %v", cert.ExpiresAfterHours, tt.wantAfterHour)
+			}
+		})
+	}
+}
+
+func TestHTTPReporter_IntervalsInReport(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	expires := time.Now().Add(90 * time.Second)
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts:     []cache.HostInfo{{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", Expires: &expires}}},
+	})
+
+	r := newTestReporter(server.URL, ingressCache)
+	if err := r.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+
+	cert := stub.received()[0].Ingresses[0].Hosts[0].Certificate
+	if cert.IntervalsUntilExpiry == nil || *cert.IntervalsUntilExpiry != 1 {
+		t.Errorf("IntervalsUntilExpiry = %v, want 1 with a one minute interval", cert.IntervalsUntilExpiry)
+	}
+	if cert.ExpiresWithinNextInterval {
+		t.Error("ExpiresWithinNextInterval = true, want false")
+	}
+	// The cache itself is not annotated
+	if got := ingressCache.GetAll()[0].Hosts[0].Certificate.IntervalsUntilExpiry; got != nil {
+		t.Errorf("cached IntervalsUntilExpiry = %d, want unset", *got)
+	}
+}
+
+func TestAnnotateDaysUntilExpiry(t *testing.T) {
+	now := time.Date(2025, time.June, 1, 12, 0, 0, 0, time.UTC)
+	at := func(d time.Duration) *time.Time {
+		expires := now.Add(d)
+		return &expires
+	}
+
+	tests := []struct {
+		name     string
+		expires  *time.Time
+		wantDays *int
+	}{
+		{name: "less than a day", expires: at(23 * time.Hour), wantDays: ptr(0)},
+		{name: "rounds toward zero", expires: at(36 * time.Hour), wantDays: ptr(1)},
+		{name: "whole days", expires: at(30 * 24 * time.Hour), wantDays: ptr(30)},
+		{name: "expired less than a day ago", expires: at(-time.Hour), wantDays: ptr(0)},
+		{name: "expired rounds toward zero", expires: at(-36 * time.Hour), wantDays: ptr(-1)},
+		{name: "unknown expiry", expires: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cert := &cache.CertificateInfo{Name: "webapp-tls", Expires: tt.expires}
+			ingresses := []*cache.IngressInfo{{
+				Namespace: "default",
+				Name:      "webapp",
+				Hosts:     []cache.HostInfo{{Host: "webapp.local", Certificate: cert}, {Host: "plain.local"}},
+			}}
+
+			annotateDaysUntilExpiry(ingresses, now)
+
+			switch {
+			case tt.wantDays == nil && cert.DaysUntilExpiry != nil:
+				t.Errorf("DaysUntilExpiry = %d, want unset", *cert.DaysUntilExpiry)
+			case tt.wantDays != nil && cert.DaysUntilExpiry == nil:
+				t.Errorf("DaysUntilExpiry unset, want %d", *tt.wantDays)
+			case tt.wantDays != nil && *cert.DaysUntilExpiry != *tt.wantDays:
+				t.Errorf("DaysUntilExpiry = %d, want %d", *cert.DaysUntilExpiry, *tt.wantDays)
+			}
+		})
+	}
+}
+
+func TestHTTPReporter_DaysUntilExpiryInReport(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	expires := time.Now().Add(10*24*time.Hour + time.Hour)
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts:     []cache.HostInfo{{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", Expires: &expires}}},
+	})
+
+	r := newTestReporter(server.URL, ingressCache)
+	if err := r.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+	report := stub.received()[0]
+	if report.GeneratedAt.IsZero() {
+		t.Error("GeneratedAt is unset")
+	}
+	if got := report.Ingresses[0].Hosts[0].Certificate.DaysUntilExpiry; got != nil {
+		t.Errorf("DaysUntilExpiry = %d, want unset when disabled", *got)
+	}
+
+	enabled := *r.settings()
+	enabled.ReportDaysUntilExpiry = true
+	r.Update(&enabled)
+	if err := r.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+	report = stub.received()[1]
+	cert := report.Ingresses[0].Hosts[0].Certificate
+	if cert.DaysUntilExpiry == nil || *cert.DaysUntilExpiry != 10 {
+		t.Errorf("DaysUntilExpiry = %v, want 10", cert.DaysUntilExpiry)
+	}
+	// Collectors can recompute the value from the report itself
+	if got := int(cert.Expires.Sub(report.GeneratedAt) / (24 * time.Hour)); got != *cert.DaysUntilExpiry {
+		t.Errorf("days from GeneratedAt = %d, want %d", got, *cert.DaysUntilExpiry)
+	}
+}
+
+func TestTruncateExpiry(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Fatalf("failed to load location: %v", err)
+	}
+	expires := time.Date(2025, time.June, 1, 1, 42, 17, 500, berlin)
+
+	tests := []struct {
+		name      string
+		precision time.Duration
+		want      time.Time
+	}{
+		{name: "exact", precision: 0, want: expires},
+		{name: "hour", precision: time.Hour, want: time.Date(2025, time.May, 31, 23, 0, 0, 0, time.UTC)},
+		{name: "day", precision: 24 * time.Hour, want: time.Date(2025, time.May, 31, 0, 0, 0, 0, time.UTC)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exact := expires
+			cert := &cache.CertificateInfo{Name: "webapp-tls", Expires: &exact}
+			ingresses := []*cache.IngressInfo{{
+				Namespace: "default",
+				Name:      "webapp",
+				Hosts: []cache.HostInfo{
+					{Host: "webapp.local", Certificate: cert},
+					{Host: "pending.local", Certificate: &cache.CertificateInfo{Name: "pending-tls"}},
+					{Host: "plain.local"},
+				},
+			}}
+
+			truncateExpiry(ingresses, tt.precision)
+
+			if !cert.Expires.Equal(tt.want) {
+				t.Errorf("Expires = %v, want %v", cert.Expires, tt.want)
+			}
+			if !exact.Equal(expires) {
+				t.Error("truncation modified the original expiry")
+			}
+		})
+	}
+}
+
+func TestHTTPReporter_ExpiryPrecision(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	expires := time.Now().Add(90 * 24 * time.Hour)
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{
+		Namespace: "default",
+		Name:      "webapp",
+		Hosts:     []cache.HostInfo{{Host: "webapp.local", Certificate: &cache.CertificateInfo{Name: "webapp-tls", Expires: &expires}}},
+	})
+
+	r := newTestReporter(server.URL, ingressCache)
+	daily := *r.settings()
+	daily.ReportExpiryPrecision = 24 * time.Hour
+	r.Update(&daily)
+	if err := r.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+
+	got := stub.received()[0].Ingresses[0].Hosts[0].Certificate.Expires
+	want := expires.UTC().Truncate(24 * time.Hour)
+	if got == nil || !got.Equal(want) {
+		t.Errorf("Expires = %v, want %v", got, want)
+	}
+	// The cache keeps the exact expiry
+	if cached := ingressCache.GetAll()[0].Hosts[0].Certificate.Expires; !cached.Equal(expires) {
+		t.Errorf("cached Expires = %v, want %v", cached, expires)
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+func TestHTTPReporter_DryRun(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var logged []string
+	logger := funcr.New(func(prefix, args string) {
+		logged = append(logged, args)
+	}, funcr.Options{})
+
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{Namespace: "default", Name: "webapp"})
+	r := NewHTTPReporter(&config.Config{
+		ClusterName:    "test-cluster",
+		ReportEndpoint: server.URL,
+		ReportInterval: time.Minute,
+		DryRun:         true,
+	}, ingressCache, logger)
+
+	if err := r.sendReport(context.Background()); err != nil {
+		t.Fatalf("sendReport() error = %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("received %d requests in dry-run mode, want 0", requests)
+	}
+
+	delivery := r.DeliveryStats()
+	if delivery.LastComputed.IsZero() {
+		t.Error("LastComputed not updated in dry-run mode")
+	}
+	if delivery.Sent != 0 || delivery.Failed != 0 {
+		t.Errorf("Sent = %d, Failed = %d, want no deliveries recorded", delivery.Sent, delivery.Failed)
+	}
+	if len(logged) != 1 || !strings.Contains(logged[0], `\"name\": \"webapp\"`) {
+		t.Errorf("logged %q, want the pretty-printed report", logged)
+	}
+}
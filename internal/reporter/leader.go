@@ -0,0 +1,28 @@
+package reporter
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+)
+
+// LeaderRunnable runs an HTTPReporter as a manager.Runnable that needs leader
+// election. With leader election enabled only the elected leader reports,
+// so several replicas don't send duplicate reports, while all of them keep
+// their caches current to take over. Without it the reporter runs right away.
+type LeaderRunnable struct {
+	Reporter *HTTPReporter
+}
+
+var _ manager.LeaderElectionRunnable = LeaderRunnable{}
+
+// Start runs the reporter until the context is cancelled
+func (l LeaderRunnable) Start(ctx context.Context) error {
+	l.Reporter.Start(ctx)
+	return nil
+}
+
+// NeedLeaderElection returns true so that only the leader reports
+func (l LeaderRunnable) NeedLeaderElection() bool {
+	return true
+}
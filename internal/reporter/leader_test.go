@@ -0,0 +1,36 @@
+package reporter
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+)
+
+func TestLeaderRunnable(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	runnable := LeaderRunnable{Reporter: newTestReporter(server.URL, cache.NewIngressCache("test-cluster"))}
+	if !runnable.NeedLeaderElection() {
+		t.Error("NeedLeaderElection() = false, want true")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- runnable.Start(ctx) }()
+
+	waitForReports(t, stub, 1, time.Second)
+	cancel()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Start() error = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Start() did not return after the context was cancelled")
+	}
+}
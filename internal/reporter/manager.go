@@ -0,0 +1,158 @@
+package reporter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+	"github.com/ugurcancaykara/cert-observer/internal/stats"
+)
+
+// Manager runs one HTTPReporter per ClusterObserver. Each reporter shares
+// the ingress cache but only reports the entries in its observer's scope.
+// It implements manager.Runnable; reporters applied before it starts are
+// started along with it.
+type Manager struct {
+	mu sync.Mutex
+	// ctx is the parent of all reporter contexts; nil until Start
+	ctx       context.Context
+	reporters map[types.NamespacedName]*managedReporter
+	cache     *cache.IngressCache
+	log       logr.Logger
+	// stats provides observer uptime and reconcile counts; nil when disabled
+	stats *stats.Recorder
+}
+
+// managedReporter is a reporter and the means to stop it
+type managedReporter struct {
+	reporter *HTTPReporter
+	// cancel and done are nil until the reporter is started
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+}
+
+// NewManager creates a Manager reporting from the given cache
+func NewManager(ingressCache *cache.IngressCache, log logr.Logger) *Manager {
+	return &Manager{
+		reporters: make(map[types.NamespacedName]*managedReporter),
+		cache:     ingressCache,
+		log:       log,
+	}
+}
+
+// WithStats includes observer uptime and reconcile counts in each report
+func (m *Manager) WithStats(recorder *stats.Recorder) *Manager {
+	m.stats = recorder
+	return m
+}
+
+// Apply starts a reporter for the observer, or reconfigures its running one
+func (m *Manager) Apply(key types.NamespacedName, cfg *config.Config) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if managed, ok := m.reporters[key]; ok {
+		managed.reporter.Update(cfg)
+		return
+	}
+
+	managed := &managedReporter{
+		reporter: NewHTTPReporter(cfg, m.cache, m.log.WithValues("observer", key)).
+			WithStats(m.stats).
+			WithConfigScope(),
+	}
+	m.reporters[key] = managed
+	if m.ctx != nil {
+		m.start(managed)
+	}
+}
+
+// DeliveryStats returns the delivery counters of the observer's reporter, or
+// false if the observer has no reporter
+func (m *Manager) DeliveryStats(key types.NamespacedName) (DeliveryStats, bool) {
+	m.mu.Lock()
+	managed, ok := m.reporters[key]
+	m.mu.Unlock()
+
+	if !ok {
+		return DeliveryStats{}, false
+	}
+	return managed.reporter.DeliveryStats(), true
+}
+
+// TriggerReport asks every reporter to send a report now and returns how
+// many were triggered
+func (m *Manager) TriggerReport() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, managed := range m.reporters {
+		managed.reporter.TriggerReport()
+	}
+	return len(m.reporters)
+}
+
+// Remove stops the observer's reporter and waits for it to exit. It is a
+// no-op when the observer has no reporter.
+func (m *Manager) Remove(key types.NamespacedName) {
+	m.mu.Lock()
+	managed, ok := m.reporters[key]
+	delete(m.reporters, key)
+	m.mu.Unlock()
+
+	if !ok || managed.cancel == nil {
+		return
+	}
+	managed.cancel(errReporterRemoved)
+	<-managed.done
+	m.log.Info("stopped reporter", "observer", key)
+}
+
+// Start starts the reporters applied so far, then runs reporters as they are
+// applied until ctx is cancelled. It waits for all reporters to exit before
+// returning.
+func (m *Manager) Start(ctx context.Context) error {
+	m.mu.Lock()
+	m.ctx = ctx
+	for _, managed := range m.reporters {
+		m.start(managed)
+	}
+	m.mu.Unlock()
+
+	<-ctx.Done()
+
+	// Reporter contexts derive from ctx, so they are all stopping already
+	m.mu.Lock()
+	var stopping []*managedReporter
+	for key, managed := range m.reporters {
+		if managed.cancel != nil {
+			stopping = append(stopping, managed)
+		}
+		delete(m.reporters, key)
+	}
+	m.mu.Unlock()
+
+	for _, managed := range stopping {
+		<-managed.done
+	}
+	return nil
+}
+
+// NeedLeaderElection returns true so that only the leader reports
+func (m *Manager) NeedLeaderElection() bool {
+	return true
+}
+
+// start runs the reporter in its own goroutine. m.mu must be held.
+func (m *Manager) start(managed *managedReporter) {
+	ctx, cancel := context.WithCancelCause(m.ctx)
+	managed.cancel = cancel
+	managed.done = make(chan struct{})
+	go func() {
+		defer close(managed.done)
+		managed.reporter.Start(ctx)
+	}()
+}
@@ -0,0 +1,188 @@
+package reporter
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/manager"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+func TestManager_ScopedReporters(t *testing.T) {
+	ingressCache := cache.NewIngressCache("test-cluster")
+	ingressCache.Add(&cache.IngressInfo{Namespace: "team-a", Name: "shop", Labels: map[string]string{"observe": "true"}})
+	ingressCache.Add(&cache.IngressInfo{Namespace: "team-a", Name: "legacy"})
+	ingressCache.Add(&cache.IngressInfo{Namespace: "team-b", Name: "api"})
+
+	teamA, teamB := &collector{healthy: true}, &collector{healthy: true}
+	serverA, serverB := httptest.NewServer(teamA), httptest.NewServer(teamB)
+	defer serverA.Close()
+	defer serverB.Close()
+
+	manager := NewManager(ingressCache, logr.Discard())
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		_ = manager.Start(ctx)
+	}()
+
+	keyA := types.NamespacedName{Namespace: "team-a", Name: "observer"}
+	manager.Apply(keyA, &config.Config{
+		ClusterName:     "team-a-cluster",
+		ReportEndpoint:  serverA.URL,
+		ReportInterval:  time.Hour,
+		WatchNamespaces: []string{"team-a"},
+		IngressSelector: labels.SelectorFromSet(labels.Set{"observe": "true"}),
+	})
+	keyB := types.NamespacedName{Namespace: "team-b", Name: "observer"}
+	manager.Apply(keyB, &config.Config{
+		ClusterName:     "team-b-cluster",
+		ReportEndpoint:  serverB.URL,
+		ReportInterval:  time.Hour,
+		WatchNamespaces: []string{"team-b"},
+	})
+
+	waitForReports(t, teamA, 1, time.Second)
+	waitForReports(t, teamB, 1, time.Second)
+	if got := reportedNames(teamA.received()[0]); len(got) != 1 || got[0] != "team-a/shop" {
+		t.Errorf("team-a report = %v, want [team-a/shop]", got)
+	}
+	if got := reportedNames(teamB.received()[0]); len(got) != 1 || got[0] != "team-b/api" {
+		t.Errorf("team-b report = %v, want [team-b/api]", got)
+	}
+
+	// Reconfigure team-b to report every few milliseconds
+	manager.Apply(keyB, &config.Config{
+		ClusterName:     "team-b-renamed",
+		ReportEndpoint:  serverB.URL,
+		ReportInterval:  10 * time.Millisecond,
+		WatchNamespaces: []string{"team-b"},
+	})
+	waitForReports(t, teamB, 3, 2*time.Second)
+	if got := teamB.received()[2].Cluster; got != "team-b-renamed" {
+		t.Errorf("Cluster = %v, want team-b-renamed after update", got)
+	}
+
+	// Removing team-b stops its reporter before returning
+	manager.Remove(keyB)
+	if _, ok := manager.reporters[keyB]; ok {
+		t.Error("removed reporter is still managed")
+	}
+	// A request cancelled by the removal may still reach the stub, so let
+	// it settle before counting
+	time.Sleep(20 * time.Millisecond)
+	sent := len(teamB.received())
+	time.Sleep(50 * time.Millisecond)
+	if got := len(teamB.received()); got != sent {
+		t.Errorf("received %d reports after removal, want %d", got, sent)
+	}
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(time.Second):
+		t.Fatal("Start() did not return after the context was cancelled")
+	}
+	if len(manager.reporters) != 0 {
+		t.Errorf("%d reporters still managed after shutdown", len(manager.reporters))
+	}
+}
+
+func TestManager_ApplyBeforeStart(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	manager := NewManager(cache.NewIngressCache("test-cluster"), logr.Discard())
+	key := types.NamespacedName{Namespace: "default", Name: "observer"}
+	manager.Apply(key, &config.Config{
+		ClusterName:    "test-cluster",
+		ReportEndpoint: server.URL,
+		ReportInterval: time.Hour,
+	})
+
+	// Nothing is reported until the manager starts
+	time.Sleep(20 * time.Millisecond)
+	if got := len(stub.received()); got != 0 {
+		t.Fatalf("received %d reports before Start, want 0", got)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() { _ = manager.Start(ctx) }()
+	waitForReports(t, stub, 1, time.Second)
+
+	// Triggering reaches every managed reporter
+	if got := manager.TriggerReport(); got != 1 {
+		t.Errorf("TriggerReport() = %d, want 1", got)
+	}
+	waitForReports(t, stub, 2, time.Second)
+
+	// Removing an unknown observer is a no-op
+	manager.Remove(types.NamespacedName{Namespace: "default", Name: "unknown"})
+}
+
+func TestManager_FinalReports(t *testing.T) {
+	stub := &collector{healthy: true}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	manager := NewManager(cache.NewIngressCache("test-cluster"), logr.Discard())
+	removed := types.NamespacedName{Namespace: "default", Name: "removed"}
+	kept := types.NamespacedName{Namespace: "default", Name: "kept"}
+	for _, key := range []types.NamespacedName{removed, kept} {
+		manager.Apply(key, &config.Config{
+			ClusterName:           key.Name,
+			ReportEndpoint:        server.URL,
+			ReportInterval:        time.Hour,
+			ReportShutdownTimeout: time.Second,
+		})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_ = manager.Start(ctx)
+	}()
+	waitForReports(t, stub, 2, time.Second)
+
+	// A reporter removed with its observer sends no final report
+	manager.Remove(removed)
+	// Shutting down sends one for the remaining reporter
+	cancel()
+	<-done
+
+	clusters := make(map[string]int)
+	for _, report := range stub.received() {
+		clusters[report.Cluster]++
+	}
+	if clusters["removed"] != 1 || clusters["kept"] != 2 {
+		t.Errorf("reports per observer = %v, want removed:1 kept:2", clusters)
+	}
+}
+
+// reportedNames returns the namespace/name of every ingress in a report
+func reportedNames(report Report) []string {
+	var names []string
+	for _, info := range report.Ingresses {
+		names = append(names, info.Namespace+"/"+info.Name)
+	}
+	return names
+}
+
+func TestManager_NeedLeaderElection(t *testing.T) {
+	var runnable manager.Runnable = NewManager(cache.NewIngressCache("test-cluster"), logr.Discard())
+	leaderOnly, ok := runnable.(manager.LeaderElectionRunnable)
+	if !ok || !leaderOnly.NeedLeaderElection() {
+		t.Error("Manager should only run on the leader")
+	}
+}
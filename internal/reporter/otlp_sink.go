@@ -0,0 +1,180 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/go-logr/logr"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	metricspb "go.opentelemetry.io/proto/otlp/metrics/v1"
+	resourcepb "go.opentelemetry.io/proto/otlp/resource/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// OTLP metric and attribute names
+const (
+	otlpScopeName        = "github.com/ugurcancaykara/cert-observer"
+	otlpDaysUntilExpiry  = "cert_observer.certificate.days_until_expiry"
+	otlpAttrService      = "service.name"
+	otlpAttrCluster      = "k8s.cluster.name"
+	otlpAttrNamespace    = "k8s.namespace.name"
+	otlpAttrIngress      = "k8s.ingress.name"
+	otlpAttrHost         = "server.address"
+	otlpAttrCertificate  = "tls.certificate.name"
+	otlpServiceName      = "cert-observer"
+	otlpProtobufMimeType = "application/x-protobuf"
+)
+
+// OTLPSink exports the days until expiry of each reported certificate as an
+// OTLP gauge over OTLP/HTTP. Each host is a resource identified by cluster,
+// namespace, ingress and host, so backends can group and alert on them like
+// on any other Kubernetes resource.
+type OTLPSink struct {
+	endpoint string
+	headers  map[string]string
+	client   *http.Client
+	log      logr.Logger
+	// retryBackoff is the base delay between delivery attempts
+	retryBackoff time.Duration
+	// now returns the time days until expiry are computed at
+	now func() time.Time
+}
+
+// NewOTLPSink creates a sink exporting to the OTLP endpoint of the
+// configuration
+func NewOTLPSink(cfg *config.Config, log logr.Logger) *OTLPSink {
+	return &OTLPSink{
+		endpoint:     cfg.ReportOTLPEndpoint,
+		headers:      cfg.ReportOTLPHeaders,
+		client:       newHTTPClient(cfg.ReportMinTLSVersion, cfg.ReportInsecureSkipVerify, cfg.ReportProxyURL),
+		log:          log,
+		retryBackoff: 2 * time.Second,
+		now:          time.Now,
+	}
+}
+
+// Send converts the report to an OTLP metrics export request and posts it,
+// retrying the statuses the OTLP specification marks as retryable
+func (s *OTLPSink) Send(ctx context.Context, report []byte) error {
+	var decoded Report
+	if err := json.Unmarshal(report, &decoded); err != nil {
+		return fmt.Errorf("failed to decode report: %w", err)
+	}
+	body, err := proto.Marshal(expiryMetrics(&decoded, s.now()))
+	if err != nil {
+		return fmt.Errorf("failed to marshal OTLP request: %w", err)
+	}
+
+	maxRetries := 3
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.endpoint, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", otlpProtobufMimeType)
+		for key, value := range s.headers {
+			req.Header.Set(key, value)
+		}
+
+		resp, err := s.client.Do(req)
+		if err == nil {
+			_ = resp.Body.Close()
+			if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+				s.log.V(1).Info("certificate expiry exported", "endpoint", s.endpoint, "status", resp.StatusCode)
+				return nil
+			}
+			err = fmt.Errorf("received non-success status code: %d", resp.StatusCode)
+			if !otlpRetryable(resp.StatusCode) {
+				return err
+			}
+		}
+		if attempt == maxRetries {
+			return err
+		}
+		s.log.V(1).Info("retrying OTLP export", "error", err.Error(), "attempt", attempt)
+		if err := sleep(ctx, time.Duration(attempt)*s.retryBackoff); err != nil {
+			return err
+		}
+	}
+
+	return fmt.Errorf("failed to export certificate expiry after %d attempts", maxRetries)
+}
+
+// otlpRetryable reports whether an OTLP/HTTP export that failed with the
+// status may succeed when retried
+func otlpRetryable(status int) bool {
+	switch status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// expiryMetrics maps a report to an OTLP export request with one resource
+// per host that has a certificate with a known expiry. Tombstones are left
+// out, so the gauges of deleted ingresses go stale. The report labels are
+// added to each resource as attributes.
+func expiryMetrics(report *Report, now time.Time) *colmetricspb.ExportMetricsServiceRequest {
+	request := &colmetricspb.ExportMetricsServiceRequest{}
+	certificateOf := report.certificateResolver()
+	labelKeys := slices.Sorted(maps.Keys(report.Labels))
+	for _, info := range report.Ingresses {
+		if info.Deleted {
+			continue
+		}
+		for _, host := range info.Hosts {
+			cert := certificateOf(host)
+			if cert == nil || cert.Expires == nil {
+				continue
+			}
+			days := cert.Expires.Sub(now).Hours() / 24
+			point := &metricspb.NumberDataPoint{
+				TimeUnixNano: uint64(now.UnixNano()),
+				Value:        &metricspb.NumberDataPoint_AsDouble{AsDouble: days},
+				Attributes:   []*commonpb.KeyValue{stringAttribute(otlpAttrCertificate, cert.Name)},
+			}
+			attributes := []*commonpb.KeyValue{
+				stringAttribute(otlpAttrService, otlpServiceName),
+				stringAttribute(otlpAttrCluster, report.Cluster),
+				stringAttribute(otlpAttrNamespace, info.Namespace),
+				stringAttribute(otlpAttrIngress, info.Name),
+				stringAttribute(otlpAttrHost, host.Host),
+			}
+			for _, key := range labelKeys {
+				attributes = append(attributes, stringAttribute(key, report.Labels[key]))
+			}
+			request.ResourceMetrics = append(request.ResourceMetrics, &metricspb.ResourceMetrics{
+				Resource: &resourcepb.Resource{Attributes: attributes},
+				ScopeMetrics: []*metricspb.ScopeMetrics{{
+					Scope: &commonpb.InstrumentationScope{Name: otlpScopeName},
+					Metrics: []*metricspb.Metric{{
+						Name:        otlpDaysUntilExpiry,
+						Description: "Days until the certificate expires, negative once expired",
+						Unit:        "d",
+						Data: &metricspb.Metric_Gauge{Gauge: &metricspb.Gauge{
+							DataPoints: []*metricspb.NumberDataPoint{point},
+						}},
+					}},
+				}},
+			})
+		}
+	}
+	return request
+}
+
+// stringAttribute returns an OTLP string attribute
+func stringAttribute(key, value string) *commonpb.KeyValue {
+	return &commonpb.KeyValue{
+		Key:   key,
+		Value: &commonpb.AnyValue{Value: &commonpb.AnyValue_StringValue{StringValue: value}},
+	}
+}
@@ -0,0 +1,170 @@
+package reporter
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	colmetricspb "go.opentelemetry.io/proto/otlp/collector/metrics/v1"
+	commonpb "go.opentelemetry.io/proto/otlp/common/v1"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/ugurcancaykara/cert-observer/internal/cache"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// attributes flattens OTLP string attributes into a map
+func attributes(kvs []*commonpb.KeyValue) map[string]string {
+	values := make(map[string]string, len(kvs))
+	for _, kv := range kvs {
+		values[kv.Key] = kv.Value.GetStringValue()
+	}
+	return values
+}
+
+func TestExpiryMetrics(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	soon := now.Add(36 * time.Hour)
+	expired := now.Add(-48 * time.Hour)
+	report := &Report{
+		Cluster: "prod",
+		Labels:  map[string]string{"region": "eu"},
+		Ingresses: []*cache.IngressInfo{{
+			Namespace: "shop",
+			Name:      "storefront",
+			Hosts: []cache.HostInfo{
+				{Host: "shop.example.com", Certificate: &cache.CertificateInfo{Name: "shop-tls", Expires: &soon}},
+				{Host: "old.example.com", Certificate: &cache.CertificateInfo{Name: "old-tls", Expires: &expired}},
+				{Host: "plain.example.com"},
+				{Host: "broken.example.com", Certificate: &cache.CertificateInfo{Name: "broken-tls"}},
+			},
+		}, {
+			Namespace: "shop",
+			Name:      "deleted",
+			Deleted:   true,
+			Hosts:     []cache.HostInfo{{Host: "gone.example.com", Certificate: &cache.CertificateInfo{Name: "gone-tls", Expires: &soon}}},
+		}},
+	}
+
+	request := expiryMetrics(report, now)
+	if len(request.ResourceMetrics) != 2 {
+		t.Fatalf("got %d resources, want one per live host with a known expiry", len(request.ResourceMetrics))
+	}
+
+	tests := []struct {
+		host        string
+		certificate string
+		days        float64
+	}{
+		{host: "shop.example.com", certificate: "shop-tls", days: 1.5},
+		{host: "old.example.com", certificate: "old-tls", days: -2},
+	}
+	for i, tt := range tests {
+		resource := request.ResourceMetrics[i]
+		wantResource := map[string]string{
+			"service.name":       "cert-observer",
+			"k8s.cluster.name":   "prod",
+			"k8s.namespace.name": "shop",
+			"k8s.ingress.name":   "storefront",
+			"server.address":     tt.host,
+			"region":             "eu",
+		}
+		got := attributes(resource.Resource.Attributes)
+		for key, want := range wantResource {
+			if got[key] != want {
+				t.Errorf("resource %d attribute %s = %q, want %q", i, key, got[key], want)
+			}
+		}
+
+		metric := resource.ScopeMetrics[0].Metrics[0]
+		if metric.Name != "cert_observer.certificate.days_until_expiry" {
+			t.Errorf("metric name = %q", metric.Name)
+		}
+		point := metric.GetGauge().DataPoints[0]
+		if point.GetAsDouble() != tt.days {
+			t.Errorf("%s: days until expiry = %v, want %v", tt.host, point.GetAsDouble(), tt.days)
+		}
+		if got := attributes(point.Attributes)["tls.certificate.name"]; got != tt.certificate {
+			t.Errorf("%s: certificate = %q, want %q", tt.host, got, tt.certificate)
+		}
+		if point.TimeUnixNano != uint64(now.UnixNano()) {
+			t.Errorf("%s: timestamp = %d, want %d", tt.host, point.TimeUnixNano, now.UnixNano())
+		}
+	}
+}
+
+func TestOTLPSink_Send(t *testing.T) {
+	var received colmetricspb.ExportMetricsServiceRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.Header.Get("Content-Type"); got != "application/x-protobuf" {
+			t.Errorf("Content-Type = %q", got)
+		}
+		if got := r.Header.Get("Authorization"); got != "Bearer token" {
+			t.Errorf("Authorization = %q, want the configured header", got)
+		}
+		body, _ := io.ReadAll(r.Body)
+		if err := proto.Unmarshal(body, &received); err != nil {
+			t.Errorf("failed to decode OTLP request: %v", err)
+		}
+	}))
+	defer server.Close()
+
+	sink := NewOTLPSink(&config.Config{
+		ReportOTLPEndpoint: server.URL + "/v1/metrics",
+		ReportOTLPHeaders:  map[string]string{"Authorization": "Bearer token"},
+	}, logr.Discard())
+
+	expires := time.Now().Add(10 * 24 * time.Hour)
+	payload, _ := json.Marshal(Report{
+		Cluster: "prod",
+		Ingresses: []*cache.IngressInfo{{
+			Namespace: "shop",
+			Name:      "storefront",
+			Hosts:     []cache.HostInfo{{Host: "shop.example.com", Certificate: &cache.CertificateInfo{Name: "shop-tls", Expires: &expires}}},
+		}},
+	})
+	if err := sink.Send(context.Background(), payload); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if len(received.ResourceMetrics) != 1 {
+		t.Fatalf("collector received %d resources, want 1", len(received.ResourceMetrics))
+	}
+}
+
+func TestOTLPSink_Retries(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		wantAttempts int32
+	}{
+		{name: "retryable", status: http.StatusServiceUnavailable, wantAttempts: 3},
+		{name: "throttled", status: http.StatusTooManyRequests, wantAttempts: 3},
+		{name: "permanent", status: http.StatusBadRequest, wantAttempts: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var attempts atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			sink := NewOTLPSink(&config.Config{ReportOTLPEndpoint: server.URL}, logr.Discard())
+			sink.retryBackoff = time.Millisecond
+			if err := sink.Send(context.Background(), []byte(`{"cluster":"prod","ingresses":[]}`)); err == nil {
+				t.Fatal("Send() succeeded, want an error")
+			}
+			if got := attempts.Load(); got != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", got, tt.wantAttempts)
+			}
+		})
+	}
+}
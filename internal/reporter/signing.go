@@ -0,0 +1,36 @@
+package reporter
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Headers of signed reports
+const (
+	// SignatureHeader carries "sha256=" followed by the hex-encoded
+	// HMAC-SHA256 of the timestamp header value followed by the body
+	SignatureHeader = "X-Cert-Observer-Signature"
+	// TimestampHeader carries the Unix time in seconds the request was
+	// signed at, so collectors can reject replayed reports
+	TimestampHeader = "X-Cert-Observer-Timestamp"
+)
+
+// Signature returns the signature header value for a report body signed at
+// timestamp with the shared secret
+func Signature(secret []byte, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// signRequest sets the timestamp and signature headers of a report request
+func signRequest(req *http.Request, secret []byte, body []byte, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	req.Header.Set(TimestampHeader, timestamp)
+	req.Header.Set(SignatureHeader, Signature(secret, timestamp, body))
+}
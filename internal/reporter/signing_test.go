@@ -0,0 +1,77 @@
+package reporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+func TestSignature(t *testing.T) {
+	// Computed with: printf '1748779200{"cluster":"test-cluster"}' | openssl dgst -sha256 -hmac s3cret
+	want := "sha256=90f6fccc70ffd6e774845cb655386c2e4c6f3ffce2d84c98b96b468b52967ce2"
+	if got := Signature([]byte("s3cret"), "1748779200", []byte(`{"cluster":"test-cluster"}`)); got != want {
+		t.Errorf("Signature() = %v, want %v", got, want)
+	}
+}
+
+func TestHTTPSink_Signing(t *testing.T) {
+	tests := []struct {
+		name   string
+		secret string
+	}{
+		{name: "unsigned by default", secret: ""},
+		{name: "signed", secret: "s3cret"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var header http.Header
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				header = r.Header.Clone()
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			sink := NewHTTPSink(&config.Config{
+				ReportEndpoint:      server.URL,
+				ReportSigningSecret: tt.secret,
+			}, logr.Discard())
+			report := []byte(`{"cluster":"test-cluster"}`)
+			if err := sink.Send(context.Background(), report); err != nil {
+				t.Fatalf("Send() error = %v", err)
+			}
+
+			if tt.secret == "" {
+				if header.Get(SignatureHeader) != "" || header.Get(TimestampHeader) != "" {
+					t.Errorf("unsigned report has signature headers: %v", header)
+				}
+				return
+			}
+			timestamp := header.Get(TimestampHeader)
+			signedAt, err := strconv.ParseInt(timestamp, 10, 64)
+			if err != nil {
+				t.Fatalf("invalid %s %q: %v", TimestampHeader, timestamp, err)
+			}
+			if age := time.Since(time.Unix(signedAt, 0)); age < 0 || age > time.Minute {
+				t.Errorf("timestamp is %v old, want the time of sending", age)
+			}
+			if got, want := header.Get(SignatureHeader), Signature([]byte(tt.secret), timestamp, body); got != want {
+				t.Errorf("%s = %v, want %v", SignatureHeader, got, want)
+			}
+			// A different timestamp doesn't verify, so replays can be detected
+			if Signature([]byte(tt.secret), strconv.FormatInt(signedAt-600, 10), body) == header.Get(SignatureHeader) {
+				t.Error("signature doesn't cover the timestamp")
+			}
+		})
+	}
+}
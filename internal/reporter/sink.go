@@ -0,0 +1,259 @@
+package reporter
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	"golang.org/x/net/http/httpproxy"
+
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// Sink delivers reports built by the reporter
+type Sink interface {
+	// Send delivers a JSON-encoded Report. Reports that fail are queued in
+	// the dead-letter queue, if any, and sent again later.
+	Send(ctx context.Context, report []byte) error
+}
+
+// reconfigurableSink is a Sink that depends on the reporter configuration
+// and is updated along with it
+type reconfigurableSink interface {
+	Sink
+	Update(cfg *config.Config)
+}
+
+// HTTPSink posts reports to the configured endpoint, retrying failed
+// requests with exponential backoff
+type HTTPSink struct {
+	// mu guards config and client, which Update replaces at runtime
+	mu     sync.RWMutex
+	config *config.Config
+	client *http.Client
+	log    logr.Logger
+	// retryBackoff is the base delay between delivery attempts
+	retryBackoff time.Duration
+	// lastReceiptID is the receipt id of the last accepted report, guarded by mu
+	lastReceiptID string
+}
+
+// receiptSink is a Sink that keeps the receipt id the collector returned
+// for the last delivered report
+type receiptSink interface {
+	Sink
+	LastReceiptID() string
+}
+
+// NewHTTPSink creates a sink posting to the endpoint of the configuration
+func NewHTTPSink(cfg *config.Config, log logr.Logger) *HTTPSink {
+	return &HTTPSink{
+		config:       cfg,
+		client:       newHTTPClient(cfg.ReportMinTLSVersion, cfg.ReportInsecureSkipVerify, cfg.ReportProxyURL),
+		log:          log,
+		retryBackoff: 2 * time.Second,
+	}
+}
+
+// settings returns the current configuration and client
+func (s *HTTPSink) settings() (*config.Config, *http.Client) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.config, s.client
+}
+
+// Update switches to the endpoint, TLS and proxy settings of a new
+// configuration. A report in flight completes against the old endpoint.
+func (s *HTTPSink) Update(cfg *config.Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if cfg.ReportMinTLSVersion != s.config.ReportMinTLSVersion ||
+		cfg.ReportInsecureSkipVerify != s.config.ReportInsecureSkipVerify ||
+		!sameURL(cfg.ReportProxyURL, s.config.ReportProxyURL) {
+		s.client = newHTTPClient(cfg.ReportMinTLSVersion, cfg.ReportInsecureSkipVerify, cfg.ReportProxyURL)
+	}
+	s.config = cfg
+}
+
+// newHTTPClient creates the report client, refusing TLS versions below
+// minVersion. A zero minVersion defaults to TLS 1.2. The collector's
+// certificate isn't verified when insecureSkipVerify is set. Requests go
+// through proxyURL if set, otherwise through the proxy from the environment.
+func newHTTPClient(minVersion uint16, insecureSkipVerify bool, proxyURL *url.URL) *http.Client {
+	if minVersion == 0 {
+		minVersion = tls.VersionTLS12
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:         minVersion,
+		InsecureSkipVerify: insecureSkipVerify,
+	}
+	transport.Proxy = proxyFunc(proxyURL)
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: transport,
+	}
+}
+
+// proxyFunc returns the proxy selection for the report client. Without an
+// explicit proxyURL, HTTP_PROXY, HTTPS_PROXY and NO_PROXY are read when the
+// client is created, unlike http.ProxyFromEnvironment, which reads them once
+// per process.
+func proxyFunc(proxyURL *url.URL) func(*http.Request) (*url.URL, error) {
+	if proxyURL != nil {
+		return http.ProxyURL(proxyURL)
+	}
+	fromEnvironment := httpproxy.FromEnvironment().ProxyFunc()
+	return func(req *http.Request) (*url.URL, error) {
+		return fromEnvironment(req.URL)
+	}
+}
+
+// sameURL reports whether two optional URLs are equal
+func sameURL(a, b *url.URL) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.String() == b.String()
+}
+
+// CheckEndpoint sends a HEAD request to the report endpoint to verify that it
+// is reachable. Any HTTP response counts as reachable, since collectors
+// usually only accept POST.
+func (s *HTTPSink) CheckEndpoint(ctx context.Context) error {
+	cfg, client := s.settings()
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, cfg.ReportEndpoint, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", userAgent(cfg))
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Send posts a report to the configured endpoint, retrying with exponential
+// backoff until it gets one of the configured success statuses. All attempts
+// carry the same request id.
+func (s *HTTPSink) Send(ctx context.Context, report []byte) error {
+	cfg, client := s.settings()
+	requestID := newRequestID()
+
+	// Retry logic with exponential backoff
+	maxRetries := 3
+	for attempt := 1; attempt <= maxRetries; attempt++ {
+		// Check if context was cancelled
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "POST", cfg.ReportEndpoint, bytes.NewBuffer(report))
+		if err != nil {
+			return fmt.Errorf("failed to create request: %w", err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", userAgent(cfg))
+		req.Header.Set(RequestIDHeader, requestID)
+		if cfg.ReportSigningSecret != "" {
+			// Signed per attempt, so retries and replays carry a fresh timestamp
+			signRequest(req, []byte(cfg.ReportSigningSecret), report, time.Now())
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			// Only log detailed errors on last attempt or non-connection errors
+			if attempt == maxRetries && !isServerUnavailable(err) {
+				s.log.Error(err, "failed to send report after retries", "endpoint", cfg.ReportEndpoint,
+					"request_id", requestID, "attempts", maxRetries)
+			}
+			if attempt < maxRetries {
+				// Exponential backoff: 2s, 4s
+				if err := sleep(ctx, time.Duration(attempt)*s.retryBackoff); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+		defer func() {
+			if err := resp.Body.Close(); err != nil {
+				s.log.V(1).Info("failed to close response body", "error", err.Error())
+			}
+		}()
+
+		if cfg.ReportSuccessStatusCodes.Contains(resp.StatusCode) {
+			if cfg.ReportAcceptance == nil {
+				s.log.V(1).Info("report delivered", "endpoint", cfg.ReportEndpoint, "request_id", requestID,
+					"status", resp.StatusCode)
+				return nil
+			}
+			receiptID, err := checkAcceptance(resp.Body, cfg.ReportAcceptance)
+			if err == nil {
+				s.setLastReceiptID(receiptID)
+				s.log.V(1).Info("report delivered", "endpoint", cfg.ReportEndpoint, "request_id", requestID,
+					"status", resp.StatusCode, "receipt_id", receiptID)
+				return nil
+			}
+			// Rejected despite the status, retried like a failed status
+			if attempt < maxRetries {
+				s.log.V(1).Info("retrying after rejected report", "error", err.Error(), "attempt", attempt)
+				if err := sleep(ctx, time.Duration(attempt)*s.retryBackoff); err != nil {
+					return err
+				}
+				continue
+			}
+			return err
+		}
+
+		// Status not configured as success
+		if attempt < maxRetries {
+			s.log.V(1).Info("retrying after non-success status", "status", resp.StatusCode, "attempt", attempt)
+			if err := sleep(ctx, time.Duration(attempt)*s.retryBackoff); err != nil {
+				return err
+			}
+			continue
+		}
+
+		return fmt.Errorf("received non-success status code: %d", resp.StatusCode)
+	}
+
+	return fmt.Errorf("failed to send report after %d attempts", maxRetries)
+}
+
+// LastReceiptID returns the receipt id of the last report the collector
+// accepted, empty if REPORT_RECEIPT_ID_FIELD isn't set
+func (s *HTTPSink) LastReceiptID() string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.lastReceiptID
+}
+
+// setLastReceiptID records the receipt id of an accepted report
+func (s *HTTPSink) setLastReceiptID(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.lastReceiptID = id
+}
+
+// sleep waits for the given duration, returning early when ctx is cancelled
+// so that a stopped reporter doesn't linger in a retry backoff
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,96 @@
+package stats
+
+import (
+	"sync"
+	"time"
+)
+
+// Reconcile kinds tracked by the Recorder
+const (
+	KindIngress  = "ingress"
+	KindSecret   = "secret"
+	KindGateway  = "gateway"
+	KindWorkload = "workload"
+	KindRoute    = "route"
+)
+
+// Recorder tracks process uptime, reconcile counts and certificate parse
+// errors for self-health reporting. A nil Recorder is valid and records nothing.
+type Recorder struct {
+	started    time.Time
+	now        func() time.Time
+	mu         sync.Mutex
+	reconciles map[string]int
+	parseErrs  int
+}
+
+// NewRecorder creates a Recorder whose uptime starts now
+func NewRecorder() *Recorder {
+	return &Recorder{
+		started:    time.Now(),
+		now:        time.Now,
+		reconciles: make(map[string]int),
+	}
+}
+
+// Uptime returns the time elapsed since the Recorder was created
+func (r *Recorder) Uptime() time.Duration {
+	if r == nil {
+		return 0
+	}
+	return r.now().Sub(r.started)
+}
+
+// Started returns when the Recorder was created, the zero time for a nil
+// Recorder
+func (r *Recorder) Started() time.Time {
+	if r == nil {
+		return time.Time{}
+	}
+	return r.started
+}
+
+// RecordReconcile increments the reconcile count for the given kind
+func (r *Recorder) RecordReconcile(kind string) {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.reconciles[kind]++
+}
+
+// ReconcileCounts returns a copy of the reconcile counts keyed by kind
+func (r *Recorder) ReconcileCounts() map[string]int {
+	counts := make(map[string]int)
+	if r == nil {
+		return counts
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for kind, n := range r.reconciles {
+		counts[kind] = n
+	}
+	return counts
+}
+
+// RecordParseError increments the count of certificates that couldn't be
+// parsed from their secret
+func (r *Recorder) RecordParseError() {
+	if r == nil {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.parseErrs++
+}
+
+// ParseErrors returns the number of certificate parse errors recorded
+func (r *Recorder) ParseErrors() int {
+	if r == nil {
+		return 0
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.parseErrs
+}
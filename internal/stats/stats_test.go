@@ -0,0 +1,77 @@
+package stats
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRecorder_Uptime(t *testing.T) {
+	r := NewRecorder()
+	now := r.started
+	r.now = func() time.Time { return now }
+
+	first := r.Uptime()
+	now = now.Add(5 * time.Second)
+	second := r.Uptime()
+
+	if second <= first {
+		t.Errorf("Uptime() did not increase: first %v, second %v", first, second)
+	}
+	if second != 5*time.Second {
+		t.Errorf("Uptime() = %v, want 5s", second)
+	}
+}
+
+func TestRecorder_ReconcileCounts(t *testing.T) {
+	r := NewRecorder()
+
+	r.RecordReconcile(KindIngress)
+	r.RecordReconcile(KindIngress)
+	r.RecordReconcile(KindSecret)
+
+	counts := r.ReconcileCounts()
+	if counts[KindIngress] != 2 {
+		t.Errorf("ingress count = %d, want 2", counts[KindIngress])
+	}
+	if counts[KindSecret] != 1 {
+		t.Errorf("secret count = %d, want 1", counts[KindSecret])
+	}
+
+	// Returned map is a copy
+	counts[KindIngress] = 100
+	if got := r.ReconcileCounts()[KindIngress]; got != 2 {
+		t.Errorf("ingress count after mutating copy = %d, want 2", got)
+	}
+}
+
+func TestRecorder_ParseErrors(t *testing.T) {
+	r := NewRecorder()
+	if got := r.ParseErrors(); got != 0 {
+		t.Errorf("ParseErrors() = %d, want 0", got)
+	}
+
+	r.RecordParseError()
+	r.RecordParseError()
+	if got := r.ParseErrors(); got != 2 {
+		t.Errorf("ParseErrors() = %d, want 2", got)
+	}
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var r *Recorder
+
+	r.RecordReconcile(KindIngress)
+	r.RecordParseError()
+	if r.Uptime() != 0 {
+		t.Error("nil Recorder reported uptime")
+	}
+	if !r.Started().IsZero() {
+		t.Error("nil Recorder reported a start time")
+	}
+	if len(r.ReconcileCounts()) != 0 {
+		t.Error("nil Recorder reported reconcile counts")
+	}
+	if r.ParseErrors() != 0 {
+		t.Error("nil Recorder reported parse errors")
+	}
+}
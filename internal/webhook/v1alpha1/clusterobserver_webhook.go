@@ -0,0 +1,116 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	observerv1alpha1 "github.com/ugurcancaykara/cert-observer/api/v1alpha1"
+	"github.com/ugurcancaykara/cert-observer/internal/config"
+)
+
+// log is for logging in this package.
+var clusterobserverlog = logf.Log.WithName("clusterobserver-resource")
+
+// SetupClusterObserverWebhookWithManager registers the webhook for ClusterObserver in the manager.
+func SetupClusterObserverWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&observerv1alpha1.ClusterObserver{}).
+		WithValidator(&ClusterObserverCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-observer-cert-observer-io-v1alpha1-clusterobserver,mutating=false,failurePolicy=fail,sideEffects=None,groups=observer.cert-observer.io,resources=clusterobservers,verbs=create;update,versions=v1alpha1,name=vclusterobserver-v1alpha1.kb.io,admissionReviewVersions=v1
+
+// ClusterObserverCustomValidator rejects ClusterObservers whose spec the
+// controller couldn't use, so that a bad value is never persisted
+type ClusterObserverCustomValidator struct{}
+
+var _ webhook.CustomValidator = &ClusterObserverCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type ClusterObserver.
+func (v *ClusterObserverCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	observer, ok := obj.(*observerv1alpha1.ClusterObserver)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterObserver object but got %T", obj)
+	}
+	clusterobserverlog.V(1).Info("validation for ClusterObserver upon creation", "name", observer.GetName())
+
+	return nil, validateClusterObserver(observer)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type ClusterObserver.
+func (v *ClusterObserverCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	observer, ok := newObj.(*observerv1alpha1.ClusterObserver)
+	if !ok {
+		return nil, fmt.Errorf("expected a ClusterObserver object for the newObj but got %T", newObj)
+	}
+	clusterobserverlog.V(1).Info("validation for ClusterObserver upon update", "name", observer.GetName())
+
+	return nil, validateClusterObserver(observer)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type ClusterObserver.
+func (v *ClusterObserverCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateClusterObserver returns an Invalid error listing every problem
+// with the spec, or nil if there is none
+func validateClusterObserver(observer *observerv1alpha1.ClusterObserver) error {
+	allErrs := validateSpec(&observer.Spec, field.NewPath("spec"))
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(
+		observerv1alpha1.GroupVersion.WithKind("ClusterObserver").GroupKind(),
+		observer.Name, allErrs)
+}
+
+// validateSpec checks the fields the CRD schema can't fully validate
+func validateSpec(spec *observerv1alpha1.ClusterObserverSpec, specPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	if err := config.ValidateEndpoint(spec.ReportEndpoint); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("reportEndpoint"), spec.ReportEndpoint, err.Error()))
+	}
+
+	intervalPath := specPath.Child("reportInterval")
+	interval, err := time.ParseDuration(spec.ReportInterval)
+	switch {
+	case err != nil:
+		allErrs = append(allErrs, field.Invalid(intervalPath, spec.ReportInterval, "must be a duration such as 30s"))
+	case interval < config.MinReportInterval:
+		allErrs = append(allErrs, field.Invalid(intervalPath, spec.ReportInterval,
+			fmt.Sprintf("must be at least %s", config.MinReportInterval)))
+	}
+
+	if err := config.ValidateReportLabels(spec.ReportLabels); err != nil {
+		allErrs = append(allErrs, field.Invalid(specPath.Child("reportLabels"), spec.ReportLabels, err.Error()))
+	}
+
+	return allErrs
+}
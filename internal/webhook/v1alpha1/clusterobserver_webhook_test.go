@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	observerv1alpha1 "github.com/ugurcancaykara/cert-observer/api/v1alpha1"
+)
+
+var _ = Describe("ClusterObserver Webhook", func() {
+	var (
+		validator *ClusterObserverCustomValidator
+		observer  *observerv1alpha1.ClusterObserver
+	)
+
+	BeforeEach(func() {
+		validator = &ClusterObserverCustomValidator{}
+		observer = &observerv1alpha1.ClusterObserver{
+			ObjectMeta: metav1.ObjectMeta{Name: "observer", Namespace: "default"},
+			Spec: observerv1alpha1.ClusterObserverSpec{
+				ClusterName:    "test-cluster",
+				ReportEndpoint: "http://collector.example.com/report",
+				ReportInterval: "30s",
+			},
+		}
+	})
+
+	Context("When creating or updating a ClusterObserver", func() {
+		It("Should admit a valid spec", func() {
+			_, err := validator.ValidateCreate(context.Background(), observer)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = validator.ValidateUpdate(context.Background(), observer.DeepCopy(), observer)
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		DescribeTable("Should deny an invalid spec",
+			func(mutate func(*observerv1alpha1.ClusterObserverSpec), field string) {
+				mutate(&observer.Spec)
+
+				_, err := validator.ValidateCreate(context.Background(), observer)
+				Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an Invalid error, got %v", err)
+				Expect(err.Error()).To(ContainSubstring(field))
+
+				_, err = validator.ValidateUpdate(context.Background(), observer.DeepCopy(), observer)
+				Expect(apierrors.IsInvalid(err)).To(BeTrue(), "expected an Invalid error, got %v", err)
+			},
+			Entry("unparsable reportInterval", func(spec *observerv1alpha1.ClusterObserverSpec) {
+				spec.ReportInterval = "often"
+			}, "spec.reportInterval"),
+			Entry("reportInterval below the minimum", func(spec *observerv1alpha1.ClusterObserverSpec) {
+				spec.ReportInterval = "1s"
+			}, "spec.reportInterval"),
+			Entry("reportEndpoint without a host", func(spec *observerv1alpha1.ClusterObserverSpec) {
+				spec.ReportEndpoint = "http:///report"
+			}, "spec.reportEndpoint"),
+			Entry("reportLabels with an empty key", func(spec *observerv1alpha1.ClusterObserverSpec) {
+				spec.ReportLabels = map[string]string{"": "eu"}
+			}, "spec.reportLabels"),
+		)
+
+		It("Should report every invalid field at once", func() {
+			observer.Spec.ReportInterval = "1s"
+			observer.Spec.ReportEndpoint = "http://"
+
+			_, err := validator.ValidateCreate(context.Background(), observer)
+			Expect(err).To(MatchError(And(
+				ContainSubstring("spec.reportInterval"),
+				ContainSubstring("spec.reportEndpoint"),
+			)))
+		})
+	})
+
+	Context("When deleting a ClusterObserver", func() {
+		It("Should always admit the deletion", func() {
+			observer.Spec.ReportInterval = "often"
+
+			_, err := validator.ValidateDelete(context.Background(), observer)
+			Expect(err).NotTo(HaveOccurred())
+		})
+	})
+})